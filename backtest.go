@@ -0,0 +1,620 @@
+package indodax
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+type BacktestStrategy interface {
+	OnStart(bt *Backtest) error
+	OnBar(bt *Backtest, pair string, bar OHLC) error
+	OnTrade(bt *Backtest, pair string, trade Trade) error
+	OnFill(bt *Backtest, fill OwnTrade) error
+	OnStop(bt *Backtest) error
+}
+
+type BacktestStrategyBase struct{}
+
+func (BacktestStrategyBase) OnStart(bt *Backtest) error                      { return nil }
+func (BacktestStrategyBase) OnBar(bt *Backtest, pair string, bar OHLC) error { return nil }
+func (BacktestStrategyBase) OnTrade(bt *Backtest, pair string, trade Trade) error {
+	return nil
+}
+func (BacktestStrategyBase) OnFill(bt *Backtest, fill OwnTrade) error { return nil }
+func (BacktestStrategyBase) OnStop(bt *Backtest) error                { return nil }
+
+type Backtest struct {
+	*simulatedExchange
+	config       BacktestConfig
+	bars         map[string][]OHLC
+	marketTrades map[string][]Trade
+	current      time.Time
+	last         map[string]float64
+	liquidity    map[string]float64
+	equity       []EquityPoint
+	fillsSeen    int
+}
+
+type backtestEvent struct {
+	time  time.Time
+	pair  string
+	bar   *OHLC
+	trade *Trade
+}
+
+var (
+	_ TradingAPI = (*Backtest)(nil)
+	_ WalletAPI  = (*Backtest)(nil)
+)
+
+func NewBacktest(config BacktestConfig) (*Backtest, error) {
+	if len(config.InitialBalances) == 0 {
+		return nil, errors.New("initial balances are required")
+	}
+
+	if len(config.QuoteCurrency) == 0 {
+		config.QuoteCurrency = CurrencyIdr
+	}
+
+	b := &Backtest{
+		simulatedExchange: newSimulatedExchange(config.InitialBalances),
+		config:            config,
+		bars:              map[string][]OHLC{},
+		marketTrades:      map[string][]Trade{},
+		last:              map[string]float64{},
+		liquidity:         map[string]float64{},
+	}
+
+	b.now = b.Now
+
+	for _, pair := range config.PairMetadata {
+		key := strings.ToLower(pair.TickerId)
+		if len(key) == 0 {
+			key = strings.ToLower(pair.Id)
+		}
+
+		b.fees[key] = pairFee(pair)
+	}
+
+	return b, nil
+}
+
+func (b *Backtest) AddBars(pair string, bars ...OHLC) *Backtest {
+	pair = strings.ToLower(pair)
+	b.bars[pair] = append(b.bars[pair], bars...)
+
+	return b
+}
+
+func (b *Backtest) AddTrades(pair string, trades ...Trade) *Backtest {
+	pair = strings.ToLower(pair)
+	b.marketTrades[pair] = append(b.marketTrades[pair], trades...)
+
+	return b
+}
+
+func (b *Backtest) LoadOHLC(api PublicAPI, pair, timeFrame string, from, to time.Time) error {
+	bars, err := api.GetOHLCHistory(strings.ToUpper(publicPairId(pair)), timeFrame, from.Unix(), to.Unix())
+	if err != nil {
+		return err
+	}
+
+	b.AddBars(pair, *bars...)
+
+	return nil
+}
+
+func (b *Backtest) Now() time.Time {
+	return b.current
+}
+
+func (b *Backtest) LastPrice(pair string) float64 {
+	return b.last[strings.ToLower(pair)]
+}
+
+/*
+ * Place an order at the current simulated price, market and crossing limit
+ * orders are filled as taker with slippage, the rest is matched on later events
+ *
+ * @return *ResponseBody
+ * @return error
+ */
+func (b *Backtest) Trade(tradeType, pair, orderType string, price, amount float64, timeInForce, clientOrderId *string, forceCoinAmount bool) (*ResponseBody, error) {
+	pair = strings.ToLower(pair)
+
+	last := b.last[pair]
+	if last <= 0 {
+		return nil, fmt.Errorf("no price available for %s", pair)
+	}
+
+	slippage := b.config.SlippageBps / 10000
+
+	levelPrice := last * (1 + slippage)
+	if tradeType == TradeTypeSell {
+		levelPrice = last * (1 - slippage)
+	}
+
+	available, exist := b.liquidity[pair]
+	if !exist {
+		available = math.MaxFloat64
+	}
+
+	levels := [][2]float64{{levelPrice, available}}
+
+	var spend float64
+
+	if orderType == OrderTypeMarket && tradeType == TradeTypeBuy && !forceCoinAmount {
+		spend, amount = amount, 0
+	}
+
+	var tif, clientId string
+
+	if timeInForce != nil {
+		tif = *timeInForce
+	}
+
+	if clientOrderId != nil {
+		clientId = *clientOrderId
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	filledBefore := len(b.simulatedExchange.trades)
+
+	resp, err := b.place(tradeType, pair, orderType, price, amount, spend, tif, clientId, levels)
+
+	if exist {
+		for _, t := range b.simulatedExchange.trades[filledBefore:] {
+			b.liquidity[pair] -= t.Amount
+		}
+	}
+
+	return resp, err
+}
+
+/*
+ * Replay the market data through the strategy, OnStop is called on every exit once
+ * OnStart succeeded and the result is computed after it
+ *
+ * @param BacktestStrategy strategy
+ *
+ * @return *BacktestResult
+ * @return error
+ */
+func (b *Backtest) Run(strategy BacktestStrategy) (result *BacktestResult, err error) {
+	events := b.events()
+	if len(events) == 0 {
+		return nil, errors.New("no market data to backtest")
+	}
+
+	b.current = events[0].time
+
+	if err := strategy.OnStart(b); err != nil {
+		return nil, err
+	}
+
+	initialEquity := b.initialEquity(events)
+
+	defer func() {
+		if stopErr := strategy.OnStop(b); stopErr != nil {
+			err = errors.Join(err, stopErr)
+		}
+
+		if err != nil {
+			result = nil
+			return
+		}
+
+		result = b.result(initialEquity)
+	}()
+
+	for i := range events {
+		event := events[i]
+		b.current = event.time
+
+		if event.bar != nil {
+			b.matchBar(event.pair, *event.bar)
+
+			b.last[event.pair] = toFloat(event.bar.Close)
+
+			if b.config.MaxVolumeParticipation > 0 {
+				b.liquidity[event.pair] = toFloat(event.bar.Volume) * b.config.MaxVolumeParticipation
+			}
+
+			if err := b.dispatchFills(strategy); err != nil {
+				return nil, err
+			}
+
+			if err := strategy.OnBar(b, event.pair, *event.bar); err != nil {
+				return nil, err
+			}
+		} else {
+			b.matchTrade(event.pair, *event.trade)
+
+			b.last[event.pair] = toFloat(event.trade.Price)
+
+			if err := b.dispatchFills(strategy); err != nil {
+				return nil, err
+			}
+
+			if err := strategy.OnTrade(b, event.pair, *event.trade); err != nil {
+				return nil, err
+			}
+		}
+
+		if err := b.dispatchFills(strategy); err != nil {
+			return nil, err
+		}
+
+		b.recordEquity()
+	}
+
+	return nil, nil
+}
+
+/*
+ * Mark all balances to the quote currency using the last simulated prices
+ *
+ * @return float64
+ */
+func (b *Backtest) Equity() float64 {
+	var equity float64
+
+	for currency, amount := range b.Balances() {
+		if currency == b.config.QuoteCurrency {
+			equity += amount
+			continue
+		}
+
+		equity += amount * b.last[fmt.Sprintf("%s_%s", currency, b.config.QuoteCurrency)]
+	}
+
+	return equity
+}
+
+/*
+ * Mark the initial balances to the quote currency using the first simulated price of
+ * every held asset, so the strategy is measured against holding its starting balances
+ *
+ * @param []backtestEvent events
+ *
+ * @return float64
+ */
+func (b *Backtest) initialEquity(events []backtestEvent) float64 {
+	first := map[string]float64{}
+
+	for _, event := range events {
+		if _, exist := first[event.pair]; exist {
+			continue
+		}
+
+		if event.bar != nil {
+			first[event.pair] = toFloat(event.bar.Close)
+		} else {
+			first[event.pair] = toFloat(event.trade.Price)
+		}
+	}
+
+	var equity float64
+
+	for currency, amount := range b.config.InitialBalances {
+		currency = strings.ToLower(currency)
+
+		if currency == b.config.QuoteCurrency {
+			equity += amount
+			continue
+		}
+
+		equity += amount * first[fmt.Sprintf("%s_%s", currency, b.config.QuoteCurrency)]
+	}
+
+	return equity
+}
+
+func (b *Backtest) events() []backtestEvent {
+	var events []backtestEvent
+
+	for pair, bars := range b.bars {
+		for i := range bars {
+			events = append(events, backtestEvent{time: time.Unix(bars[i].Time, 0), pair: pair, bar: &bars[i]})
+		}
+	}
+
+	for pair, trades := range b.marketTrades {
+		for i := range trades {
+			events = append(events, backtestEvent{time: toTime(trades[i].Date), pair: pair, trade: &trades[i]})
+		}
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		if !events[i].time.Equal(events[j].time) {
+			return events[i].time.Before(events[j].time)
+		}
+
+		return events[i].pair < events[j].pair
+	})
+
+	return events
+}
+
+func (b *Backtest) matchBar(pair string, bar OHLC) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	low, high := toFloat(bar.Low), toFloat(bar.High)
+
+	available := math.MaxFloat64
+	if b.config.MaxVolumeParticipation > 0 {
+		available = toFloat(bar.Volume) * b.config.MaxVolumeParticipation
+	}
+
+	for _, o := range b.openOrders() {
+		if o.pair != pair {
+			continue
+		}
+
+		if (o.tradeType == TradeTypeBuy && low > o.price) || (o.tradeType == TradeTypeSell && high < o.price) {
+			continue
+		}
+
+		amount := math.Min(o.remain, available)
+		if amount <= simulatedEpsilon {
+			continue
+		}
+
+		available -= amount
+
+		b.fill(o, o.price, amount, true)
+	}
+}
+
+func (b *Backtest) matchTrade(pair string, trade Trade) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	price := toFloat(trade.Price)
+	available := toFloat(trade.Amount)
+
+	for _, o := range b.openOrders() {
+		if o.pair != pair || !levelCrosses(o.tradeType, o.price, price) {
+			continue
+		}
+
+		amount := math.Min(o.remain, available)
+		if amount <= simulatedEpsilon {
+			continue
+		}
+
+		available -= amount
+
+		b.fill(o, o.price, amount, true)
+	}
+}
+
+func (b *Backtest) dispatchFills(strategy BacktestStrategy) error {
+	for {
+		fills := b.OwnTrades()
+
+		if b.fillsSeen >= len(fills) {
+			return nil
+		}
+
+		fill := fills[b.fillsSeen]
+		b.fillsSeen++
+
+		if err := strategy.OnFill(b, fill); err != nil {
+			return err
+		}
+	}
+}
+
+func (b *Backtest) recordEquity() {
+	point := EquityPoint{Time: b.current, Equity: b.Equity()}
+
+	if n := len(b.equity); n > 0 && b.equity[n-1].Time.Equal(point.Time) {
+		b.equity[n-1] = point
+		return
+	}
+
+	b.equity = append(b.equity, point)
+}
+
+/*
+ * Compute backtest metrics from the equity curve and simulated fills
+ *
+ * @param float64 initialEquity
+ *
+ * @return *BacktestResult
+ */
+func (b *Backtest) result(initialEquity float64) *BacktestResult {
+	fills := b.OwnTrades()
+
+	r := BacktestResult{
+		InitialEquity: initialEquity,
+		FinalEquity:   initialEquity,
+		EquityCurve:   b.equity,
+		Fills:         fills,
+		Trades:        len(fills),
+	}
+
+	if len(b.equity) > 0 {
+		r.Start = b.equity[0].Time
+		r.End = b.equity[len(b.equity)-1].Time
+		r.FinalEquity = b.equity[len(b.equity)-1].Equity
+	}
+
+	if initialEquity > 0 {
+		r.TotalReturn = r.FinalEquity/initialEquity - 1
+	}
+
+	peak := initialEquity
+
+	var returns []float64
+
+	var equitySum float64
+
+	previous := initialEquity
+
+	for _, point := range b.equity {
+		if point.Equity > peak {
+			peak = point.Equity
+		}
+
+		if peak > 0 {
+			if drawdown := (peak - point.Equity) / peak; drawdown > r.MaxDrawdown {
+				r.MaxDrawdown = drawdown
+			}
+		}
+
+		if previous > 0 {
+			returns = append(returns, point.Equity/previous-1)
+		}
+
+		previous = point.Equity
+		equitySum += point.Equity
+	}
+
+	r.Sharpe = sharpeRatio(returns, b.equity, b.config.RiskFreeRate)
+
+	var traded float64
+
+	for _, fill := range fills {
+		traded += fill.Price * fill.Amount
+		r.Fees += fill.Fee
+	}
+
+	if len(b.equity) > 0 && equitySum > 0 {
+		r.Turnover = traded / (equitySum / float64(len(b.equity)))
+	}
+
+	engine, _ := NewPnLEngine(LotMethodFifo)
+	engine.Ingest(fills...)
+
+	var wins, closed int
+
+	for _, realized := range engine.Realized(time.Time{}, time.Time{}) {
+		closed++
+
+		if realized.PnL > 0 {
+			wins++
+		}
+	}
+
+	if closed > 0 {
+		r.WinRate = float64(wins) / float64(closed)
+	}
+
+	return &r
+}
+
+func (r *BacktestResult) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(r)
+}
+
+func (r *BacktestResult) WriteEquityCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+
+	if err := cw.Write([]string{"time", "equity"}); err != nil {
+		return err
+	}
+
+	for _, point := range r.EquityCurve {
+		if err := cw.Write([]string{point.Time.Format(time.RFC3339), formatFloat(point.Equity)}); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+
+	return cw.Error()
+}
+
+func (r *BacktestResult) WriteFillsCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+
+	if err := cw.Write([]string{"time", "trade_id", "order_id", "pair", "type", "price", "amount", "fee"}); err != nil {
+		return err
+	}
+
+	for _, fill := range r.Fills {
+		if err := cw.Write([]string{
+			fill.Time.Format(time.RFC3339),
+			fill.TradeId,
+			fill.OrderId,
+			fill.Pair,
+			fill.Type,
+			formatFloat(fill.Price),
+			formatFloat(fill.Amount),
+			formatFloat(fill.Fee),
+		}); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+
+	return cw.Error()
+}
+
+/*
+ * Annualized sharpe ratio, the period length is taken from the median spacing of the equity curve
+ *
+ * @param []float64 returns
+ * @param []EquityPoint curve
+ * @param float64 riskFreeRate
+ *
+ * @return float64
+ */
+func sharpeRatio(returns []float64, curve []EquityPoint, riskFreeRate float64) float64 {
+	if len(returns) < 2 || len(curve) < 2 {
+		return 0
+	}
+
+	spacings := make([]float64, 0, len(curve)-1)
+
+	for i := 1; i < len(curve); i++ {
+		spacings = append(spacings, curve[i].Time.Sub(curve[i-1].Time).Seconds())
+	}
+
+	sort.Float64s(spacings)
+
+	spacing := spacings[len(spacings)/2]
+	if spacing <= 0 {
+		return 0
+	}
+
+	periodsPerYear := (365 * 24 * time.Hour).Seconds() / spacing
+	riskFree := riskFreeRate / periodsPerYear
+
+	var mean float64
+
+	for _, r := range returns {
+		mean += r - riskFree
+	}
+
+	mean /= float64(len(returns))
+
+	var variance float64
+
+	for _, r := range returns {
+		variance += math.Pow(r-riskFree-mean, 2)
+	}
+
+	std := math.Sqrt(variance / float64(len(returns)-1))
+	if std == 0 {
+		return 0
+	}
+
+	return mean / std * math.Sqrt(periodsPerYear)
+}
@@ -0,0 +1,129 @@
+package indodax
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+	"time"
+)
+
+type scriptedStrategy struct {
+	BacktestStrategyBase
+	orders map[int]func(bt *Backtest) error
+	bar    int
+}
+
+func (s *scriptedStrategy) OnBar(bt *Backtest, pair string, bar OHLC) error {
+	s.bar++
+
+	if order, exist := s.orders[s.bar]; exist {
+		return order(bt)
+	}
+
+	return nil
+}
+
+func dailyBars(base time.Time, closes ...string) []OHLC {
+	bars := make([]OHLC, 0, len(closes))
+
+	for i, price := range closes {
+		bars = append(bars, OHLC{
+			Time:   base.Add(time.Duration(i) * 24 * time.Hour).Unix(),
+			Open:   json.Number(price),
+			High:   json.Number(price),
+			Low:    json.Number(price),
+			Close:  json.Number(price),
+			Volume: "1000",
+		})
+	}
+
+	return bars
+}
+
+func TestBacktestInitialEquityMarksHeldAssets(t *testing.T) {
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	bt, err := NewBacktest(BacktestConfig{InitialBalances: map[string]float64{"IDR": 1000, "BTC": 1}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bt.AddBars("btc_idr", dailyBars(base, "100", "110", "99", "121")...)
+
+	result, err := bt.Run(&scriptedStrategy{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the starting btc is worth the first close, 1000 + 100
+	if result.InitialEquity != 1100 || result.FinalEquity != 1121 {
+		t.Fatalf("expected equity from 1100 to 1121, got %v to %v", result.InitialEquity, result.FinalEquity)
+	}
+
+	if math.Abs(result.TotalReturn-(1121.0/1100-1)) > 1e-12 {
+		t.Errorf("expected total return %v, got %v", 1121.0/1100-1, result.TotalReturn)
+	}
+
+	// the drawdown is measured from the first close, 1110 to 1099
+	if math.Abs(result.MaxDrawdown-11.0/1110) > 1e-12 {
+		t.Errorf("expected max drawdown %v, got %v", 11.0/1110, result.MaxDrawdown)
+	}
+}
+
+func TestBacktestResultMetrics(t *testing.T) {
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	bt, err := NewBacktest(BacktestConfig{InitialBalances: map[string]float64{CurrencyIdr: 1000}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bt.AddBars("btc_idr", dailyBars(base, "100", "110", "99", "88")...)
+
+	trade := func(tradeType string, amount float64) func(*Backtest) error {
+		return func(bt *Backtest) error {
+			_, err := bt.Trade(tradeType, "btc_idr", OrderTypeMarket, 0, amount, nil, nil, false)
+
+			return err
+		}
+	}
+
+	// buy 5 btc at 100, sell at 110, buy 5 btc at 99 and sell at 88, without fees or slippage
+	result, err := bt.Run(&scriptedStrategy{orders: map[int]func(*Backtest) error{
+		1: trade(TradeTypeBuy, 500),
+		2: trade(TradeTypeSell, 5),
+		3: trade(TradeTypeBuy, 495),
+		4: trade(TradeTypeSell, 5),
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	curve := []float64{1000, 1050, 1050, 995}
+
+	if len(result.EquityCurve) != len(curve) {
+		t.Fatalf("expected %d equity points, got %+v", len(curve), result.EquityCurve)
+	}
+
+	for i, equity := range curve {
+		if math.Abs(result.EquityCurve[i].Equity-equity) > 1e-9 {
+			t.Errorf("equity point %d: expected %v, got %v", i, equity, result.EquityCurve[i].Equity)
+		}
+	}
+
+	// returns 0, 0.05, 0 and 995/1050-1 over daily periods, annualized with sqrt(365)
+	expected := map[string][2]float64{
+		"total return": {result.TotalReturn, -0.005},
+		"max drawdown": {result.MaxDrawdown, 55.0 / 1050},
+		"sharpe":       {result.Sharpe, -0.2720413355357359},
+		"win rate":     {result.WinRate, 0.5},
+		"turnover":     {result.Turnover, (500 + 550 + 495 + 440) / ((1000 + 1050 + 1050 + 995) / 4.0)},
+		"trades":       {float64(result.Trades), 4},
+	}
+
+	for name, values := range expected {
+		if math.Abs(values[0]-values[1]) > 1e-9 {
+			t.Errorf("%s: expected %v, got %v", name, values[1], values[0])
+		}
+	}
+}
@@ -0,0 +1,308 @@
+package indodax
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+type BalanceMonitor struct {
+	client   PrivateAPI
+	config   BalanceMonitorConfig
+	handler  func(BalanceAlert)
+	mu       sync.Mutex
+	checking sync.Mutex
+	last     *BalanceSnapshot
+	expected []expectedBalanceChange
+}
+
+type expectedBalanceChange struct {
+	currency  string
+	reference string
+	amount    float64
+}
+
+func NewBalanceMonitor(client PrivateAPI, config BalanceMonitorConfig) (*BalanceMonitor, error) {
+	if client == nil {
+		return nil, errors.New("client is required")
+	}
+
+	if config.PollInterval <= 0 {
+		config.PollInterval = DefaultBalancePollInterval
+	}
+
+	return &BalanceMonitor{client: client, config: config}, nil
+}
+
+func (m *BalanceMonitor) WithAlertHandler(handler func(BalanceAlert)) *BalanceMonitor {
+	m.handler = handler
+
+	return m
+}
+
+/*
+ * Register a balance change that is known in advance and should not raise an alert,
+ * e.g. transfers made outside of the api
+ *
+ * @param string currency
+ * @param string reference
+ * @param float64 amount
+ *
+ * @return void
+ */
+func (m *BalanceMonitor) ExpectChange(currency, reference string, amount float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.expected = append(m.expected, expectedBalanceChange{
+		currency:  strings.ToLower(currency),
+		reference: reference,
+		amount:    amount,
+	})
+}
+
+func (m *BalanceMonitor) Snapshot() (*BalanceSnapshot, error) {
+	info, err := m.client.GetInfo()
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := BalanceSnapshot{
+		Time: time.Now(),
+		Free: map[string]float64{},
+		Held: map[string]float64{},
+	}
+
+	for currency, amount := range info.Balance {
+		snapshot.Free[strings.ToLower(currency)] = toFloat(amount)
+	}
+
+	for currency, amount := range info.BalanceHold {
+		snapshot.Held[strings.ToLower(currency)] = toFloat(amount)
+	}
+
+	return &snapshot, nil
+}
+
+func (m *BalanceMonitor) Last() *BalanceSnapshot {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.last
+}
+
+/*
+ * Take a new snapshot, compare it with the previous one and raise alerts
+ * for every delta that can not be attributed to known movements. The previous
+ * snapshot is kept when the movements can not be read, so the next check
+ * covers the whole period
+ *
+ * @return []BalanceDelta
+ * @return error
+ */
+func (m *BalanceMonitor) Check() ([]BalanceDelta, error) {
+	m.checking.Lock()
+	defer m.checking.Unlock()
+
+	snapshot, err := m.Snapshot()
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	previous := m.last
+	if previous == nil {
+		m.last = snapshot
+	}
+	m.mu.Unlock()
+
+	if previous == nil {
+		return nil, nil
+	}
+
+	attributions, err := m.attributions(previous.Time, snapshot.Time)
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	expected := m.expected
+	m.expected = nil
+	m.last = snapshot
+	m.mu.Unlock()
+
+	for _, e := range expected {
+		attributions[e.currency] = append(attributions[e.currency], BalanceAttribution{
+			Source:    BalanceSourceExpected,
+			Reference: e.reference,
+			Amount:    e.amount,
+		})
+	}
+
+	currencies := map[string]bool{}
+
+	for _, balances := range []map[string]float64{previous.Free, previous.Held, snapshot.Free, snapshot.Held} {
+		for currency := range balances {
+			currencies[currency] = true
+		}
+	}
+
+	var deltas []BalanceDelta
+	var alerts []BalanceAlert
+
+	for currency := range currencies {
+		delta := BalanceDelta{
+			Currency:     currency,
+			Free:         snapshot.Free[currency] - previous.Free[currency],
+			Held:         snapshot.Held[currency] - previous.Held[currency],
+			Attributions: attributions[currency],
+		}
+
+		delta.Total = delta.Free + delta.Held
+
+		for _, attribution := range delta.Attributions {
+			delta.Explained += attribution.Amount
+		}
+
+		delta.Unexplained = delta.Total - delta.Explained
+
+		if delta.Free == 0 && delta.Held == 0 && len(delta.Attributions) == 0 {
+			continue
+		}
+
+		deltas = append(deltas, delta)
+
+		if math.Abs(delta.Unexplained) > m.tolerance(currency) {
+			alerts = append(alerts, BalanceAlert{From: previous.Time, To: snapshot.Time, Delta: delta})
+		}
+	}
+
+	sort.Slice(deltas, func(i, j int) bool {
+		return deltas[i].Currency < deltas[j].Currency
+	})
+
+	sort.Slice(alerts, func(i, j int) bool {
+		return alerts[i].Delta.Currency < alerts[j].Delta.Currency
+	})
+
+	if m.handler != nil {
+		for _, alert := range alerts {
+			m.handler(alert)
+		}
+	}
+
+	return deltas, nil
+}
+
+func (m *BalanceMonitor) Run(ctx context.Context) error {
+	ticker := time.NewTicker(m.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := m.Check(); err != nil {
+			logApiError(m.client, "failed to check balance", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+/*
+ * Collect known balance movements between two points in time
+ *
+ * @param time.Time from
+ * @param time.Time to
+ *
+ * @return map[string][]BalanceAttribution
+ * @return error
+ */
+func (m *BalanceMonitor) attributions(from, to time.Time) (map[string][]BalanceAttribution, error) {
+	result := map[string][]BalanceAttribution{}
+
+	history, err := getTransactionHistoryRange(m.client, from.AddDate(0, 0, -1), to.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, err
+	}
+
+	for currency, records := range history.Deposit {
+		currency = strings.ToLower(currency)
+
+		for _, record := range records {
+			deposit := parseDeposit(currency, record)
+
+			if deposit.Status != DepositStatusSuccess || !inBalanceWindow(deposit.SuccessAt, from, to) {
+				continue
+			}
+
+			amount, fee := depositTransfer(deposit)
+
+			result[currency] = append(result[currency], BalanceAttribution{
+				Source:    BalanceSourceDeposit,
+				Reference: depositKey(deposit),
+				Amount:    amount - fee,
+			})
+		}
+	}
+
+	for currency, records := range history.Withdraw {
+		currency = strings.ToLower(currency)
+
+		for _, record := range records {
+			status := normalizeWithdrawalStatus(toString(record["status"]))
+			if status == WithdrawalStatusCancelled || !inBalanceWindow(toTime(record["submit_time"]), from, to) {
+				continue
+			}
+
+			amount, fee := withdrawalTransfer(currency, record)
+
+			result[currency] = append(result[currency], BalanceAttribution{
+				Source:    BalanceSourceWithdrawal,
+				Reference: toString(record["withdraw_id"]),
+				Amount:    -(amount + fee),
+			})
+		}
+	}
+
+	for _, pair := range m.config.Pairs {
+		trades, err := getOwnTrades(m.client, pair, &from, &to)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, trade := range trades {
+			if !inBalanceWindow(trade.Time, from, to) {
+				continue
+			}
+
+			for currency, amount := range tradeBalanceEffects(trade) {
+				result[currency] = append(result[currency], BalanceAttribution{
+					Source:    BalanceSourceTrade,
+					Reference: trade.TradeId,
+					Amount:    amount,
+				})
+			}
+		}
+	}
+
+	return result, nil
+}
+
+func (m *BalanceMonitor) tolerance(currency string) float64 {
+	if tolerance, exist := m.config.Tolerances[currency]; exist {
+		return tolerance
+	}
+
+	return m.config.Tolerance
+}
+
+func inBalanceWindow(t, from, to time.Time) bool {
+	return t.After(from) && !t.After(to)
+}
@@ -0,0 +1,90 @@
+package indodax
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestBalanceMonitorAlertHandlerCanUseMonitor(t *testing.T) {
+	wallet := &fakeWallet{balance: map[string]json.Number{"idr": "1000"}}
+
+	monitor, err := NewBalanceMonitor(&fakePrivate{fakeWallet: wallet}, BalanceMonitorConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var alerts []BalanceAlert
+
+	monitor.WithAlertHandler(func(alert BalanceAlert) {
+		if monitor.Last() == nil {
+			t.Error("expected the last snapshot to be set when alerts are raised")
+		}
+
+		alerts = append(alerts, alert)
+	})
+
+	if _, err = monitor.Check(); err != nil {
+		t.Fatal(err)
+	}
+
+	wallet.balance["idr"] = "400"
+
+	done := make(chan error, 1)
+
+	go func() {
+		_, err := monitor.Check()
+		done <- err
+	}()
+
+	select {
+	case err = <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("check deadlocked while calling the alert handler")
+	}
+
+	if len(alerts) != 1 || alerts[0].Delta.Unexplained != -600 {
+		t.Fatalf("expected an unexplained -600 idr alert, got %+v", alerts)
+	}
+}
+
+func TestBalanceMonitorKeepsSnapshotWhenAttributionFails(t *testing.T) {
+	wallet := &fakeWallet{balance: map[string]json.Number{"btc": "1"}}
+
+	monitor, err := NewBalanceMonitor(&fakePrivate{fakeWallet: wallet}, BalanceMonitorConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = monitor.Check(); err != nil {
+		t.Fatal(err)
+	}
+
+	first := monitor.Last()
+
+	wallet.balance["btc"] = "0.5"
+	wallet.historyErr = errors.New("exchange unavailable")
+
+	if _, err = monitor.Check(); err == nil {
+		t.Fatal("expected the check to fail")
+	}
+
+	if monitor.Last() != first {
+		t.Fatal("expected the previous snapshot to be kept when attribution fails")
+	}
+
+	wallet.historyErr = nil
+
+	deltas, err := monitor.Check()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(deltas) != 1 || deltas[0].Free != -0.5 {
+		t.Fatalf("expected the withdrawal of 0.5 btc to be reported, got %+v", deltas)
+	}
+}
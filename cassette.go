@@ -0,0 +1,374 @@
+package indodax
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	cassetteRedacted  = "REDACTED"
+	cassetteTimestamp = "0"
+	cassetteId        = "ID"
+)
+
+var ErrCassetteMismatch = errors.New("no matching cassette interaction")
+
+var cassetteSecretHeaders = []string{"Key", "Sign", "Authorization"}
+
+// generated per request, a replayed request never repeats the recorded value
+var cassetteGeneratedParams = []string{"client_order_id", "request_id"}
+
+type CassetteRecorder struct {
+	Path         string
+	Base         http.RoundTripper
+	Interactions []CassetteInteraction
+	mu           sync.Mutex
+}
+
+type CassetteReplayer struct {
+	Path         string
+	Interactions []CassetteInteraction
+	mu           sync.Mutex
+	used         []bool
+}
+
+func NewCassetteRecorder(path string, base http.RoundTripper) *CassetteRecorder {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	return &CassetteRecorder{Path: path, Base: base}
+}
+
+/*
+ * Send request through the base transport and record the scrubbed interaction
+ *
+ * @param *http.Request req
+ *
+ * @return *http.Response
+ * @return error
+ */
+func (r *CassetteRecorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	reqBody, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := r.Base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	respBody, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+
+	if err != nil {
+		return nil, err
+	}
+
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	interaction := CassetteInteraction{
+		Request: CassetteRequest{
+			Method: req.Method,
+			Url:    req.URL.String(),
+			Header: scrubCassetteHeader(req.Header),
+			Body:   normalizeCassetteBody(string(reqBody)),
+		},
+		Response: CassetteResponse{
+			StatusCode: resp.StatusCode,
+			Header:     scrubCassetteHeader(resp.Header),
+			Body:       scrubCassetteResponseBody(respBody),
+		},
+	}
+
+	r.mu.Lock()
+	r.Interactions = append(r.Interactions, interaction)
+	r.mu.Unlock()
+
+	return resp, nil
+}
+
+func (r *CassetteRecorder) Save() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	b, err := json.MarshalIndent(map[string]interface{}{"interactions": r.Interactions}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(r.Path, b, 0600)
+}
+
+func NewCassetteReplayer(path string) (*CassetteReplayer, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var cassette struct {
+		Interactions []CassetteInteraction `json:"interactions"`
+	}
+
+	if err = json.Unmarshal(b, &cassette); err != nil {
+		return nil, err
+	}
+
+	return &CassetteReplayer{
+		Path:         path,
+		Interactions: cassette.Interactions,
+		used:         make([]bool, len(cassette.Interactions)),
+	}, nil
+}
+
+/*
+ * Serve the first unused recorded interaction matching method, url and
+ * normalized body, unmatched requests fail with ErrCassetteMismatch
+ *
+ * @param *http.Request req
+ *
+ * @return *http.Response
+ * @return error
+ */
+func (r *CassetteReplayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	reqBody, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	body := normalizeCassetteBody(string(reqBody))
+	target := req.URL.String()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, interaction := range r.Interactions {
+		if r.used[i] || interaction.Request.Method != req.Method || interaction.Request.Url != target || interaction.Request.Body != body {
+			continue
+		}
+
+		r.used[i] = true
+
+		header := http.Header{}
+
+		for k, v := range interaction.Response.Header {
+			header.Set(k, v)
+		}
+
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", interaction.Response.StatusCode, http.StatusText(interaction.Response.StatusCode)),
+			StatusCode:    interaction.Response.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        header,
+			Body:          io.NopCloser(strings.NewReader(interaction.Response.Body)),
+			ContentLength: int64(len(interaction.Response.Body)),
+			Request:       req,
+		}, nil
+	}
+
+	return nil, fmt.Errorf("%w: %s %s %s", ErrCassetteMismatch, req.Method, target, body)
+}
+
+/*
+ * Number of recorded interactions that have not been replayed
+ *
+ * @return int
+ */
+func (r *CassetteReplayer) Remaining() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var remaining int
+
+	for _, used := range r.used {
+		if !used {
+			remaining++
+		}
+	}
+
+	return remaining
+}
+
+func readRequestBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil {
+		return nil, nil
+	}
+
+	b, err := io.ReadAll(req.Body)
+	_ = req.Body.Close()
+
+	if err != nil {
+		return nil, err
+	}
+
+	req.Body = io.NopCloser(bytes.NewReader(b))
+
+	return b, nil
+}
+
+func scrubCassetteHeader(header http.Header) map[string]string {
+	result := map[string]string{}
+
+	for k := range header {
+		result[k] = header.Get(k)
+	}
+
+	for _, k := range cassetteSecretHeaders {
+		if _, exist := result[http.CanonicalHeaderKey(k)]; exist {
+			result[http.CanonicalHeaderKey(k)] = cassetteRedacted
+		}
+	}
+
+	return result
+}
+
+/*
+ * Normalize form encoded body so requests can be matched regardless of
+ * timestamp, generated ids and parameter order. Personal data and secrets
+ * are redacted with the keys redacted by the logger
+ *
+ * @param string body
+ *
+ * @return string
+ */
+func normalizeCassetteBody(body string) string {
+	values, err := url.ParseQuery(body)
+	if err != nil || len(values) == 0 {
+		return body
+	}
+
+	if values.Has("timestamp") {
+		values.Set("timestamp", cassetteTimestamp)
+	}
+
+	for _, k := range cassetteGeneratedParams {
+		if values.Has(k) {
+			values.Set(k, cassetteId)
+		}
+	}
+
+	redact := cassetteRedactKeys()
+
+	for k := range values {
+		if redactValue(k, "", redact) == redactedValue {
+			values.Set(k, cassetteRedacted)
+		}
+	}
+
+	keys := make([]string, 0, len(values))
+
+	for k := range values {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	normalized := url.Values{}
+
+	for _, k := range keys {
+		normalized[k] = values[k]
+	}
+
+	return normalized.Encode()
+}
+
+/*
+ * Redact personal data and secrets of a json response body, bodies that are
+ * not json are recorded as they are
+ *
+ * @param []byte body
+ *
+ * @return string
+ */
+func scrubCassetteResponseBody(body []byte) string {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+
+	var decoded interface{}
+
+	if err := decoder.Decode(&decoded); err != nil {
+		return string(body)
+	}
+
+	scrubbed, err := json.Marshal(scrubCassetteValue("", decoded, cassetteRedactKeys(), false))
+	if err != nil {
+		return string(body)
+	}
+
+	return string(scrubbed)
+}
+
+/*
+ * Redact the leaf values below a sensitive key and keep the shape of the value, so a
+ * scrubbed response still decodes into the same types. Maps keep their keys, strings
+ * are replaced with REDACTED, and numbers and numeric strings with 0
+ *
+ * @param string key
+ * @param interface{} value
+ * @param map[string]bool redact
+ * @param bool sensitive
+ *
+ * @return interface{}
+ */
+func scrubCassetteValue(key string, value interface{}, redact map[string]bool, sensitive bool) interface{} {
+	sensitive = sensitive || isRedactedKey(strings.ToLower(key), redact)
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(v))
+
+		for k, item := range v {
+			result[k] = scrubCassetteValue(k, item, redact, sensitive)
+		}
+
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(v))
+
+		for i, item := range v {
+			result[i] = scrubCassetteValue(key, item, redact, sensitive)
+		}
+
+		return result
+	case string:
+		if !sensitive || len(v) == 0 {
+			return value
+		}
+
+		// numeric strings, such as user ids, stay numeric
+		if _, err := strconv.ParseFloat(v, 64); err == nil {
+			return "0"
+		}
+
+		return cassetteRedacted
+	case json.Number:
+		if sensitive {
+			return json.Number("0")
+		}
+	}
+
+	return value
+}
+
+func cassetteRedactKeys() map[string]bool {
+	keys := make(map[string]bool, len(defaultRedactKeys))
+
+	for _, key := range defaultRedactKeys {
+		keys[key] = true
+	}
+
+	return keys
+}
@@ -0,0 +1,123 @@
+package indodax
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCassetteRecordAndReplay(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, `{"success":1,"return":{"name":"Jane","email":"jane@example.com","balance":{"idr":"1000.5"},"withdraw_address":"addr-1"}}`)
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	recorder := NewCassetteRecorder(path, nil)
+
+	send := func(transport http.RoundTripper, body string) (string, error) {
+		req, err := http.NewRequest(http.MethodPost, server.URL+"/tapi", strings.NewReader(body))
+		if err != nil {
+			return "", err
+		}
+
+		req.Header.Set("Key", "api-key")
+		req.Header.Set("Sign", "signature")
+
+		resp, err := transport.RoundTrip(req)
+		if err != nil {
+			return "", err
+		}
+
+		defer resp.Body.Close()
+
+		b, err := io.ReadAll(resp.Body)
+
+		return string(b), err
+	}
+
+	if _, err := send(recorder, "method=trade&client_order_id=abc-1&timestamp=1&withdraw_memo=memo-7&withdraw_address=addr-1&request_id=r-1"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := recorder.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	recorded := recorder.Interactions[0]
+
+	for _, secret := range []string{"abc-1", "r-1", "addr-1", "memo-7", "Jane", "jane@example.com", "api-key", "signature"} {
+		if strings.Contains(recorded.Request.Body, secret) || strings.Contains(recorded.Response.Body, secret) || strings.Contains(recorded.Request.Header["Key"]+recorded.Request.Header["Sign"], secret) {
+			t.Errorf("cassette contains %q: %+v", secret, recorded)
+		}
+	}
+
+	if !strings.Contains(recorded.Response.Body, `"1000.5"`) {
+		t.Errorf("expected balances to be kept, got %s", recorded.Response.Body)
+	}
+
+	replayer, err := NewCassetteReplayer(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	body, err := send(replayer, "method=trade&client_order_id=abc-2&timestamp=2&withdraw_memo=memo-7&withdraw_address=addr-1&request_id=r-2")
+	if err != nil {
+		t.Fatalf("expected a request with new generated ids to match: %v", err)
+	}
+
+	if !strings.Contains(body, `"success":1`) || replayer.Remaining() != 0 {
+		t.Fatalf("unexpected replay %s, remaining %d", body, replayer.Remaining())
+	}
+}
+
+func TestCassetteReplaysScrubbedGetInfo(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, `{"success":1,"return":{"user_id":"123456","name":"Jane Doe","email":"jane@example.com","profile_picture":null,"verification_status":"verified","gauth_enable":true,"withdraw_status":1,`+
+			`"balance":{"idr":"1000.5","btc":"0.1"},"balance_hold":{"idr":"0","btc":"0"},"memo_is_required":{"xrp":{"memo":true}},`+
+			`"address":{"btc":"bc1-secret","xrp":"r-secret"},"network":{"btc":"mainnet"},"server_time":1700000000}}`)
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "getinfo.json")
+	recorder := NewCassetteRecorder(path, nil)
+
+	recording := New(Config{PrivateApiBaseUrl: server.URL}).WithCredential("api-key", "api-secret").WithHttpClient(&http.Client{Transport: recorder})
+
+	if _, err := recording.GetInfo(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := recorder.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, secret := range []string{"123456", "Jane Doe", "jane@example.com", "bc1-secret", "r-secret", "api-key"} {
+		if strings.Contains(recorder.Interactions[0].Response.Body+recorder.Interactions[0].Request.Body, secret) {
+			t.Errorf("cassette contains %q", secret)
+		}
+	}
+
+	replayer, err := NewCassetteReplayer(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	replaying := New(Config{PrivateApiBaseUrl: server.URL}).WithCredential("api-key", "api-secret").WithHttpClient(&http.Client{Transport: replayer})
+
+	info, err := replaying.GetInfo()
+	if err != nil {
+		t.Fatalf("expected the scrubbed cassette to replay: %v", err)
+	}
+
+	if info.UserId.String() != "0" || info.Name != cassetteRedacted || info.Address["btc"] != cassetteRedacted || info.Address["xrp"] != cassetteRedacted {
+		t.Errorf("expected personal data to be redacted, got %+v", info)
+	}
+
+	if info.Balance["idr"].String() != "1000.5" || !info.MemoIsRequired["xrp"]["memo"] || info.VerificationStatus != "verified" {
+		t.Errorf("expected other fields to be kept, got %+v", info)
+	}
+}
@@ -0,0 +1,424 @@
+package indodax
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/vannleonheart/goutil"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+/*
+ * CircuitOpenError is returned without calling the exchange while the circuit of a
+ * group is open or the traded pair is in maintenance, it matches ErrCircuitOpen
+ */
+type CircuitOpenError struct {
+	Group   string
+	Pair    string
+	Reason  string
+	RetryAt time.Time
+}
+
+func (e *CircuitOpenError) Error() string {
+	if len(e.Pair) > 0 {
+		return fmt.Sprintf("%s: %s %s: %s", ErrCircuitOpen, e.Group, e.Pair, e.Reason)
+	}
+
+	return fmt.Sprintf("%s: %s: %s", ErrCircuitOpen, e.Group, e.Reason)
+}
+
+func (e *CircuitOpenError) Unwrap() error {
+	return ErrCircuitOpen
+}
+
+type CircuitBreaker struct {
+	api        PublicAPI
+	config     CircuitBreakerConfig
+	handler    func(CircuitStateChange)
+	mu         sync.Mutex
+	circuits   map[string]*circuit
+	pairs      map[string]Pair
+	pairsAt    time.Time
+	refreshing bool
+	changes    []CircuitStateChange
+}
+
+type circuit struct {
+	state     string
+	failures  int
+	successes int
+	probes    int
+	openedAt  time.Time
+	reason    string
+}
+
+/*
+ * Create a circuit breaker, pair maintenance is read from api.GetPairs and
+ * is not checked when api is nil
+ *
+ * @param PublicAPI api
+ * @param CircuitBreakerConfig config
+ *
+ * @return *CircuitBreaker
+ */
+func NewCircuitBreaker(api PublicAPI, config CircuitBreakerConfig) *CircuitBreaker {
+	if config.FailureThreshold <= 0 {
+		config.FailureThreshold = DefaultCircuitFailureThreshold
+	}
+
+	if config.OpenTimeout <= 0 {
+		config.OpenTimeout = DefaultCircuitOpenTimeout
+	}
+
+	if config.HalfOpenProbes <= 0 {
+		config.HalfOpenProbes = DefaultCircuitHalfOpenProbes
+	}
+
+	if config.PairStatusInterval <= 0 {
+		config.PairStatusInterval = DefaultPairStatusInterval
+	}
+
+	if config.Group == nil {
+		config.Group = DefaultCircuitGroup
+	}
+
+	return &CircuitBreaker{
+		api:      api,
+		config:   config,
+		circuits: map[string]*circuit{},
+		pairs:    map[string]Pair{},
+	}
+}
+
+func (c *Client) WithCircuitBreaker(breaker *CircuitBreaker) *Client {
+	return c.WithMiddleware(breaker.Middleware())
+}
+
+func (b *CircuitBreaker) WithHandler(handler func(CircuitStateChange)) *CircuitBreaker {
+	b.handler = handler
+
+	return b
+}
+
+/*
+ * Group public calls as market data, and private calls as trading, withdrawal or account calls
+ *
+ * @param *ApiRequest req
+ *
+ * @return string
+ */
+func DefaultCircuitGroup(req *ApiRequest) string {
+	if req.Api == ApiPublic {
+		return CircuitGroupMarket
+	}
+
+	switch req.Method {
+	case MethodTrade, MethodCancelOrder, MethodCancelOrderByClientOrderId:
+		return CircuitGroupTrade
+	case MethodWithdrawFee, MethodWithdrawCoin:
+		return CircuitGroupWithdraw
+	}
+
+	return CircuitGroupAccount
+}
+
+func (b *CircuitBreaker) Middleware() Middleware {
+	return func(next ApiHandler) ApiHandler {
+		return func(req *ApiRequest) (*[]byte, error) {
+			group := b.config.Group(req)
+
+			if req.Method == MethodTrade && len(req.Pair) > 0 {
+				if err := b.checkPair(group, req.Pair); err != nil {
+					return nil, err
+				}
+			}
+
+			probe, err := b.allow(group)
+			if err != nil {
+				return nil, err
+			}
+
+			raw, err := next(req)
+
+			b.record(group, probe, err)
+
+			return raw, err
+		}
+	}
+}
+
+func (b *CircuitBreaker) State(group string) string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if cb, exist := b.circuits[group]; exist {
+		return cb.state
+	}
+
+	return CircuitStateClosed
+}
+
+func (b *CircuitBreaker) States() map[string]string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	states := make(map[string]string, len(b.circuits))
+
+	for group, cb := range b.circuits {
+		states[group] = cb.state
+	}
+
+	return states
+}
+
+func (b *CircuitBreaker) Reset(group string) {
+	b.mu.Lock()
+	defer b.emit()
+	defer b.mu.Unlock()
+
+	if cb, exist := b.circuits[group]; exist {
+		b.setState(group, cb, CircuitStateClosed, "reset")
+	}
+}
+
+/*
+ * Replace the known pair statuses, usually with the result of GetPairs
+ *
+ * @param []Pair pairs
+ *
+ * @return void
+ */
+func (b *CircuitBreaker) UpdatePairs(pairs []Pair) {
+	statuses := make(map[string]Pair, len(pairs))
+
+	for _, pair := range pairs {
+		statuses[circuitPairKey(pair.Id)] = pair
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.pairs = statuses
+	b.pairsAt = time.Now()
+}
+
+func (b *CircuitBreaker) RefreshPairs() error {
+	if b.api == nil {
+		return nil
+	}
+
+	pairs, err := b.api.GetPairs()
+	if err != nil {
+		return err
+	}
+
+	if pairs != nil {
+		b.UpdatePairs(*pairs)
+	}
+
+	return nil
+}
+
+/*
+ * Fail fast when the pair is in maintenance or its market is suspended. Pair statuses
+ * are refreshed when they are older than PairStatusInterval, a failed refresh keeps
+ * the previous statuses
+ *
+ * @param string group
+ * @param string pair
+ *
+ * @return error
+ */
+func (b *CircuitBreaker) checkPair(group, pair string) error {
+	b.mu.Lock()
+	refresh := b.api != nil && !b.refreshing && time.Since(b.pairsAt) >= b.config.PairStatusInterval
+	if refresh {
+		b.refreshing = true
+	}
+	b.mu.Unlock()
+
+	if refresh {
+		err := b.RefreshPairs()
+
+		b.mu.Lock()
+		b.refreshing = false
+		if err != nil {
+			b.pairsAt = time.Now()
+		}
+		b.mu.Unlock()
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	status, exist := b.pairs[circuitPairKey(pair)]
+	if !exist {
+		return nil
+	}
+
+	var reason string
+
+	switch {
+	case status.IsMaintenance == 1:
+		reason = "pair is in maintenance"
+	case status.IsMarketSuspended == 1:
+		reason = "market is suspended"
+	default:
+		return nil
+	}
+
+	return &CircuitOpenError{
+		Group:   group,
+		Pair:    pair,
+		Reason:  reason,
+		RetryAt: b.pairsAt.Add(b.config.PairStatusInterval),
+	}
+}
+
+/*
+ * Allow a call of the group, an open circuit becomes half open after OpenTimeout
+ * and then lets HalfOpenProbes calls through at a time
+ *
+ * @param string group
+ *
+ * @return bool whether the call is a probe of a half open circuit
+ * @return error
+ */
+func (b *CircuitBreaker) allow(group string) (bool, error) {
+	b.mu.Lock()
+	defer b.emit()
+	defer b.mu.Unlock()
+
+	cb, exist := b.circuits[group]
+	if !exist {
+		cb = &circuit{state: CircuitStateClosed}
+		b.circuits[group] = cb
+	}
+
+	if cb.state == CircuitStateOpen {
+		retryAt := cb.openedAt.Add(b.config.OpenTimeout)
+
+		if time.Now().Before(retryAt) {
+			return false, &CircuitOpenError{Group: group, Reason: cb.reason, RetryAt: retryAt}
+		}
+
+		b.setState(group, cb, CircuitStateHalfOpen, "open timeout elapsed")
+	}
+
+	if cb.state == CircuitStateHalfOpen {
+		if cb.probes >= b.config.HalfOpenProbes {
+			return false, &CircuitOpenError{Group: group, Reason: "waiting for probe requests", RetryAt: time.Now().Add(b.config.OpenTimeout)}
+		}
+
+		cb.probes++
+
+		return true, nil
+	}
+
+	return false, nil
+}
+
+func (b *CircuitBreaker) record(group string, probe bool, err error) {
+	b.mu.Lock()
+	defer b.emit()
+	defer b.mu.Unlock()
+
+	cb := b.circuits[group]
+
+	probe = probe && cb.state == CircuitStateHalfOpen
+
+	if probe {
+		cb.probes--
+	}
+
+	if !isCircuitFailure(err) {
+		cb.failures = 0
+
+		if probe {
+			cb.successes++
+
+			if cb.successes >= b.config.HalfOpenProbes {
+				b.setState(group, cb, CircuitStateClosed, "probe requests succeeded")
+			}
+		}
+
+		return
+	}
+
+	cb.failures++
+
+	switch {
+	case probe:
+		b.setState(group, cb, CircuitStateOpen, fmt.Sprintf("probe request failed: %s", err))
+	case cb.state == CircuitStateClosed && cb.failures >= b.config.FailureThreshold:
+		b.setState(group, cb, CircuitStateOpen, fmt.Sprintf("%d consecutive failures, last: %s", cb.failures, err))
+	}
+}
+
+func (b *CircuitBreaker) setState(group string, cb *circuit, state, reason string) {
+	from := cb.state
+
+	cb.state = state
+	cb.reason = reason
+	cb.failures = 0
+	cb.successes = 0
+	cb.probes = 0
+
+	if state == CircuitStateOpen {
+		cb.openedAt = time.Now()
+	}
+
+	if from != state {
+		b.changes = append(b.changes, CircuitStateChange{Group: group, From: from, To: state, Reason: reason})
+	}
+}
+
+/*
+ * Deliver state changes to the handler, outside of the lock so the handler may use the breaker
+ *
+ * @return void
+ */
+func (b *CircuitBreaker) emit() {
+	b.mu.Lock()
+	changes := b.changes
+	b.changes = nil
+	b.mu.Unlock()
+
+	if b.handler == nil {
+		return
+	}
+
+	for _, change := range changes {
+		b.handler(change)
+	}
+}
+
+/*
+ * Errors of an unavailable exchange count as failures, rejected requests do not
+ *
+ * @param error err
+ *
+ * @return bool
+ */
+func isCircuitFailure(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	var httpErr goutil.HttpResponseError
+
+	if errors.As(err, &httpErr) && httpErr.Code == http.StatusTooManyRequests {
+		return true
+	}
+
+	return isAmbiguousError(err)
+}
+
+func circuitPairKey(pair string) string {
+	return strings.ReplaceAll(strings.ToLower(pair), "_", "")
+}
@@ -0,0 +1,139 @@
+package indodax
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/vannleonheart/goutil"
+)
+
+func TestCircuitBreakerOpensAndRecovers(t *testing.T) {
+	var mu sync.Mutex
+	var changes []CircuitStateChange
+
+	breaker := NewCircuitBreaker(nil, CircuitBreakerConfig{FailureThreshold: 2, OpenTimeout: 50 * time.Millisecond, HalfOpenProbes: 1})
+
+	breaker.WithHandler(func(change CircuitStateChange) {
+		// the handler may use the breaker
+		_ = breaker.State(change.Group)
+
+		mu.Lock()
+		changes = append(changes, change)
+		mu.Unlock()
+	})
+
+	var failure error
+	calls := 0
+
+	handler := breaker.Middleware()(func(req *ApiRequest) (*[]byte, error) {
+		calls++
+		return nil, failure
+	})
+
+	req := &ApiRequest{Api: ApiPrivate, Method: MethodTrade}
+
+	failure = goutil.HttpResponseError{Code: 503}
+
+	for i := 0; i < 2; i++ {
+		_, _ = handler(req)
+	}
+
+	if state := breaker.State(CircuitGroupTrade); state != CircuitStateOpen {
+		t.Fatalf("expected the trade circuit to open, got %s", state)
+	}
+
+	if _, err := handler(req); !errors.Is(err, ErrCircuitOpen) || calls != 2 {
+		t.Fatalf("expected the open circuit to fail fast, got %v after %d calls", err, calls)
+	}
+
+	if state := breaker.State(CircuitGroupAccount); state != CircuitStateClosed {
+		t.Fatalf("expected other groups to stay closed, got %s", state)
+	}
+
+	time.Sleep(60 * time.Millisecond)
+
+	failure = nil
+
+	if _, err := handler(req); err != nil {
+		t.Fatal(err)
+	}
+
+	if state := breaker.State(CircuitGroupTrade); state != CircuitStateClosed {
+		t.Fatalf("expected the successful probe to close the circuit, got %s", state)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	want := []string{CircuitStateOpen, CircuitStateHalfOpen, CircuitStateClosed}
+
+	if len(changes) != len(want) {
+		t.Fatalf("expected %d state changes, got %+v", len(want), changes)
+	}
+
+	for i, state := range want {
+		if changes[i].To != state {
+			t.Errorf("change %d: expected %s, got %+v", i, state, changes[i])
+		}
+	}
+}
+
+func TestCircuitBreakerFailedProbeReopens(t *testing.T) {
+	breaker := NewCircuitBreaker(nil, CircuitBreakerConfig{FailureThreshold: 1, OpenTimeout: 20 * time.Millisecond})
+
+	handler := breaker.Middleware()(func(req *ApiRequest) (*[]byte, error) {
+		return nil, goutil.HttpResponseError{Code: 502}
+	})
+
+	req := &ApiRequest{Api: ApiPublic, Endpoint: "/api/ticker"}
+
+	_, _ = handler(req)
+
+	time.Sleep(30 * time.Millisecond)
+
+	_, _ = handler(req)
+
+	if state := breaker.State(CircuitGroupMarket); state != CircuitStateOpen {
+		t.Fatalf("expected the failed probe to reopen the circuit, got %s", state)
+	}
+}
+
+func TestCircuitBreakerIgnoresRejectedRequests(t *testing.T) {
+	breaker := NewCircuitBreaker(nil, CircuitBreakerConfig{FailureThreshold: 1})
+
+	handler := breaker.Middleware()(func(req *ApiRequest) (*[]byte, error) {
+		return nil, goutil.HttpResponseError{Code: 400}
+	})
+
+	for i := 0; i < 3; i++ {
+		_, _ = handler(&ApiRequest{Api: ApiPrivate, Method: MethodGetInfo})
+	}
+
+	if state := breaker.State(CircuitGroupAccount); state != CircuitStateClosed {
+		t.Fatalf("expected rejected requests not to open the circuit, got %s", state)
+	}
+}
+
+func TestCircuitBreakerPairMaintenance(t *testing.T) {
+	breaker := NewCircuitBreaker(nil, CircuitBreakerConfig{})
+	breaker.UpdatePairs([]Pair{{Id: "btcidr", IsMaintenance: 1}, {Id: "ethidr"}})
+
+	calls := 0
+
+	handler := breaker.Middleware()(func(req *ApiRequest) (*[]byte, error) {
+		calls++
+		return nil, nil
+	})
+
+	var openErr *CircuitOpenError
+
+	if _, err := handler(&ApiRequest{Api: ApiPrivate, Method: MethodTrade, Pair: "btc_idr"}); !errors.As(err, &openErr) || openErr.Pair != "btc_idr" {
+		t.Fatalf("expected the pair in maintenance to fail fast, got %v", err)
+	}
+
+	if _, err := handler(&ApiRequest{Api: ApiPrivate, Method: MethodTrade, Pair: "eth_idr"}); err != nil || calls != 1 {
+		t.Fatalf("expected other pairs to be traded, got %v after %d calls", err, calls)
+	}
+}
@@ -0,0 +1,139 @@
+package indodax
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/vannleonheart/goutil"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+var ErrOrderOutcomeUnknown = errors.New("order outcome is unknown")
+
+/*
+ * Generate a collision resistant client order id from the current time in
+ * milliseconds and 64 random bits
+ *
+ * @param string prefix
+ *
+ * @return string
+ */
+func NewClientOrderId(prefix string) string {
+	random := make([]byte, 8)
+
+	if _, err := rand.Read(random); err != nil {
+		return fmt.Sprintf("%s%s", prefix, strconv.FormatInt(time.Now().UnixNano(), 36))
+	}
+
+	return fmt.Sprintf("%s%s%s", prefix, strconv.FormatInt(time.Now().UnixMilli(), 36), hex.EncodeToString(random))
+}
+
+/*
+ * Look up an order by client order id after an ambiguous failure, only an answer
+ * from the exchange that the order does not exist returns ErrOrderNotFound, any
+ * other failure leaves the outcome unknown
+ *
+ * @param context.Context ctx
+ * @param string clientOrderId
+ *
+ * @return map[string]interface{}
+ * @return error
+ */
+func (c *Client) resolveClientOrder(ctx context.Context, clientOrderId string) (map[string]interface{}, error) {
+	delay := c.Config.TradeResolveDelay
+
+	if delay <= 0 {
+		delay = DefaultTradeResolveDelay
+	}
+
+	var err error
+
+	for attempt := 0; attempt < DefaultTradeResolveAttempts; attempt++ {
+		timer := time.NewTimer(delay * time.Duration(attempt+1))
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+
+		if attempt > 0 {
+			c.Metrics.ObserveRetry(ApiPrivate, MethodGetOrderByClientOrderId)
+		}
+
+		var resp *GetOrderResponseBody
+
+		resp, err = c.GetOrderByClientOrderIdContext(ctx, clientOrderId)
+		if err == nil && len(resp.Order) > 0 {
+			return resp.Order, nil
+		}
+
+		if errors.Is(err, ErrOrderNotFound) {
+			return nil, err
+		}
+
+		if err == nil {
+			err = errors.New("empty order response")
+			continue
+		}
+
+		if !isAmbiguousError(err) {
+			return nil, err
+		}
+	}
+
+	return nil, err
+}
+
+/*
+ * Check whether a request may have reached the exchange without a definite answer,
+ * transport errors and server errors are ambiguous while api errors, requests
+ * cancelled by the caller and requests that could not be built are not
+ *
+ * @param error err
+ *
+ * @return bool
+ */
+func isAmbiguousError(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	var httpErr goutil.HttpResponseError
+
+	if errors.As(err, &httpErr) {
+		return httpErr.Code >= 500
+	}
+
+	if errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF) {
+		return true
+	}
+
+	// url.Error implements net.Error itself, so look at the error it wraps
+	var urlErr *url.Error
+
+	for errors.As(err, &urlErr) {
+		err = urlErr.Err
+	}
+
+	var netErr net.Error
+
+	return errors.As(err, &netErr)
+}
+
+func orderResponse(order map[string]interface{}) *ResponseBody {
+	ret := map[string]interface{}{}
+
+	for k, v := range order {
+		ret[k] = v
+	}
+
+	return &ResponseBody{Success: 1, Return: ret}
+}
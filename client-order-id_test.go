@@ -0,0 +1,173 @@
+package indodax
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/vannleonheart/goutil"
+)
+
+// tradeServer drops the connection of the first trade request after optionally
+// placing the order, and answers client order id lookups with lookup
+type tradeServer struct {
+	*httptest.Server
+	mu         sync.Mutex
+	placeFirst bool
+	lookup     string
+	trades     int
+	orders     map[string]bool
+}
+
+func newTradeServer(t *testing.T, placeFirst bool, lookup string) *tradeServer {
+	s := &tradeServer{placeFirst: placeFirst, lookup: lookup, orders: map[string]bool{}}
+
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		clientOrderId := r.PostForm.Get("client_order_id")
+
+		switch r.PostForm.Get("method") {
+		case MethodTrade:
+			s.trades++
+
+			if s.trades == 1 {
+				if s.placeFirst {
+					s.orders[clientOrderId] = true
+				}
+
+				conn, _, err := w.(http.Hijacker).Hijack()
+				if err == nil {
+					_ = conn.Close()
+				}
+
+				return
+			}
+
+			s.orders[clientOrderId] = true
+			_, _ = fmt.Fprintf(w, `{"success":1,"return":{"order_id":"2","client_order_id":%q}}`, clientOrderId)
+		case MethodGetOrderByClientOrderId:
+			switch {
+			case s.lookup == "error":
+				_, _ = io.WriteString(w, `{"success":0,"error":"Invalid credentials","error_code":"invalid_credentials"}`)
+			case s.orders[clientOrderId]:
+				_, _ = fmt.Fprintf(w, `{"success":1,"return":{"order":{"order_id":"1","client_order_id":%q,"status":"open"}}}`, clientOrderId)
+			default:
+				_, _ = io.WriteString(w, `{"success":0,"error":"Order not found","error_code":"order_not_found"}`)
+			}
+		}
+	}))
+
+	t.Cleanup(s.Close)
+
+	return s
+}
+
+func (s *tradeServer) client() *Client {
+	return New(Config{PrivateApiBaseUrl: s.URL, TradeResolveDelay: time.Millisecond}).WithCredential("key", "secret")
+}
+
+func (s *tradeServer) placed() (int, int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.trades, len(s.orders)
+}
+
+func TestTradeDoesNotPlaceTwiceWhenLookupFails(t *testing.T) {
+	server := newTradeServer(t, true, "error")
+
+	_, err := server.client().Trade(TradeTypeBuy, "btc_idr", OrderTypeLimit, 100, 1, nil, nil, true)
+	if !errors.Is(err, ErrOrderOutcomeUnknown) {
+		t.Fatalf("expected ErrOrderOutcomeUnknown, got %v", err)
+	}
+
+	if trades, orders := server.placed(); trades != 1 || orders != 1 {
+		t.Fatalf("expected the order to be sent once, got %d trade requests and %d orders", trades, orders)
+	}
+}
+
+func TestTradeResolvesPlacedOrder(t *testing.T) {
+	server := newTradeServer(t, true, "")
+
+	resp, err := server.client().Trade(TradeTypeBuy, "btc_idr", OrderTypeLimit, 100, 1, nil, nil, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if ret, _ := resp.Return.(map[string]interface{}); toString(ret["order_id"]) != "1" {
+		t.Fatalf("expected the placed order to be returned, got %+v", resp.Return)
+	}
+
+	if trades, orders := server.placed(); trades != 1 || orders != 1 {
+		t.Fatalf("expected the order to be sent once, got %d trade requests and %d orders", trades, orders)
+	}
+}
+
+func TestTradeRetriesWhenOrderNotFound(t *testing.T) {
+	server := newTradeServer(t, false, "")
+
+	if _, err := server.client().Trade(TradeTypeBuy, "btc_idr", OrderTypeLimit, 100, 1, nil, nil, true); err != nil {
+		t.Fatal(err)
+	}
+
+	if trades, orders := server.placed(); trades != 2 || orders != 1 {
+		t.Fatalf("expected one retry and one order, got %d trade requests and %d orders", trades, orders)
+	}
+}
+
+func TestTradeResolveStopsOnCancel(t *testing.T) {
+	server := newTradeServer(t, true, "")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	client := New(Config{PrivateApiBaseUrl: server.URL, TradeResolveDelay: time.Hour}).WithCredential("key", "secret").WithContext(ctx)
+
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	start := time.Now()
+
+	_, err := client.Trade(TradeTypeBuy, "btc_idr", OrderTypeLimit, 100, 1, nil, nil, true)
+	if !errors.Is(err, ErrOrderOutcomeUnknown) {
+		t.Fatalf("expected ErrOrderOutcomeUnknown, got %v", err)
+	}
+
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("expected the lookup to stop on cancel, took %s", elapsed)
+	}
+}
+
+func TestIsAmbiguousError(t *testing.T) {
+	_, parseErr := url.Parse("://invalid")
+
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"canceled", &url.Error{Op: "Post", URL: "http://x", Err: context.Canceled}, false},
+		{"invalid url", parseErr, false},
+		{"unsupported scheme", &url.Error{Op: "Post", URL: "ftp://x", Err: errors.New("unsupported protocol scheme")}, false},
+		{"api error", errors.New("Invalid credentials"), false},
+		{"client error", goutil.HttpResponseError{Code: 400}, false},
+		{"server error", goutil.HttpResponseError{Code: 502}, true},
+		{"connection closed", &url.Error{Op: "Post", URL: "http://x", Err: io.EOF}, true},
+		{"network error", &url.Error{Op: "Post", URL: "http://x", Err: &net.OpError{Op: "read", Err: errors.New("connection reset")}}, true},
+	}
+
+	for _, test := range tests {
+		if got := isAmbiguousError(test.err); got != test.want {
+			t.Errorf("%s: expected %v, got %v", test.name, test.want, got)
+		}
+	}
+}
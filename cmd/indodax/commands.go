@@ -0,0 +1,661 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	indodax "github.com/vannleonheart/indodax-api-go"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func tickerCommand(args []string) error {
+	fs, opts := newFlagSet("ticker")
+
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+
+	if len(positional) != 1 {
+		return errors.New("usage: indodax ticker <pair>")
+	}
+
+	client, err := opts.client(false)
+	if err != nil {
+		return err
+	}
+
+	result, err := client.GetTicker(pairId(positional[0]))
+	if err != nil {
+		return err
+	}
+
+	return opts.print(result, fields(result.Ticker))
+}
+
+func depthCommand(args []string) error {
+	fs, opts := newFlagSet("depth")
+	limit := fs.Int("limit", 10, "number of levels per side")
+
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+
+	if len(positional) != 1 {
+		return errors.New("usage: indodax depth <pair> [--limit n]")
+	}
+
+	client, err := opts.client(false)
+	if err != nil {
+		return err
+	}
+
+	result, err := client.GetDepth(pairId(positional[0]))
+	if err != nil {
+		return err
+	}
+
+	t := table{headers: []string{"side", "price", "amount"}}
+
+	for i, level := range result.Sell {
+		if i >= *limit {
+			break
+		}
+
+		t.add(indodax.TradeTypeSell, level[0].String(), level[1].String())
+	}
+
+	for i, level := range result.Buy {
+		if i >= *limit {
+			break
+		}
+
+		t.add(indodax.TradeTypeBuy, level[0].String(), level[1].String())
+	}
+
+	return opts.print(result, &t)
+}
+
+func tradesCommand(args []string) error {
+	fs, opts := newFlagSet("trades")
+	limit := fs.Int("limit", 20, "number of trades")
+
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+
+	if len(positional) != 1 {
+		return errors.New("usage: indodax trades <pair> [--limit n]")
+	}
+
+	client, err := opts.client(false)
+	if err != nil {
+		return err
+	}
+
+	result, err := client.GetTrades(pairId(positional[0]))
+	if err != nil {
+		return err
+	}
+
+	t := table{headers: []string{"tid", "time", "type", "price", "amount"}}
+
+	for i, trade := range *result {
+		if i >= *limit {
+			break
+		}
+
+		t.add(trade.Tid, formatUnix(trade.Date), trade.Type, trade.Price.String(), trade.Amount.String())
+	}
+
+	return opts.print(result, &t)
+}
+
+func pairsCommand(args []string) error {
+	fs, opts := newFlagSet("pairs")
+
+	if _, err := parseArgs(fs, args); err != nil {
+		return err
+	}
+
+	client, err := opts.client(false)
+	if err != nil {
+		return err
+	}
+
+	result, err := client.GetPairs()
+	if err != nil {
+		return err
+	}
+
+	t := table{headers: []string{"ticker_id", "symbol", "min_traded", "min_base", "maker_fee", "taker_fee", "maintenance"}}
+
+	for _, pair := range *result {
+		t.add(
+			pair.TickerId,
+			pair.Symbol,
+			pair.TradeMinTradedCurrency.String(),
+			pair.TradeMinBaseCurrency.String(),
+			pair.TradeFeePercentMaker.String(),
+			pair.TradeFeePercentTaker.String(),
+			strconv.FormatBool(pair.IsMaintenance == 1),
+		)
+	}
+
+	return opts.print(result, &t)
+}
+
+func ohlcCommand(args []string) error {
+	fs, opts := newFlagSet("ohlc")
+	timeFrame := fs.String("tf", indodax.TimeFrame1Hour, "time frame: 1, 15, 30, 60, 240, 1D, 3D or 1W")
+	fromValue := fs.String("from", "", "start time, defaults to 24 hours ago")
+	toValue := fs.String("to", "", "end time, defaults to now")
+
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+
+	if len(positional) != 1 {
+		return errors.New("usage: indodax ohlc <pair> [--tf 60] [--from time] [--to time]")
+	}
+
+	to := time.Now()
+	from := to.Add(-24 * time.Hour)
+
+	if len(*fromValue) > 0 {
+		if from, err = parseTime(*fromValue); err != nil {
+			return err
+		}
+	}
+
+	if len(*toValue) > 0 {
+		if to, err = parseTime(*toValue); err != nil {
+			return err
+		}
+	}
+
+	client, err := opts.client(false)
+	if err != nil {
+		return err
+	}
+
+	result, err := client.GetOHLCHistory(strings.ToUpper(pairId(positional[0])), *timeFrame, from.Unix(), to.Unix())
+	if err != nil {
+		return err
+	}
+
+	t := table{headers: []string{"time", "open", "high", "low", "close", "volume"}}
+
+	for _, bar := range *result {
+		t.add(
+			time.Unix(bar.Time, 0).Format(time.RFC3339),
+			bar.Open.String(),
+			bar.High.String(),
+			bar.Low.String(),
+			bar.Close.String(),
+			bar.Volume,
+		)
+	}
+
+	return opts.print(result, &t)
+}
+
+func balanceCommand(args []string) error {
+	fs, opts := newFlagSet("balance")
+	all := fs.Bool("all", false, "include zero balances")
+
+	if _, err := parseArgs(fs, args); err != nil {
+		return err
+	}
+
+	client, err := opts.client(true)
+	if err != nil {
+		return err
+	}
+
+	result, err := client.GetInfo()
+	if err != nil {
+		return err
+	}
+
+	currencies := map[string]bool{}
+
+	for currency := range result.Balance {
+		currencies[currency] = true
+	}
+
+	for currency := range result.BalanceHold {
+		currencies[currency] = true
+	}
+
+	keys := make([]string, 0, len(currencies))
+
+	for currency := range currencies {
+		keys = append(keys, currency)
+	}
+
+	sort.Strings(keys)
+
+	t := table{headers: []string{"currency", "free", "held"}}
+
+	for _, currency := range keys {
+		free, held := result.Balance[currency], result.BalanceHold[currency]
+
+		if !*all && isZero(free) && isZero(held) {
+			continue
+		}
+
+		t.add(currency, numberText(free), numberText(held))
+	}
+
+	return opts.print(result, &t)
+}
+
+func ordersCommand(args []string) error {
+	fs, opts := newFlagSet("orders")
+	count := fs.Int("count", 100, "number of orders in history")
+
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+
+	if len(positional) == 0 {
+		return errors.New("usage: indodax orders open [pair] | indodax orders history <pair>")
+	}
+
+	client, err := opts.client(true)
+	if err != nil {
+		return err
+	}
+
+	t := table{headers: []string{"order_id", "client_order_id", "pair", "type", "price", "amount", "remain", "status", "submit_time"}}
+
+	switch positional[0] {
+	case "open":
+		if len(positional) > 1 {
+			pair := strings.ToLower(positional[1])
+
+			result, err := client.GetOpenOrders(&pair)
+			if err != nil {
+				return err
+			}
+
+			if orders, ok := result.(*indodax.GetPairOpenOrdersResponseBody); ok {
+				for _, order := range orders.Orders {
+					t.add(orderRow(pair, order)...)
+				}
+			}
+
+			return opts.print(result, &t)
+		}
+
+		result, err := client.GetOpenOrders(nil)
+		if err != nil {
+			return err
+		}
+
+		if orders, ok := result.(*indodax.GetOpenOrdersResponseBody); ok {
+			pairs := make([]string, 0, len(orders.Orders))
+
+			for pair := range orders.Orders {
+				pairs = append(pairs, pair)
+			}
+
+			sort.Strings(pairs)
+
+			for _, pair := range pairs {
+				for _, order := range orders.Orders[pair] {
+					t.add(orderRow(pair, order)...)
+				}
+			}
+		}
+
+		return opts.print(result, &t)
+	case "history":
+		if len(positional) != 2 {
+			return errors.New("usage: indodax orders history <pair> [--count n]")
+		}
+
+		pair := strings.ToLower(positional[1])
+
+		result, err := client.GetOrderHistory(pair, count, nil)
+		if err != nil {
+			return err
+		}
+
+		for _, order := range result.Orders {
+			t.add(orderRow(pair, order)...)
+		}
+
+		return opts.print(result, &t)
+	}
+
+	return fmt.Errorf("unknown orders command %s", positional[0])
+}
+
+func orderCommand(args []string) error {
+	if len(args) == 0 {
+		return errors.New("usage: indodax order get|place|cancel")
+	}
+
+	switch args[0] {
+	case "get":
+		return orderGetCommand(args[1:])
+	case "place":
+		return orderPlaceCommand(args[1:])
+	case "cancel":
+		return orderCancelCommand(args[1:])
+	}
+
+	return fmt.Errorf("unknown order command %s", args[0])
+}
+
+func orderGetCommand(args []string) error {
+	fs, opts := newFlagSet("order get")
+	clientOrderId := fs.String("client-order-id", "", "client order id")
+
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+
+	if len(*clientOrderId) == 0 && len(positional) != 2 {
+		return errors.New("usage: indodax order get <pair> <order id> | indodax order get --client-order-id id")
+	}
+
+	client, err := opts.client(true)
+	if err != nil {
+		return err
+	}
+
+	var result *indodax.GetOrderResponseBody
+
+	if len(*clientOrderId) > 0 {
+		result, err = client.GetOrderByClientOrderId(*clientOrderId)
+	} else {
+		result, err = client.GetOrder(strings.ToLower(positional[0]), positional[1])
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return opts.print(result, fields(result.Order))
+}
+
+func orderPlaceCommand(args []string) error {
+	fs, opts := newFlagSet("order place")
+	side := fs.String("side", "", "buy or sell")
+	pair := fs.String("pair", "", "pair, e.g. btc_idr")
+	orderType := fs.String("order-type", indodax.OrderTypeLimit, "limit or market")
+	price := fs.Float64("price", 0, "limit price")
+	amount := fs.Float64("amount", 0, "amount in coin, or in quote currency for market buy")
+	clientOrderId := fs.String("client-order-id", "", "client order id, generated when empty")
+	timeInForce := fs.String("tif", "", "time in force: GTC or MOC")
+	forceCoinAmount := fs.Bool("force-coin-amount", false, "market buy amount is in coin")
+
+	if _, err := parseArgs(fs, args); err != nil {
+		return err
+	}
+
+	if *side != indodax.TradeTypeBuy && *side != indodax.TradeTypeSell {
+		return errors.New("--side must be buy or sell")
+	}
+
+	if len(*pair) == 0 || *amount <= 0 {
+		return errors.New("--pair and --amount are required")
+	}
+
+	if *orderType != indodax.OrderTypeMarket && *price <= 0 {
+		return errors.New("--price is required for limit orders")
+	}
+
+	client, err := opts.client(true)
+	if err != nil {
+		return err
+	}
+
+	summary := fmt.Sprintf("%s %s %s %s", *orderType, *side, text(*amount), strings.ToLower(*pair))
+	if *orderType != indodax.OrderTypeMarket {
+		summary = fmt.Sprintf("%s at %s", summary, text(*price))
+	}
+
+	if err := opts.confirm(summary); err != nil {
+		return err
+	}
+
+	result, err := client.Trade(*side, strings.ToLower(*pair), *orderType, *price, *amount, optional(*timeInForce), optional(*clientOrderId), *forceCoinAmount)
+	if err != nil {
+		return err
+	}
+
+	return opts.print(result, fields(result.Return))
+}
+
+func orderCancelCommand(args []string) error {
+	fs, opts := newFlagSet("order cancel")
+	pair := fs.String("pair", "", "pair, e.g. btc_idr")
+	orderId := fs.String("id", "", "order id")
+	side := fs.String("side", "", "buy or sell")
+	orderType := fs.String("order-type", "", "order type of stop orders")
+	clientOrderId := fs.String("client-order-id", "", "client order id")
+
+	if _, err := parseArgs(fs, args); err != nil {
+		return err
+	}
+
+	if len(*clientOrderId) == 0 && (len(*pair) == 0 || len(*orderId) == 0 || len(*side) == 0) {
+		return errors.New("--pair, --id and --side, or --client-order-id are required")
+	}
+
+	client, err := opts.client(true)
+	if err != nil {
+		return err
+	}
+
+	summary := fmt.Sprintf("cancel order %s %s %s", *orderId, *side, *pair)
+	if len(*clientOrderId) > 0 {
+		summary = fmt.Sprintf("cancel order with client order id %s", *clientOrderId)
+	}
+
+	if err := opts.confirm(summary); err != nil {
+		return err
+	}
+
+	var result *map[string]interface{}
+
+	if len(*clientOrderId) > 0 {
+		result, err = client.CancelOrderByClientOrderId(*clientOrderId)
+	} else {
+		result, err = client.CancelOrder(strings.ToLower(*pair), *orderId, *side, optional(*orderType))
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return opts.print(result, fields(*result))
+}
+
+func withdrawCommand(args []string) error {
+	fs, opts := newFlagSet("withdraw")
+	currency := fs.String("currency", "", "currency, e.g. btc")
+	address := fs.String("address", "", "destination address")
+	network := fs.String("network", "", "network, e.g. btc or trc20")
+	amount := fs.String("amount", "", "amount to withdraw")
+	memo := fs.String("memo", "", "memo or destination tag")
+	requestId := fs.String("request-id", "", "request id, generated when empty")
+
+	if _, err := parseArgs(fs, args); err != nil {
+		return err
+	}
+
+	if len(*currency) == 0 || len(*address) == 0 || len(*network) == 0 || len(*amount) == 0 {
+		return errors.New("--currency, --address, --network and --amount are required")
+	}
+
+	if len(*requestId) == 0 {
+		*requestId = indodax.NewClientOrderId("")
+	}
+
+	client, err := opts.client(true)
+	if err != nil {
+		return err
+	}
+
+	summary := fmt.Sprintf("withdraw %s %s to %s on %s", *amount, strings.ToLower(*currency), *address, *network)
+	if len(*memo) > 0 {
+		summary = fmt.Sprintf("%s with memo %s", summary, *memo)
+	}
+
+	if err := opts.confirm(summary); err != nil {
+		return err
+	}
+
+	result, err := client.Withdraw(*requestId, strings.ToLower(*currency), *address, *network, *amount, *memo)
+	if err != nil {
+		return err
+	}
+
+	return opts.print(result, fields(result))
+}
+
+/*
+ * Build a field and value table from any json object
+ *
+ * @param interface{} v
+ *
+ * @return *table
+ */
+func fields(v interface{}) *table {
+	t := table{headers: []string{"field", "value"}}
+
+	content, err := json.Marshal(v)
+	if err != nil {
+		return &t
+	}
+
+	var values map[string]interface{}
+
+	decoder := json.NewDecoder(strings.NewReader(string(content)))
+	decoder.UseNumber()
+
+	if err := decoder.Decode(&values); err != nil {
+		return &t
+	}
+
+	keys := make([]string, 0, len(values))
+
+	for key := range values {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		value := values[key]
+
+		if _, nested := value.(map[string]interface{}); nested {
+			content, _ := json.Marshal(value)
+			value = string(content)
+		}
+
+		t.add(key, text(value))
+	}
+
+	return &t
+}
+
+func orderRow(pair string, order map[string]interface{}) []string {
+	coin := strings.Split(pair, "_")[0]
+
+	amount, remain := order["order_"+coin], order["remain_"+coin]
+
+	for key, value := range order {
+		if amount == nil && strings.HasPrefix(key, "order_") && key != "order_id" && key != "order_type" {
+			amount = value
+		}
+
+		if remain == nil && strings.HasPrefix(key, "remain_") {
+			remain = value
+		}
+	}
+
+	return []string{
+		text(order["order_id"]),
+		text(order["client_order_id"]),
+		pair,
+		text(order["type"]),
+		text(order["price"]),
+		text(amount),
+		text(remain),
+		text(order["status"]),
+		formatUnix(text(order["submit_time"])),
+	}
+}
+
+func pairId(pair string) string {
+	return strings.ReplaceAll(strings.ToLower(pair), "_", "")
+}
+
+func optional(value string) *string {
+	if len(value) == 0 {
+		return nil
+	}
+
+	return &value
+}
+
+/*
+ * Parse unix seconds, RFC3339 or a date
+ *
+ * @param string value
+ *
+ * @return time.Time
+ * @return error
+ */
+func parseTime(value string) (time.Time, error) {
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Unix(seconds, 0), nil
+	}
+
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+
+	if t, err := time.ParseInLocation(indodax.TransactionHistoryDateLayout, value, time.Local); err == nil {
+		return t, nil
+	}
+
+	return time.Time{}, fmt.Errorf("invalid time %s", value)
+}
+
+func formatUnix(value string) string {
+	seconds, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || seconds <= 0 {
+		return value
+	}
+
+	return time.Unix(seconds, 0).Format(time.RFC3339)
+}
+
+func isZero(value json.Number) bool {
+	f, err := value.Float64()
+
+	return err != nil || f == 0
+}
+
+func numberText(value json.Number) string {
+	if len(value) == 0 {
+		return "0"
+	}
+
+	return value.String()
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	indodax "github.com/vannleonheart/indodax-api-go"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	defaultBaseUrl        = "https://indodax.com"
+	keystorePassphraseEnv = "INDODAX_KEYSTORE_PASSPHRASE"
+)
+
+type fileConfig struct {
+	indodax.Config
+	TradeApiKey    string `json:"trade_api_key"`
+	TradeApiSecret string `json:"trade_api_secret"`
+	CredentialFile string `json:"credential_file"`
+	KeystoreFile   string `json:"keystore_file"`
+}
+
+type options struct {
+	config string
+	output string
+	yes    bool
+}
+
+/*
+ * Create a flag set with the flags shared by every command
+ *
+ * @param string name
+ *
+ * @return *flag.FlagSet
+ * @return *options
+ */
+func newFlagSet(name string) (*flag.FlagSet, *options) {
+	opts := options{}
+
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.StringVar(&opts.config, "config", "", "config file")
+	fs.StringVar(&opts.output, "output", outputTable, "output format: table, json or csv")
+	fs.BoolVar(&opts.yes, "yes", false, "skip confirmation")
+
+	return fs, &opts
+}
+
+/*
+ * Parse flags that may appear before, between or after positional arguments
+ *
+ * @param *flag.FlagSet fs
+ * @param []string args
+ *
+ * @return []string
+ * @return error
+ */
+func parseArgs(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+func (o *options) client(private bool) (*indodax.Client, error) {
+	config := fileConfig{}
+
+	path := o.config
+	if len(path) == 0 {
+		path = os.Getenv("INDODAX_CONFIG")
+	}
+
+	explicit := len(path) > 0
+
+	if !explicit {
+		if dir, err := os.UserConfigDir(); err == nil {
+			path = filepath.Join(dir, "indodax", "config.json")
+		}
+	}
+
+	if len(path) > 0 {
+		content, err := os.ReadFile(path)
+
+		switch {
+		case err == nil:
+			if err := json.Unmarshal(content, &config); err != nil {
+				return nil, fmt.Errorf("invalid config file %s: %w", path, err)
+			}
+		case explicit || !errors.Is(err, os.ErrNotExist):
+			return nil, err
+		}
+	}
+
+	if len(config.PublicApiBaseUrl) == 0 {
+		config.PublicApiBaseUrl = defaultBaseUrl
+	}
+
+	if len(config.PrivateApiBaseUrl) == 0 {
+		config.PrivateApiBaseUrl = defaultBaseUrl
+	}
+
+	client := indodax.New(config.Config)
+
+	if private {
+		credential, err := config.credentials().Credential()
+		if errors.Is(err, indodax.ErrCredentialNotFound) {
+			return nil, fmt.Errorf("credential is required, set %s and %s or add them to the config file: %w", indodax.DefaultApiKeyEnv, indodax.DefaultApiSecretEnv, err)
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		client = client.WithCredential(credential.TradeApiKey, credential.TradeApiSecret)
+	}
+
+	return client, nil
+}
+
+/*
+ * Chain the credential sources, the environment comes first, then the keys of the
+ * config file, the credential file and the keystore file it points to
+ *
+ * @return indodax.CredentialProvider
+ */
+func (c fileConfig) credentials() indodax.CredentialProvider {
+	providers := []indodax.CredentialProvider{
+		indodax.NewEnvCredentialProvider(indodax.DefaultApiKeyEnv, indodax.DefaultApiSecretEnv),
+		indodax.CredentialProviderFunc(func() (*indodax.Credential, error) {
+			if len(c.TradeApiKey) == 0 || len(c.TradeApiSecret) == 0 {
+				return nil, fmt.Errorf("%w: config file has no trade_api_key or trade_api_secret", indodax.ErrCredentialNotFound)
+			}
+
+			return &indodax.Credential{TradeApiKey: c.TradeApiKey, TradeApiSecret: c.TradeApiSecret}, nil
+		}),
+	}
+
+	if len(c.CredentialFile) > 0 {
+		providers = append(providers, indodax.NewJsonCredentialProvider(c.CredentialFile))
+	}
+
+	if len(c.KeystoreFile) > 0 {
+		providers = append(providers, indodax.NewKeystoreCredentialProvider(c.KeystoreFile, []byte(os.Getenv(keystorePassphraseEnv))))
+	}
+
+	return indodax.NewCredentialChain(providers...)
+}
+
+/*
+ * Ask the user to confirm an action unless --yes is given
+ *
+ * @param string summary
+ *
+ * @return error
+ */
+func (o *options) confirm(summary string) error {
+	if o.yes {
+		return nil
+	}
+
+	return confirm(os.Stdin, os.Stderr, summary)
+}
+
+func confirm(in io.Reader, out io.Writer, summary string) error {
+	fmt.Fprintf(out, "%s\nproceed? [y/N] ", summary)
+
+	answer, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return nil
+	}
+
+	return errors.New("aborted")
+}
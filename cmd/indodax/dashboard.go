@@ -0,0 +1,589 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	indodax "github.com/vannleonheart/indodax-api-go"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const dashboardHelp = "b <price> <amount> buy | s <price> <amount> sell | mb <quote amount> market buy | ms <amount> market sell | c <#> cancel | ca cancel all | p <pair> | r refresh | q quit"
+
+type account interface {
+	indodax.TradingAPI
+	indodax.WalletAPI
+}
+
+type dashboardAction struct {
+	summary string
+	strict  bool
+	run     func() (string, error)
+}
+
+type dashboard struct {
+	market       indodax.PublicAPI
+	account      account
+	paper        *indodax.PaperTrader
+	pair         string
+	levels       int
+	maxNotional  float64
+	maxDeviation float64
+	depth        *indodax.GetDepthResponseBody
+	trades       []indodax.Trade
+	balances     *indodax.GetInfoResponseBody
+	orders       []map[string]interface{}
+	updatedAt    time.Time
+	status       string
+	pending      *dashboardAction
+}
+
+func dashboardCommand(args []string) error {
+	fs, opts := newFlagSet("dashboard")
+	refresh := fs.Duration("refresh", 2*time.Second, "refresh interval")
+	levels := fs.Int("levels", 10, "order book levels per side")
+	maxNotional := fs.Float64("max-notional", 10000000, "orders above this value in quote currency need a strict confirmation, 0 disables")
+	maxDeviation := fs.Float64("max-deviation", 0.05, "limit prices further than this fraction from mid price need a strict confirmation, 0 disables")
+	paper := fs.Bool("paper", false, "trade against a paper account instead of the real one")
+	paperBalance := fs.String("paper-balance", "idr=10000000", "initial paper balances, e.g. idr=10000000,btc=0.1")
+
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+
+	if len(positional) != 1 {
+		return errors.New("usage: indodax dashboard <pair> [--paper]")
+	}
+
+	d := &dashboard{
+		pair:         strings.ToLower(positional[0]),
+		levels:       *levels,
+		maxNotional:  *maxNotional,
+		maxDeviation: *maxDeviation,
+	}
+
+	if *paper {
+		client, err := opts.client(false)
+		if err != nil {
+			return err
+		}
+
+		balances, err := parseBalances(*paperBalance)
+		if err != nil {
+			return err
+		}
+
+		if d.paper, err = indodax.NewPaperTrader(client, balances); err != nil {
+			return err
+		}
+
+		d.market, d.account = client, d.paper
+	} else {
+		client, err := opts.client(true)
+		if err != nil {
+			return err
+		}
+
+		d.market, d.account = client, client
+	}
+
+	return d.run(os.Stdin, os.Stdout, *refresh)
+}
+
+/*
+ * Redraw the dashboard on every refresh and handle one command per input line
+ *
+ * @param io.Reader in
+ * @param io.Writer out
+ * @param time.Duration refresh
+ *
+ * @return error
+ */
+func (d *dashboard) run(in io.Reader, out io.Writer, refresh time.Duration) error {
+	lines := make(chan string)
+
+	go func() {
+		scanner := bufio.NewScanner(in)
+
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+
+		close(lines)
+	}()
+
+	ticker := time.NewTicker(refresh)
+	defer ticker.Stop()
+
+	d.refresh()
+	d.render(out)
+
+	for {
+		select {
+		case line, ok := <-lines:
+			if !ok {
+				return nil
+			}
+
+			if quit := d.handle(strings.TrimSpace(line)); quit {
+				return nil
+			}
+
+			d.refresh()
+		case <-ticker.C:
+			d.refresh()
+		}
+
+		d.render(out)
+	}
+}
+
+func (d *dashboard) refresh() {
+	var errs []string
+
+	if d.paper != nil {
+		if err := d.paper.Match(); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+
+	if depth, err := d.market.GetDepth(pairId(d.pair)); err == nil {
+		d.depth = depth
+	} else {
+		errs = append(errs, err.Error())
+	}
+
+	if trades, err := d.market.GetTrades(pairId(d.pair)); err == nil {
+		d.trades = *trades
+	} else {
+		errs = append(errs, err.Error())
+	}
+
+	if info, err := d.account.GetInfo(); err == nil {
+		d.balances = info
+	} else {
+		errs = append(errs, err.Error())
+	}
+
+	pair := d.pair
+
+	if result, err := d.account.GetOpenOrders(&pair); err == nil {
+		if open, ok := result.(*indodax.GetPairOpenOrdersResponseBody); ok {
+			d.orders = open.Orders
+
+			sort.SliceStable(d.orders, func(i, j int) bool {
+				a, b := text(d.orders[i]["order_id"]), text(d.orders[j]["order_id"])
+				if len(a) != len(b) {
+					return len(a) < len(b)
+				}
+
+				return a < b
+			})
+		}
+	} else {
+		errs = append(errs, err.Error())
+	}
+
+	d.updatedAt = time.Now()
+
+	if len(errs) > 0 {
+		d.status = fmt.Sprintf("refresh failed: %s", strings.Join(errs, "; "))
+	} else if strings.HasPrefix(d.status, "refresh failed") {
+		d.status = ""
+	}
+}
+
+/*
+ * Handle a command line, orders are never sent before they are confirmed
+ *
+ * @param string line
+ *
+ * @return bool
+ */
+func (d *dashboard) handle(line string) bool {
+	if d.pending != nil {
+		action := d.pending
+		d.pending = nil
+
+		answer := strings.ToLower(line)
+
+		if (action.strict && answer != "yes") || (!action.strict && answer != "y" && answer != "yes") {
+			d.status = "cancelled"
+			return false
+		}
+
+		message, err := action.run()
+		if err != nil {
+			d.status = fmt.Sprintf("error: %s", err)
+		} else {
+			d.status = message
+		}
+
+		return false
+	}
+
+	parts := strings.Fields(line)
+	if len(parts) == 0 {
+		return false
+	}
+
+	var err error
+
+	switch parts[0] {
+	case "q", "quit":
+		return true
+	case "r":
+		d.status = ""
+	case "p":
+		if len(parts) != 2 {
+			err = errors.New("usage: p <pair>")
+			break
+		}
+
+		d.pair = strings.ToLower(parts[1])
+		d.status = fmt.Sprintf("switched to %s", d.pair)
+	case "b", "s":
+		err = d.limitOrder(parts)
+	case "mb", "ms":
+		err = d.marketOrder(parts)
+	case "c":
+		err = d.cancelOrder(parts)
+	case "ca":
+		err = d.cancelAll()
+	default:
+		err = fmt.Errorf("unknown command %s", parts[0])
+	}
+
+	if err != nil {
+		d.status = fmt.Sprintf("error: %s", err)
+	}
+
+	return false
+}
+
+func (d *dashboard) limitOrder(parts []string) error {
+	if len(parts) != 3 {
+		return fmt.Errorf("usage: %s <price> <amount>", parts[0])
+	}
+
+	price, err := strconv.ParseFloat(parts[1], 64)
+	if err != nil || price <= 0 {
+		return errors.New("invalid price")
+	}
+
+	amount, err := strconv.ParseFloat(parts[2], 64)
+	if err != nil || amount <= 0 {
+		return errors.New("invalid amount")
+	}
+
+	side := indodax.TradeTypeBuy
+	if parts[0] == "s" {
+		side = indodax.TradeTypeSell
+	}
+
+	var warnings []string
+
+	if notional := price * amount; d.maxNotional > 0 && notional > d.maxNotional {
+		warnings = append(warnings, fmt.Sprintf("value %s exceeds %s", text(notional), text(d.maxNotional)))
+	}
+
+	if mid := d.mid(); mid > 0 && d.maxDeviation > 0 && math.Abs(price-mid)/mid > d.maxDeviation {
+		warnings = append(warnings, fmt.Sprintf("price is %.2f%% away from mid %s", math.Abs(price-mid)/mid*100, text(mid)))
+	}
+
+	pair := d.pair
+
+	d.confirm(fmt.Sprintf("limit %s %s %s at %s", side, text(amount), pair, text(price)), warnings, func() (string, error) {
+		resp, err := d.account.Trade(side, pair, indodax.OrderTypeLimit, price, amount, nil, nil, false)
+		if err != nil {
+			return "", err
+		}
+
+		return placedMessage(resp), nil
+	})
+
+	return nil
+}
+
+func (d *dashboard) marketOrder(parts []string) error {
+	if len(parts) != 2 {
+		return fmt.Errorf("usage: %s <amount>", parts[0])
+	}
+
+	amount, err := strconv.ParseFloat(parts[1], 64)
+	if err != nil || amount <= 0 {
+		return errors.New("invalid amount")
+	}
+
+	side, notional := indodax.TradeTypeBuy, amount
+	if parts[0] == "ms" {
+		side, notional = indodax.TradeTypeSell, amount*d.bestBid()
+	}
+
+	var warnings []string
+
+	if d.maxNotional > 0 && notional > d.maxNotional {
+		warnings = append(warnings, fmt.Sprintf("value %s exceeds %s", text(notional), text(d.maxNotional)))
+	}
+
+	pair := d.pair
+
+	summary := fmt.Sprintf("market sell %s %s", text(amount), pair)
+	if side == indodax.TradeTypeBuy {
+		summary = fmt.Sprintf("market buy %s for %s in quote currency", pair, text(amount))
+	}
+
+	d.confirm(summary, warnings, func() (string, error) {
+		resp, err := d.account.Trade(side, pair, indodax.OrderTypeMarket, 0, amount, nil, nil, false)
+		if err != nil {
+			return "", err
+		}
+
+		return placedMessage(resp), nil
+	})
+
+	return nil
+}
+
+func (d *dashboard) cancelOrder(parts []string) error {
+	if len(parts) != 2 {
+		return errors.New("usage: c <#>")
+	}
+
+	index, err := strconv.Atoi(parts[1])
+	if err != nil || index < 1 || index > len(d.orders) {
+		return errors.New("invalid order number")
+	}
+
+	order := d.orders[index-1]
+	orderId, side, pair := text(order["order_id"]), text(order["type"]), d.pair
+
+	d.confirm(fmt.Sprintf("cancel %s order %s at %s", side, orderId, text(order["price"])), nil, func() (string, error) {
+		if _, err := d.account.CancelOrder(pair, orderId, side, nil); err != nil {
+			return "", err
+		}
+
+		return fmt.Sprintf("cancelled order %s", orderId), nil
+	})
+
+	return nil
+}
+
+func (d *dashboard) cancelAll() error {
+	if len(d.orders) == 0 {
+		return errors.New("no open orders")
+	}
+
+	orders, pair := d.orders, d.pair
+
+	d.confirm(fmt.Sprintf("cancel all %d open orders of %s", len(orders), pair), nil, func() (string, error) {
+		cancelled := 0
+
+		for _, order := range orders {
+			if _, err := d.account.CancelOrder(pair, text(order["order_id"]), text(order["type"]), nil); err != nil {
+				return "", fmt.Errorf("cancelled %d orders, then %w", cancelled, err)
+			}
+
+			cancelled++
+		}
+
+		return fmt.Sprintf("cancelled %d orders", cancelled), nil
+	})
+
+	return nil
+}
+
+/*
+ * Ask for confirmation on the next input line, orders with warnings require typing yes
+ *
+ * @param string summary
+ * @param []string warnings
+ * @param func() (string, error) run
+ *
+ * @return void
+ */
+func (d *dashboard) confirm(summary string, warnings []string, run func() (string, error)) {
+	action := dashboardAction{summary: summary, run: run}
+
+	if len(warnings) > 0 {
+		action.strict = true
+		action.summary = fmt.Sprintf("WARNING %s: %s", strings.Join(warnings, ", "), summary)
+	}
+
+	d.pending = &action
+}
+
+func (d *dashboard) render(w io.Writer) {
+	var b strings.Builder
+
+	b.WriteString("\033[H\033[2J")
+
+	mode := "live"
+	if d.paper != nil {
+		mode = "paper"
+	}
+
+	fmt.Fprintf(&b, "INDODAX %s  mid %s  %s  [%s]\n\n", strings.ToUpper(d.pair), text(d.mid()), d.updatedAt.Format("15:04:05"), mode)
+
+	book := d.bookLines()
+	trades := d.tradeLines()
+
+	for i := 0; i < len(book) || i < len(trades); i++ {
+		var left, right string
+
+		if i < len(book) {
+			left = book[i]
+		}
+
+		if i < len(trades) {
+			right = trades[i]
+		}
+
+		fmt.Fprintf(&b, "%-44s%s\n", left, right)
+	}
+
+	b.WriteString("\nBALANCES\n")
+
+	coin, quote, _ := strings.Cut(d.pair, "_")
+
+	if d.balances != nil {
+		for _, currency := range []string{coin, quote} {
+			fmt.Fprintf(&b, "  %-6s free %-20s held %s\n", currency, numberText(d.balances.Balance[currency]), numberText(d.balances.BalanceHold[currency]))
+		}
+	}
+
+	b.WriteString("\nOPEN ORDERS\n")
+	fmt.Fprintf(&b, "  %-3s %-12s %-5s %-16s %-14s %s\n", "#", "ORDER ID", "SIDE", "PRICE", "AMOUNT", "REMAIN")
+
+	for i, order := range d.orders {
+		row := orderRow(d.pair, order)
+		fmt.Fprintf(&b, "  %-3d %-12s %-5s %-16s %-14s %s\n", i+1, row[0], row[3], row[4], row[5], row[6])
+	}
+
+	fmt.Fprintf(&b, "\n%s\n", dashboardHelp)
+
+	if len(d.status) > 0 {
+		fmt.Fprintf(&b, "%s\n", d.status)
+	}
+
+	if d.pending != nil {
+		answer := "[y/N]"
+		if d.pending.strict {
+			answer = "type yes to confirm"
+		}
+
+		fmt.Fprintf(&b, "%s, %s ", d.pending.summary, answer)
+	} else {
+		b.WriteString("> ")
+	}
+
+	_, _ = io.WriteString(w, b.String())
+}
+
+func (d *dashboard) bookLines() []string {
+	lines := []string{fmt.Sprintf("%-6s %-20s %s", "BOOK", "PRICE", "AMOUNT")}
+
+	if d.depth == nil {
+		return lines
+	}
+
+	asks := d.depth.Sell
+	if len(asks) > d.levels {
+		asks = asks[:d.levels]
+	}
+
+	for i := len(asks) - 1; i >= 0; i-- {
+		lines = append(lines, fmt.Sprintf("%-6s %-20s %s", "ask", asks[i][0].String(), asks[i][1].String()))
+	}
+
+	bids := d.depth.Buy
+	if len(bids) > d.levels {
+		bids = bids[:d.levels]
+	}
+
+	for _, level := range bids {
+		lines = append(lines, fmt.Sprintf("%-6s %-20s %s", "bid", level[0].String(), level[1].String()))
+	}
+
+	return lines
+}
+
+func (d *dashboard) tradeLines() []string {
+	lines := []string{fmt.Sprintf("%-10s %-5s %-20s %s", "TRADES", "SIDE", "PRICE", "AMOUNT")}
+
+	for i, trade := range d.trades {
+		if i >= d.levels*2 {
+			break
+		}
+
+		when := trade.Date
+		if seconds, err := strconv.ParseInt(trade.Date, 10, 64); err == nil {
+			when = time.Unix(seconds, 0).Format("15:04:05")
+		}
+
+		lines = append(lines, fmt.Sprintf("%-10s %-5s %-20s %s", when, trade.Type, trade.Price.String(), trade.Amount.String()))
+	}
+
+	return lines
+}
+
+func (d *dashboard) bestBid() float64 {
+	if d.depth == nil || len(d.depth.Buy) == 0 {
+		return 0
+	}
+
+	price, _ := d.depth.Buy[0][0].Float64()
+
+	return price
+}
+
+func (d *dashboard) mid() float64 {
+	if d.depth == nil || len(d.depth.Buy) == 0 || len(d.depth.Sell) == 0 {
+		return 0
+	}
+
+	ask, _ := d.depth.Sell[0][0].Float64()
+
+	return (ask + d.bestBid()) / 2
+}
+
+func placedMessage(resp *indodax.ResponseBody) string {
+	ret, _ := resp.Return.(map[string]interface{})
+
+	return fmt.Sprintf("placed order %s", text(ret["order_id"]))
+}
+
+/*
+ * Parse balances in the form currency=amount separated by commas
+ *
+ * @param string value
+ *
+ * @return map[string]float64
+ * @return error
+ */
+func parseBalances(value string) (map[string]float64, error) {
+	balances := map[string]float64{}
+
+	for _, item := range strings.Split(value, ",") {
+		currency, amount, found := strings.Cut(strings.TrimSpace(item), "=")
+		if !found {
+			return nil, fmt.Errorf("invalid balance %s", item)
+		}
+
+		f, err := strconv.ParseFloat(amount, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid balance %s", item)
+		}
+
+		balances[strings.ToLower(currency)] = f
+	}
+
+	return balances, nil
+}
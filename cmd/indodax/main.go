@@ -0,0 +1,88 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+)
+
+const usage = `usage: indodax <command> [arguments] [flags]
+
+market data:
+  ticker <pair>                        show ticker of a pair
+  depth <pair> [--limit n]             show order book of a pair
+  trades <pair> [--limit n]            show recent trades of a pair
+  pairs                                list tradable pairs
+  ohlc <pair> [--tf 60] [--from] [--to]
+                                       show candles, from and to accept unix seconds, RFC3339 or 2006-01-02
+
+account:
+  balance [--all]                      show free and held balances
+  orders open [pair]                   list open orders
+  orders history <pair> [--count n]    list order history of a pair
+  order get <pair> <order id>          show an order
+  order place --side buy --pair btc_idr --price 1000 --amount 0.1 [--order-type limit]
+  order cancel --pair btc_idr --id 123 --side buy
+  order cancel --client-order-id abc
+  withdraw --currency btc --address x --network btc --amount 0.1 [--memo] [--request-id]
+
+dashboard:
+  dashboard <pair> [--paper] [--refresh 2s] [--max-notional n] [--max-deviation 0.05]
+                                       live order book, trades, balances and open orders with
+                                       shortcuts to place and cancel orders
+
+flags available on every command:
+  --config path                        config file, defaults to $INDODAX_CONFIG or ~/.config/indodax/config.json
+  --output table|json|csv              output format, defaults to table
+  --yes                                skip confirmation of trading and withdrawal commands
+
+credentials are read from INDODAX_API_KEY and INDODAX_API_SECRET, then from
+trade_api_key and trade_api_secret of the config file, then from the json file
+in credential_file and the keystore in keystore_file, which is opened with
+INDODAX_KEYSTORE_PASSPHRASE
+`
+
+type command func(args []string) error
+
+var commands = map[string]command{
+	"ticker":    tickerCommand,
+	"depth":     depthCommand,
+	"trades":    tradesCommand,
+	"pairs":     pairsCommand,
+	"ohlc":      ohlcCommand,
+	"balance":   balanceCommand,
+	"orders":    ordersCommand,
+	"order":     orderCommand,
+	"withdraw":  withdrawCommand,
+	"dashboard": dashboardCommand,
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	name := os.Args[1]
+
+	if name == "help" || name == "-h" || name == "--help" {
+		fmt.Print(usage)
+		return
+	}
+
+	cmd, exist := commands[name]
+	if !exist {
+		fmt.Fprintf(os.Stderr, "unknown command %s\n\n%s", name, usage)
+		os.Exit(2)
+	}
+
+	if err := cmd(os.Args[2:]); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return
+		}
+
+		fmt.Fprintf(os.Stderr, "error: %s\n", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+)
+
+const (
+	outputTable = "table"
+	outputJson  = "json"
+	outputCsv   = "csv"
+)
+
+type table struct {
+	headers []string
+	rows    [][]string
+}
+
+func (t *table) add(values ...string) {
+	t.rows = append(t.rows, values)
+}
+
+/*
+ * Print a result, json output prints the raw api result while table and csv print the rows
+ *
+ * @param interface{} raw
+ * @param *table t
+ *
+ * @return error
+ */
+func (o *options) print(raw interface{}, t *table) error {
+	return write(os.Stdout, o.output, raw, t)
+}
+
+func write(w io.Writer, format string, raw interface{}, t *table) error {
+	switch format {
+	case outputJson:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+
+		return encoder.Encode(raw)
+	case outputCsv:
+		cw := csv.NewWriter(w)
+
+		if err := cw.Write(t.headers); err != nil {
+			return err
+		}
+
+		if err := cw.WriteAll(t.rows); err != nil {
+			return err
+		}
+
+		return cw.Error()
+	case outputTable, "":
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+		fmt.Fprintln(tw, strings.ToUpper(strings.Join(t.headers, "\t")))
+
+		for _, row := range t.rows {
+			fmt.Fprintln(tw, strings.Join(row, "\t"))
+		}
+
+		return tw.Flush()
+	}
+
+	return fmt.Errorf("invalid output format %s", format)
+}
+
+func text(v interface{}) string {
+	switch value := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return value
+	case json.Number:
+		return value.String()
+	case float64:
+		return strings.TrimRight(strings.TrimRight(fmt.Sprintf("%.8f", value), "0"), ".")
+	}
+
+	return fmt.Sprintf("%v", v)
+}
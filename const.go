@@ -1,5 +1,7 @@
 package indodax
 
+import "time"
+
 const (
 	MethodGetInfo                    = "getInfo"
 	MethodGetTransactionHistory      = "transHistory"
@@ -35,4 +37,124 @@ const (
 	TimeFrame1Day      = "1D"
 	TimeFrame3Days     = "3D"
 	TimeFrame1Week     = "1W"
+
+	TransactionHistoryDateLayout = "2006-01-02"
+
+	WithdrawalStatusRequested = "requested"
+	WithdrawalStatusPending   = "pending"
+	WithdrawalStatusSuccess   = "success"
+	WithdrawalStatusCancelled = "cancelled"
+	WithdrawalStatusExpired   = "expired"
+
+	WithdrawalEventTracked       = "tracked"
+	WithdrawalEventStatusChanged = "status_changed"
+	WithdrawalEventStuck         = "stuck"
+	WithdrawalEventExpired       = "expired"
+
+	DefaultWithdrawalPollInterval = 30 * time.Second
+	DefaultWithdrawalStuckAfter   = 2 * time.Hour
+	DefaultWithdrawalRetention    = 30 * 24 * time.Hour
+	DefaultWithdrawalExpiryMargin = 7 * 24 * time.Hour
+
+	DepositStatusSuccess = "success"
+
+	DepositEventNew           = "new"
+	DepositEventStatusChanged = "status_changed"
+
+	DefaultDepositPollInterval = 30 * time.Second
+	DefaultDepositOverlap      = 24 * time.Hour
+
+	BalanceSourceTrade      = "trade"
+	BalanceSourceDeposit    = "deposit"
+	BalanceSourceWithdrawal = "withdrawal"
+	BalanceSourceExpected   = "expected"
+
+	DefaultBalancePollInterval = time.Minute
+
+	PriceSourceLast = "last"
+	PriceSourceBid  = "bid"
+	PriceSourceAsk  = "ask"
+	PriceSourceMid  = "mid"
+
+	CurrencyIdr  = "idr"
+	CurrencyUsdt = "usdt"
+	CurrencyBtc  = "btc"
+
+	LotMethodFifo    = "fifo"
+	LotMethodLifo    = "lifo"
+	LotMethodAverage = "average"
+
+	PeriodDaily   = "daily"
+	PeriodMonthly = "monthly"
+	PeriodYearly  = "yearly"
+
+	TaxCategoryPurchase   = "purchase"
+	TaxCategorySale       = "sale"
+	TaxCategoryDeposit    = "deposit"
+	TaxCategoryWithdrawal = "withdrawal"
+
+	DefaultPPhRate = 0.001
+	DefaultPPNRate = 0.0011
+	DefaultTaxZone = "Asia/Jakarta"
+
+	TransactionHistoryMaxRange = 7 * 24 * time.Hour
+
+	LedgerEntryTrade      = "trade"
+	LedgerEntryFee        = "fee"
+	LedgerEntryOrder      = "order"
+	LedgerEntryDeposit    = "deposit"
+	LedgerEntryWithdrawal = "withdrawal"
+
+	OrderStatusOpen      = "open"
+	OrderStatusFilled    = "filled"
+	OrderStatusCancelled = "cancelled"
+
+	ErrorCodeOrderNotFound   = "order_not_found"
+	ErrorCodeTooManyRequests = "too_many_requests"
+
+	StrategyModeLive     = "live"
+	StrategyModePaper    = "paper"
+	StrategyModeBacktest = "backtest"
+
+	DefaultStrategyPollInterval = 5 * time.Second
+
+	OrderStatePending         = "pending"
+	OrderStateOpen            = "open"
+	OrderStatePartiallyFilled = "partially_filled"
+	OrderStateFilled          = "filled"
+	OrderStateCancelled       = "cancelled"
+	OrderStateRejected        = "rejected"
+
+	DefaultOrderReconcileInterval = 10 * time.Second
+	DefaultOrderPendingTimeout    = time.Minute
+	DefaultOrderHistoryCount      = 100
+
+	DefaultTradeRetries         = 1
+	DefaultTradeResolveAttempts = 3
+	DefaultTradeResolveDelay    = time.Second
+	DefaultRateLimitBackoff     = time.Second
+
+	ApiPublic               = "public"
+	ApiPrivate              = "private"
+	DefaultMetricsNamespace = "indodax"
+
+	CircuitStateClosed   = "closed"
+	CircuitStateOpen     = "open"
+	CircuitStateHalfOpen = "half_open"
+
+	CircuitGroupMarket   = "market"
+	CircuitGroupTrade    = "trade"
+	CircuitGroupWithdraw = "withdraw"
+	CircuitGroupAccount  = "account"
+
+	DefaultCircuitFailureThreshold = 5
+	DefaultCircuitOpenTimeout      = 30 * time.Second
+	DefaultCircuitHalfOpenProbes   = 1
+	DefaultPairStatusInterval      = time.Minute
+
+	DefaultSignerTimeout = 5 * time.Second
+
+	DefaultApiKeyEnv          = "INDODAX_API_KEY"
+	DefaultApiSecretEnv       = "INDODAX_API_SECRET"
+	DefaultKeystoreIterations = 600000
 )
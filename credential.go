@@ -0,0 +1,459 @@
+package indodax
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+var ErrCredentialNotFound = errors.New("credential not found")
+
+/*
+ * CredentialProvider supplies the trade api credential, providers return ErrCredentialNotFound
+ * when they are not configured so a chain can move on to the next provider
+ */
+type CredentialProvider interface {
+	Credential() (*Credential, error)
+}
+
+type CredentialProviderFunc func() (*Credential, error)
+
+func (f CredentialProviderFunc) Credential() (*Credential, error) {
+	return f()
+}
+
+/*
+ * Derive a client using a credential provider, providers other than a CredentialRotator
+ * are wrapped in a rotator loading them once
+ *
+ * @param CredentialProvider provider
+ *
+ * @return *Client
+ */
+func (c *Client) WithCredentialProvider(provider CredentialProvider) *Client {
+	if _, ok := provider.(*CredentialRotator); !ok {
+		provider = NewCredentialRotator(provider, 0)
+	}
+
+	derived := c.withoutCredential()
+	derived.CredentialProvider = provider
+
+	return derived
+}
+
+/*
+ * Read the credential from environment variables, INDODAX_API_KEY and INDODAX_API_SECRET
+ * are used when the names are empty
+ *
+ * @param string keyVar
+ * @param string secretVar
+ *
+ * @return CredentialProvider
+ */
+func NewEnvCredentialProvider(keyVar, secretVar string) CredentialProvider {
+	if len(keyVar) == 0 {
+		keyVar = DefaultApiKeyEnv
+	}
+
+	if len(secretVar) == 0 {
+		secretVar = DefaultApiSecretEnv
+	}
+
+	return CredentialProviderFunc(func() (*Credential, error) {
+		key, secret := os.Getenv(keyVar), os.Getenv(secretVar)
+
+		if len(key) == 0 || len(secret) == 0 {
+			return nil, fmt.Errorf("%w: %s or %s is not set", ErrCredentialNotFound, keyVar, secretVar)
+		}
+
+		return &Credential{TradeApiKey: key, TradeApiSecret: secret}, nil
+	})
+}
+
+/*
+ * Read the credential from a json file holding trade_api_key and trade_api_secret
+ *
+ * @param string path
+ *
+ * @return CredentialProvider
+ */
+func NewJsonCredentialProvider(path string) CredentialProvider {
+	return CredentialProviderFunc(func() (*Credential, error) {
+		data, err := readCredentialFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		var credential Credential
+
+		if err = json.Unmarshal(data, &credential); err != nil {
+			return nil, fmt.Errorf("invalid credential file %s: %w", path, err)
+		}
+
+		return checkCredential(&credential)
+	})
+}
+
+/*
+ * Read the credential from a keystore file written by WriteCredentialKeystore
+ *
+ * @param string path
+ * @param []byte passphrase
+ *
+ * @return CredentialProvider
+ */
+func NewKeystoreCredentialProvider(path string, passphrase []byte) CredentialProvider {
+	return CredentialProviderFunc(func() (*Credential, error) {
+		data, err := readCredentialFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		return DecryptCredential(data, passphrase)
+	})
+}
+
+/*
+ * Use the credential of the first configured provider, errors other than
+ * ErrCredentialNotFound stop the chain
+ *
+ * @param ...CredentialProvider providers
+ *
+ * @return CredentialProvider
+ */
+func NewCredentialChain(providers ...CredentialProvider) CredentialProvider {
+	return CredentialProviderFunc(func() (*Credential, error) {
+		var errs []error
+
+		for _, provider := range providers {
+			credential, err := provider.Credential()
+			if err == nil {
+				return credential, nil
+			}
+
+			if !errors.Is(err, ErrCredentialNotFound) {
+				return nil, err
+			}
+
+			errs = append(errs, err)
+		}
+
+		if len(errs) == 0 {
+			return nil, ErrCredentialNotFound
+		}
+
+		return nil, errors.Join(errs...)
+	})
+}
+
+/*
+ * CredentialRotator caches the credential of a provider and swaps it without rebuilding
+ * the client, requests in flight keep the credential they were signed with
+ */
+type CredentialRotator struct {
+	provider        CredentialProvider
+	refreshInterval time.Duration
+	mu              sync.RWMutex
+	load            sync.Mutex
+	current         *Credential
+	loadedAt        time.Time
+}
+
+/*
+ * Create a credential rotator, the provider is read again every refresh interval,
+ * a zero interval only reloads on Reload
+ *
+ * @param CredentialProvider provider
+ * @param time.Duration refreshInterval
+ *
+ * @return *CredentialRotator
+ */
+func NewCredentialRotator(provider CredentialProvider, refreshInterval time.Duration) *CredentialRotator {
+	return &CredentialRotator{provider: provider, refreshInterval: refreshInterval}
+}
+
+/*
+ * Get the current credential, a failed refresh keeps the previous credential
+ *
+ * @return *Credential
+ * @return error
+ */
+func (r *CredentialRotator) Credential() (*Credential, error) {
+	r.mu.RLock()
+	current, loadedAt := r.current, r.loadedAt
+	r.mu.RUnlock()
+
+	if current != nil && (r.refreshInterval <= 0 || time.Since(loadedAt) < r.refreshInterval) {
+		return current, nil
+	}
+
+	if err := r.Reload(); err != nil {
+		if current != nil {
+			return current, nil
+		}
+
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.current, nil
+}
+
+func (r *CredentialRotator) Reload() error {
+	r.load.Lock()
+	defer r.load.Unlock()
+
+	if r.provider == nil {
+		return ErrCredentialNotFound
+	}
+
+	credential, err := r.provider.Credential()
+	if err == nil {
+		credential, err = checkCredential(credential)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.loadedAt = time.Now()
+
+	if err != nil {
+		return err
+	}
+
+	r.current = credential
+
+	return nil
+}
+
+/*
+ * Swap the credential, it is used until the next refresh or rotation
+ *
+ * @param Credential credential
+ *
+ * @return error
+ */
+func (r *CredentialRotator) Rotate(credential Credential) error {
+	checked, err := checkCredential(&credential)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.current = checked
+	r.loadedAt = time.Now()
+
+	return nil
+}
+
+type credentialKeystore struct {
+	Version    int    `json:"version"`
+	Kdf        string `json:"kdf"`
+	Iterations int    `json:"iterations"`
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+/*
+ * Encrypt a credential with AES-256-GCM under a key derived from the passphrase with PBKDF2-SHA256
+ *
+ * @param Credential credential
+ * @param []byte passphrase
+ *
+ * @return []byte
+ * @return error
+ */
+func EncryptCredential(credential Credential, passphrase []byte) ([]byte, error) {
+	if _, err := checkCredential(&credential); err != nil {
+		return nil, err
+	}
+
+	if len(passphrase) == 0 {
+		return nil, errors.New("passphrase can not be empty")
+	}
+
+	keystore := credentialKeystore{
+		Version:    1,
+		Kdf:        "pbkdf2-sha256",
+		Iterations: DefaultKeystoreIterations,
+		Salt:       make([]byte, 16),
+	}
+
+	if _, err := rand.Read(keystore.Salt); err != nil {
+		return nil, err
+	}
+
+	aead, err := keystoreCipher(passphrase, keystore.Salt, keystore.Iterations)
+	if err != nil {
+		return nil, err
+	}
+
+	keystore.Nonce = make([]byte, aead.NonceSize())
+
+	if _, err = rand.Read(keystore.Nonce); err != nil {
+		return nil, err
+	}
+
+	plain, err := json.Marshal(credential)
+	if err != nil {
+		return nil, err
+	}
+
+	keystore.Ciphertext = aead.Seal(nil, keystore.Nonce, plain, nil)
+	clear(plain)
+
+	return json.MarshalIndent(keystore, "", "  ")
+}
+
+func DecryptCredential(data, passphrase []byte) (*Credential, error) {
+	var keystore credentialKeystore
+
+	if err := json.Unmarshal(data, &keystore); err != nil {
+		return nil, fmt.Errorf("invalid keystore: %w", err)
+	}
+
+	if keystore.Version != 1 || keystore.Kdf != "pbkdf2-sha256" || keystore.Iterations <= 0 {
+		return nil, fmt.Errorf("unsupported keystore version %d kdf %s", keystore.Version, keystore.Kdf)
+	}
+
+	aead, err := keystoreCipher(passphrase, keystore.Salt, keystore.Iterations)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(keystore.Nonce) != aead.NonceSize() {
+		return nil, errors.New("invalid keystore nonce")
+	}
+
+	plain, err := aead.Open(nil, keystore.Nonce, keystore.Ciphertext, nil)
+	if err != nil {
+		return nil, errors.New("invalid passphrase or corrupted keystore")
+	}
+
+	defer clear(plain)
+
+	var credential Credential
+
+	if err = json.Unmarshal(plain, &credential); err != nil {
+		return nil, fmt.Errorf("invalid keystore content: %w", err)
+	}
+
+	return checkCredential(&credential)
+}
+
+/*
+ * Write an encrypted credential keystore readable by the owner only
+ *
+ * @param string path
+ * @param Credential credential
+ * @param []byte passphrase
+ *
+ * @return error
+ */
+func WriteCredentialKeystore(path string, credential Credential, passphrase []byte) error {
+	data, err := EncryptCredential(credential, passphrase)
+	if err != nil {
+		return err
+	}
+
+	if err = os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+
+	tmp := path + ".tmp"
+
+	if err = os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, path)
+}
+
+func keystoreCipher(passphrase, salt []byte, iterations int) (cipher.AEAD, error) {
+	key := pbkdf2Key(passphrase, salt, iterations, 32)
+	defer clear(key)
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+/*
+ * PBKDF2 with HMAC-SHA256 as defined in RFC 8018
+ *
+ * @param []byte password
+ * @param []byte salt
+ * @param int iterations
+ * @param int keyLen
+ *
+ * @return []byte
+ */
+func pbkdf2Key(password, salt []byte, iterations, keyLen int) []byte {
+	prf := hmac.New(sha256.New, password)
+	size := prf.Size()
+	blocks := (keyLen + size - 1) / size
+
+	key := make([]byte, 0, blocks*size)
+	counter := make([]byte, 4)
+
+	for block := 1; block <= blocks; block++ {
+		binary.BigEndian.PutUint32(counter, uint32(block))
+
+		prf.Reset()
+		prf.Write(salt)
+		prf.Write(counter)
+
+		u := prf.Sum(nil)
+		t := append([]byte{}, u...)
+
+		for i := 1; i < iterations; i++ {
+			prf.Reset()
+			prf.Write(u)
+			u = prf.Sum(u[:0])
+
+			for j := range t {
+				t[j] ^= u[j]
+			}
+		}
+
+		key = append(key, t...)
+	}
+
+	return key[:keyLen]
+}
+
+func readCredentialFile(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s does not exist", ErrCredentialNotFound, path)
+	}
+
+	return data, err
+}
+
+func checkCredential(credential *Credential) (*Credential, error) {
+	if credential == nil || len(credential.TradeApiKey) == 0 || len(credential.TradeApiSecret) == 0 {
+		return nil, errors.New("invalid credential")
+	}
+
+	return &Credential{TradeApiKey: credential.TradeApiKey, TradeApiSecret: credential.TradeApiSecret}, nil
+}
@@ -0,0 +1,150 @@
+package indodax
+
+import (
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPbkdf2Key(t *testing.T) {
+	tests := []struct {
+		iterations int
+		want       string
+	}{
+		{1, "120fb6cffcf8b32c43e7225256c4f837a86548c92ccc35480805987cb70be17b"},
+		{2, "ae4d0c95af6b46d32d0adff928f06dd02a303f8ef3c251dfd6e2d85a95474c43"},
+		{4096, "c5e478d59288c841aa530db6845c4c8d962893a001ce4e11a4963873aa98134a"},
+	}
+
+	for _, test := range tests {
+		if got := hex.EncodeToString(pbkdf2Key([]byte("password"), []byte("salt"), test.iterations, 32)); got != test.want {
+			t.Errorf("%d iterations: expected %s, got %s", test.iterations, test.want, got)
+		}
+	}
+}
+
+func TestCredentialKeystore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys", "indodax.json")
+	credential := Credential{TradeApiKey: "key", TradeApiSecret: "secret"}
+
+	if err := WriteCredentialKeystore(path, credential, []byte("passphrase")); err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("expected the keystore to be readable by the owner only, got %s", info.Mode().Perm())
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Contains(string(data), "secret") {
+		t.Fatalf("keystore holds the secret in plain text: %s", data)
+	}
+
+	loaded, err := NewKeystoreCredentialProvider(path, []byte("passphrase")).Credential()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if *loaded != credential {
+		t.Fatalf("expected %+v, got %+v", credential, *loaded)
+	}
+
+	if _, err = DecryptCredential(data, []byte("wrong")); err == nil {
+		t.Fatal("expected a wrong passphrase to fail")
+	}
+
+	if _, err = EncryptCredential(credential, nil); err == nil {
+		t.Fatal("expected an empty passphrase to be rejected")
+	}
+}
+
+func TestCredentialChain(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "credential.json")
+
+	t.Setenv("INDODAX_TEST_KEY", "")
+	t.Setenv("INDODAX_TEST_SECRET", "")
+
+	chain := NewCredentialChain(
+		NewEnvCredentialProvider("INDODAX_TEST_KEY", "INDODAX_TEST_SECRET"),
+		NewJsonCredentialProvider(path),
+	)
+
+	if _, err := chain.Credential(); !errors.Is(err, ErrCredentialNotFound) {
+		t.Fatalf("expected ErrCredentialNotFound, got %v", err)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"trade_api_key":"file-key","trade_api_secret":"file-secret"}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if credential, err := chain.Credential(); err != nil || credential.TradeApiKey != "file-key" {
+		t.Fatalf("expected the file credential, got %+v %v", credential, err)
+	}
+
+	t.Setenv("INDODAX_TEST_KEY", "env-key")
+	t.Setenv("INDODAX_TEST_SECRET", "env-secret")
+
+	if credential, err := chain.Credential(); err != nil || credential.TradeApiKey != "env-key" {
+		t.Fatalf("expected the environment credential, got %+v %v", credential, err)
+	}
+
+	if err := os.WriteFile(path, []byte(`not json`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("INDODAX_TEST_KEY", "")
+
+	if _, err := chain.Credential(); err == nil || errors.Is(err, ErrCredentialNotFound) {
+		t.Fatalf("expected an invalid file to stop the chain, got %v", err)
+	}
+}
+
+func TestCredentialRotator(t *testing.T) {
+	var credential *Credential
+	var loadErr error
+
+	rotator := NewCredentialRotator(CredentialProviderFunc(func() (*Credential, error) {
+		return credential, loadErr
+	}), 0)
+
+	credential = &Credential{TradeApiKey: "key-1", TradeApiSecret: "secret-1"}
+
+	if current, err := rotator.Credential(); err != nil || current.TradeApiKey != "key-1" {
+		t.Fatalf("expected the first credential, got %+v %v", current, err)
+	}
+
+	credential, loadErr = nil, errors.New("vault is sealed")
+
+	if err := rotator.Reload(); err == nil {
+		t.Fatal("expected the reload to fail")
+	}
+
+	if current, err := rotator.Credential(); err != nil || current.TradeApiKey != "key-1" {
+		t.Fatalf("expected a failed reload to keep the credential, got %+v %v", current, err)
+	}
+
+	if err := rotator.Rotate(Credential{TradeApiKey: "key-2", TradeApiSecret: "secret-2"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if current, err := rotator.Credential(); err != nil || current.TradeApiKey != "key-2" {
+		t.Fatalf("expected the rotated credential, got %+v %v", current, err)
+	}
+
+	if err := rotator.Rotate(Credential{TradeApiKey: "key-3"}); err == nil {
+		t.Fatal("expected a credential without secret to be rejected")
+	}
+}
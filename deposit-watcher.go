@@ -0,0 +1,324 @@
+package indodax
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const depositStoreKey = "deposits"
+
+type DepositWatcher struct {
+	client  WalletAPI
+	store   Store
+	config  DepositWatcherConfig
+	handler func(DepositEvent) error
+	mu      sync.Mutex
+	polling sync.Mutex
+	cursor  DepositCursor
+}
+
+func NewDepositWatcher(client WalletAPI, store Store, config DepositWatcherConfig) (*DepositWatcher, error) {
+	if client == nil {
+		return nil, errors.New("client is required")
+	}
+
+	if store == nil {
+		store = NewMemoryStore()
+	}
+
+	if config.PollInterval <= 0 {
+		config.PollInterval = DefaultDepositPollInterval
+	}
+
+	if config.Overlap <= 0 {
+		config.Overlap = DefaultDepositOverlap
+	}
+
+	w := &DepositWatcher{
+		client: client,
+		store:  store,
+		config: config,
+	}
+
+	if err := store.Load(depositStoreKey, &w.cursor); err != nil && !errors.Is(err, ErrStateNotFound) {
+		return nil, err
+	}
+
+	if w.cursor.Seen == nil {
+		w.cursor.Seen = map[string]SeenDeposit{}
+	}
+
+	return w, nil
+}
+
+/*
+ * Set event handler, an event is only marked as delivered when the handler
+ * returns nil, otherwise it is delivered again on the next poll
+ *
+ * @param func(DepositEvent) error handler
+ *
+ * @return *DepositWatcher
+ */
+func (w *DepositWatcher) WithHandler(handler func(DepositEvent) error) *DepositWatcher {
+	w.handler = handler
+
+	return w
+}
+
+func (w *DepositWatcher) WithChannel(ch chan<- DepositEvent) *DepositWatcher {
+	return w.WithHandler(func(event DepositEvent) error {
+		ch <- event
+
+		return nil
+	})
+}
+
+func (w *DepositWatcher) Cursor() DepositCursor {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	seen := make(map[string]SeenDeposit, len(w.cursor.Seen))
+
+	for k, v := range w.cursor.Seen {
+		seen[k] = v
+	}
+
+	return DepositCursor{Since: w.cursor.Since, Seen: seen}
+}
+
+/*
+ * Poll transaction history and deliver new deposits and status changes. Polls are
+ * serialized, the handler is called without holding the cursor lock
+ *
+ * @return error
+ */
+func (w *DepositWatcher) Poll() error {
+	w.polling.Lock()
+	defer w.polling.Unlock()
+
+	now := time.Now()
+
+	addresses, err := w.watchedAddresses()
+	if err != nil {
+		return err
+	}
+
+	cursor := w.Cursor()
+
+	from := cursor.Since.Add(-w.config.Overlap)
+	if cursor.Since.IsZero() {
+		from = now.Add(-w.config.Overlap)
+	}
+
+	for _, seen := range cursor.Seen {
+		if seen.Status != DepositStatusSuccess && seen.Time.Before(from) {
+			from = seen.Time
+		}
+	}
+
+	start := from.AddDate(0, 0, -1)
+	start = time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, start.Location())
+
+	history, err := getTransactionHistoryRange(w.client, start, now.AddDate(0, 0, 1))
+	if err != nil {
+		return err
+	}
+
+	var deposits []Deposit
+
+	for currency, records := range history.Deposit {
+		currency = strings.ToLower(currency)
+
+		if !w.isWatchedCurrency(currency) {
+			continue
+		}
+
+		address, exist := addresses[currency]
+		if addresses != nil && !exist {
+			continue
+		}
+
+		for _, record := range records {
+			deposit := parseDeposit(currency, record)
+			deposit.Address = address
+
+			deposits = append(deposits, deposit)
+		}
+	}
+
+	sort.SliceStable(deposits, func(i, j int) bool {
+		return depositTime(deposits[i]).Before(depositTime(deposits[j]))
+	})
+
+	for _, deposit := range deposits {
+		key := depositKey(deposit)
+		seen, exist := cursor.Seen[key]
+
+		if exist && seen.Status == deposit.Status {
+			continue
+		}
+
+		event := DepositEvent{Type: DepositEventNew, Deposit: deposit}
+
+		if exist {
+			event.Type = DepositEventStatusChanged
+			event.PreviousStatus = seen.Status
+		}
+
+		if w.handler != nil {
+			if err = w.handler(event); err != nil {
+				if saveErr := w.save(); saveErr != nil {
+					return saveErr
+				}
+
+				return err
+			}
+		}
+
+		cursor.Seen[key] = SeenDeposit{Status: deposit.Status, Time: depositTime(deposit)}
+
+		w.mu.Lock()
+		w.cursor.Seen[key] = cursor.Seen[key]
+		w.mu.Unlock()
+	}
+
+	// the exchange reads the dates in its own timezone, a day of margin keeps deposits
+	// that can still be returned by the query from being pruned and delivered again
+	pruneBefore := start.AddDate(0, 0, -1)
+
+	w.mu.Lock()
+
+	w.cursor.Since = now
+
+	for key, seen := range w.cursor.Seen {
+		if seen.Status == DepositStatusSuccess && seen.Time.Before(pruneBefore) {
+			delete(w.cursor.Seen, key)
+		}
+	}
+
+	w.mu.Unlock()
+
+	return w.save()
+}
+
+func (w *DepositWatcher) Run(ctx context.Context) error {
+	ticker := time.NewTicker(w.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := w.Poll(); err != nil {
+			logApiError(w.client, "failed to poll deposits", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *DepositWatcher) save() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.store.Save(depositStoreKey, w.cursor)
+}
+
+/*
+ * Resolve deposit addresses to watch, returns nil when no address filter is configured
+ *
+ * @return map[string]string
+ * @return error
+ */
+func (w *DepositWatcher) watchedAddresses() (map[string]string, error) {
+	if len(w.config.Addresses) == 0 {
+		return nil, nil
+	}
+
+	info, err := w.client.GetInfo()
+	if err != nil {
+		return nil, err
+	}
+
+	result := map[string]string{}
+
+	for currency, address := range info.Address {
+		for _, watched := range w.config.Addresses {
+			if strings.EqualFold(strings.TrimSpace(watched), address) {
+				result[strings.ToLower(currency)] = address
+			}
+		}
+	}
+
+	return result, nil
+}
+
+func (w *DepositWatcher) isWatchedCurrency(currency string) bool {
+	if len(w.config.Currencies) == 0 {
+		return true
+	}
+
+	for _, c := range w.config.Currencies {
+		if strings.EqualFold(c, currency) {
+			return true
+		}
+	}
+
+	return false
+}
+
+/*
+ * Parse deposit record from transaction history
+ *
+ * @param string currency
+ * @param map[string]interface{} record
+ *
+ * @return Deposit
+ */
+func parseDeposit(currency string, record map[string]interface{}) Deposit {
+	amount := toString(record["amount"])
+	if len(amount) == 0 {
+		amount = toString(record[currency])
+	}
+
+	if len(amount) == 0 {
+		amount = toString(record["rp"])
+	}
+
+	status := strings.ToLower(toString(record["status"]))
+
+	return Deposit{
+		DepositId:   toString(record["deposit_id"]),
+		TxId:        toString(record["tx"]),
+		Currency:    currency,
+		Amount:      amount,
+		Fee:         toString(record["fee"]),
+		Status:      status,
+		Confirmed:   status == DepositStatusSuccess,
+		SubmittedAt: toTime(record["submit_time"]),
+		SuccessAt:   toTime(record["success_time"]),
+	}
+}
+
+func depositKey(deposit Deposit) string {
+	id := deposit.DepositId
+	if len(id) == 0 {
+		id = deposit.TxId
+	}
+
+	return fmt.Sprintf("%s:%s", deposit.Currency, id)
+}
+
+func depositTime(deposit Deposit) time.Time {
+	if !deposit.SubmittedAt.IsZero() {
+		return deposit.SubmittedAt
+	}
+
+	return deposit.SuccessAt
+}
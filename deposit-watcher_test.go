@@ -0,0 +1,88 @@
+package indodax
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDepositWatcherDeliversDepositOnce(t *testing.T) {
+	wallet := &fakeWallet{}
+	at := time.Now().Add(-20 * time.Hour)
+
+	wallet.add("btc", true, at, map[string]interface{}{
+		"deposit_id":  "1",
+		"status":      "success",
+		"amount":      "0.1",
+		"submit_time": at.Unix(),
+	})
+
+	watcher, err := NewDepositWatcher(wallet, nil, DepositWatcherConfig{Overlap: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var events []DepositEvent
+
+	watcher.WithHandler(func(event DepositEvent) error {
+		events = append(events, event)
+
+		return nil
+	})
+
+	for i := 0; i < 3; i++ {
+		if err = watcher.Poll(); err != nil {
+			t.Fatalf("poll %d: %v", i, err)
+		}
+	}
+
+	if len(events) != 1 {
+		t.Fatalf("expected 1 event in 3 polls, got %d", len(events))
+	}
+}
+
+func TestDepositWatcherSplitsRangeAfterDowntime(t *testing.T) {
+	wallet := &fakeWallet{}
+	store := NewMemoryStore()
+	at := time.Now().AddDate(0, 0, -15)
+
+	wallet.add("idr", true, at, map[string]interface{}{
+		"deposit_id":  "1",
+		"status":      "success",
+		"amount":      "100000",
+		"submit_time": at.Unix(),
+	})
+
+	if err := store.Save(depositStoreKey, DepositCursor{Since: time.Now().AddDate(0, 0, -20)}); err != nil {
+		t.Fatal(err)
+	}
+
+	watcher, err := NewDepositWatcher(wallet, store, DepositWatcherConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var events []DepositEvent
+
+	watcher.WithHandler(func(event DepositEvent) error {
+		// the handler may use the watcher, it is not called under the cursor lock
+		if _, seen := watcher.Cursor().Seen[depositKey(event.Deposit)]; seen {
+			t.Errorf("deposit %s is marked as seen before it is delivered", event.Deposit.DepositId)
+		}
+
+		events = append(events, event)
+
+		return nil
+	})
+
+	if err = watcher.Poll(); err != nil {
+		t.Fatalf("poll: %v", err)
+	}
+
+	if len(events) != 1 {
+		t.Fatalf("expected the deposit to be delivered after downtime, got %d events", len(events))
+	}
+
+	if len(wallet.ranges) < 3 {
+		t.Fatalf("expected the range to be split in 7 day chunks, got %v", wallet.ranges)
+	}
+}
@@ -0,0 +1,122 @@
+package indodax
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+)
+
+type historyRecord struct {
+	currency string
+	deposit  bool
+	time     time.Time
+	fields   map[string]interface{}
+}
+
+// fakeWallet serves transaction history from records filtered by their submit time,
+// and fails like the exchange when a range is wider than 7 days
+type fakeWallet struct {
+	mu         sync.Mutex
+	records    []historyRecord
+	ranges     [][2]string
+	balance    map[string]json.Number
+	historyErr error
+}
+
+// fakePrivate is a PrivateAPI backed by a fakeWallet, trading calls are not implemented
+type fakePrivate struct {
+	*fakeWallet
+	unimplementedPrivate
+}
+
+type unimplementedPrivate struct {
+	PrivateAPI
+}
+
+func (f *fakeWallet) add(currency string, deposit bool, at time.Time, fields map[string]interface{}) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.records = append(f.records, historyRecord{currency: currency, deposit: deposit, time: at, fields: fields})
+}
+
+func (f *fakeWallet) set(currency, id, key string, value interface{}) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, record := range f.records {
+		if record.currency == currency && (record.fields["withdraw_id"] == id || record.fields["deposit_id"] == id) {
+			record.fields[key] = value
+		}
+	}
+}
+
+func (f *fakeWallet) GetInfo() (*GetInfoResponseBody, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	balance := map[string]json.Number{}
+	for currency, amount := range f.balance {
+		balance[currency] = amount
+	}
+
+	return &GetInfoResponseBody{Balance: balance}, nil
+}
+
+func (f *fakeWallet) GetTransactionHistory(fromDate, toDate string) (*GetTransactionHistoryResponseBody, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.ranges = append(f.ranges, [2]string{fromDate, toDate})
+
+	if f.historyErr != nil {
+		return nil, f.historyErr
+	}
+
+	from, err := time.ParseInLocation(TransactionHistoryDateLayout, fromDate, time.Local)
+	if err != nil {
+		return nil, err
+	}
+
+	to, err := time.ParseInLocation(TransactionHistoryDateLayout, toDate, time.Local)
+	if err != nil {
+		return nil, err
+	}
+
+	if to.Sub(from) >= TransactionHistoryMaxRange {
+		return nil, fmt.Errorf("range %s to %s is longer than 7 days", fromDate, toDate)
+	}
+
+	result := GetTransactionHistoryResponseBody{
+		Withdraw: map[string][]map[string]interface{}{},
+		Deposit:  map[string][]map[string]interface{}{},
+	}
+
+	for _, record := range f.records {
+		if record.time.Before(from) || !record.time.Before(to.AddDate(0, 0, 1)) {
+			continue
+		}
+
+		fields := map[string]interface{}{}
+		for key, value := range record.fields {
+			fields[key] = value
+		}
+
+		if record.deposit {
+			result.Deposit[record.currency] = append(result.Deposit[record.currency], fields)
+		} else {
+			result.Withdraw[record.currency] = append(result.Withdraw[record.currency], fields)
+		}
+	}
+
+	return &result, nil
+}
+
+func (f *fakeWallet) Withdraw(_, _, _, _, _, _ string) (*WithdrawCoinResponseBody, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
+func (f *fakeWallet) GetWithdrawFee(_ string, _ *string) (*map[string]interface{}, error) {
+	return nil, fmt.Errorf("not implemented")
+}
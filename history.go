@@ -0,0 +1,294 @@
+package indodax
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	ownTradesPageSize    = 999
+	orderHistoryPageSize = 999
+)
+
+func (c *Client) GetOwnTrades(pair string, since, end *time.Time) ([]OwnTrade, error) {
+	return getOwnTrades(c, pair, since, end)
+}
+
+func (c *Client) GetTransactionHistoryRange(from, to time.Time) (*GetTransactionHistoryResponseBody, error) {
+	return getTransactionHistoryRange(c, from, to)
+}
+
+/*
+ * Get all own trades of a pair, paging through trade history
+ *
+ * @param TradingAPI api
+ * @param string pair
+ * @param *time.Time since
+ * @param *time.Time end
+ *
+ * @return []OwnTrade
+ * @return error
+ */
+func getOwnTrades(api TradingAPI, pair string, since, end *time.Time) ([]OwnTrade, error) {
+	var result []OwnTrade
+
+	order := "asc"
+	count := int64(ownTradesPageSize)
+
+	var fromId *string
+
+	var sinceTs, endTs *int64
+
+	if since != nil {
+		ts := since.Unix()
+		sinceTs = &ts
+	}
+
+	if end != nil {
+		ts := end.Unix()
+		endTs = &ts
+	}
+
+	for {
+		resp, err := api.GetTradeHistory(pair, fromId, nil, &order, sinceTs, endTs, &count, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, record := range resp.Trades {
+			result = append(result, parseOwnTrade(pair, record))
+		}
+
+		if int64(len(resp.Trades)) < count {
+			break
+		}
+
+		lastId, err := strconv.ParseInt(result[len(result)-1].TradeId, 10, 64)
+		if err != nil {
+			break
+		}
+
+		next := strconv.FormatInt(lastId+1, 10)
+		fromId = &next
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Time.Before(result[j].Time)
+	})
+
+	return result, nil
+}
+
+/*
+ * Parse own trade record from trade history
+ *
+ * @param string pair
+ * @param map[string]interface{} record
+ *
+ * @return OwnTrade
+ */
+func parseOwnTrade(pair string, record map[string]interface{}) OwnTrade {
+	base, _, _ := splitPair(pair)
+
+	return OwnTrade{
+		TradeId:       toString(record["trade_id"]),
+		OrderId:       toString(record["order_id"]),
+		ClientOrderId: toString(record["client_order_id"]),
+		Pair:          pair,
+		Type:          strings.ToLower(toString(record["type"])),
+		Price:         toFloat(record["price"]),
+		Amount:        toFloat(record[base]),
+		Fee:           toFloat(record["fee"]),
+		Time:          toTime(record["trade_time"]),
+	}
+}
+
+/*
+ * Split pair id into traded and base currency
+ *
+ * @param string pair
+ *
+ * @return string
+ * @return string
+ * @return error
+ */
+func splitPair(pair string) (string, string, error) {
+	slPair := strings.Split(strings.ToLower(pair), "_")
+	if len(slPair) != 2 {
+		return "", "", errors.New("invalid pair")
+	}
+
+	return slPair[0], slPair[1], nil
+}
+
+/*
+ * Calculate balance movements caused by an own trade, fee is charged in base currency
+ *
+ * @param OwnTrade trade
+ *
+ * @return map[string]float64
+ */
+func tradeBalanceEffects(trade OwnTrade) map[string]float64 {
+	coin, currency, err := splitPair(trade.Pair)
+	if err != nil {
+		return nil
+	}
+
+	value := trade.Price * trade.Amount
+
+	switch trade.Type {
+	case TradeTypeBuy:
+		return map[string]float64{
+			coin:     trade.Amount,
+			currency: -value - trade.Fee,
+		}
+	case TradeTypeSell:
+		return map[string]float64{
+			coin:     -trade.Amount,
+			currency: value - trade.Fee,
+		}
+	}
+
+	return nil
+}
+
+/*
+ * Get the order history of a pair back to a point in time, the history is
+ * returned newest first and is paged by offset until an older order is reached
+ *
+ * @param TradingAPI api
+ * @param string pair
+ * @param time.Time since
+ *
+ * @return []map[string]interface{}
+ * @return error
+ */
+func getOrderHistory(api TradingAPI, pair string, since time.Time) ([]map[string]interface{}, error) {
+	var result []map[string]interface{}
+
+	count := orderHistoryPageSize
+	offset := 0
+
+	for {
+		resp, err := api.GetOrderHistory(pair, &count, &offset)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, resp.Orders...)
+
+		if len(resp.Orders) < count {
+			return result, nil
+		}
+
+		if !since.IsZero() && toTime(resp.Orders[len(resp.Orders)-1]["submit_time"]).Before(since) {
+			return result, nil
+		}
+
+		offset += len(resp.Orders)
+	}
+}
+
+/*
+ * Split a withdrawal history record into the amount sent and the fee, the balance is
+ * debited with both. The amount key holds the amount sent, the currency and rp keys
+ * hold the debited amount with the fee included
+ *
+ * @param string currency
+ * @param map[string]interface{} record
+ *
+ * @return float64 amount sent
+ * @return float64 fee
+ */
+func withdrawalTransfer(currency string, record map[string]interface{}) (float64, float64) {
+	fee := toFloat(record["fee"])
+
+	if v := toString(record["amount"]); len(v) > 0 {
+		return toFloat(v), fee
+	}
+
+	for _, key := range []string{currency, "rp"} {
+		if v := toString(record[key]); len(v) > 0 {
+			return toFloat(v) - fee, fee
+		}
+	}
+
+	return 0, fee
+}
+
+/*
+ * Split a deposit into the amount sent and the fee, the balance is credited with the
+ * amount of the deposit, which is the amount sent less the fee
+ *
+ * @param Deposit deposit
+ *
+ * @return float64 amount sent
+ * @return float64 fee
+ */
+func depositTransfer(deposit Deposit) (float64, float64) {
+	fee := toFloat(deposit.Fee)
+
+	return toFloat(deposit.Amount) + fee, fee
+}
+
+/*
+ * Get transaction history for an arbitrary date range, the range is split
+ * into windows accepted by the api and the results are merged
+ *
+ * @param WalletAPI api
+ * @param time.Time from
+ * @param time.Time to
+ *
+ * @return *GetTransactionHistoryResponseBody
+ * @return error
+ */
+func getTransactionHistoryRange(api WalletAPI, from, to time.Time) (*GetTransactionHistoryResponseBody, error) {
+	if to.Before(from) {
+		return nil, errors.New("invalid date range")
+	}
+
+	result := GetTransactionHistoryResponseBody{
+		Withdraw: map[string][]map[string]interface{}{},
+		Deposit:  map[string][]map[string]interface{}{},
+	}
+
+	seen := map[string]bool{}
+
+	for start := from; !start.After(to); start = start.Add(TransactionHistoryMaxRange) {
+		end := start.Add(TransactionHistoryMaxRange - 24*time.Hour)
+		if end.After(to) {
+			end = to
+		}
+
+		resp, err := api.GetTransactionHistory(start.Format(TransactionHistoryDateLayout), end.Format(TransactionHistoryDateLayout))
+		if err != nil {
+			return nil, err
+		}
+
+		for currency, records := range resp.Withdraw {
+			for _, record := range records {
+				key := fmt.Sprintf("w:%s:%s", currency, toString(record["withdraw_id"]))
+				if !seen[key] {
+					seen[key] = true
+					result.Withdraw[currency] = append(result.Withdraw[currency], record)
+				}
+			}
+		}
+
+		for currency, records := range resp.Deposit {
+			for _, record := range records {
+				key := fmt.Sprintf("d:%s:%s:%s", currency, toString(record["deposit_id"]), toString(record["tx"]))
+				if !seen[key] {
+					seen[key] = true
+					result.Deposit[currency] = append(result.Deposit[currency], record)
+				}
+			}
+		}
+	}
+
+	return &result, nil
+}
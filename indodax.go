@@ -1,59 +1,81 @@
 package indodax
 
 import (
-	"crypto/hmac"
-	"crypto/sha512"
-	"encoding/hex"
+	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"github.com/vannleonheart/goutil"
+	"log/slog"
+	"net/http"
+	"strconv"
 	"strings"
+	"time"
 )
 
 func New(config Config) *Client {
 	return &Client{Config: config}
 }
 
+/*
+ * Derive a client using the credential, c is left untouched so clients with different
+ * credentials can be used from several goroutines at once
+ *
+ * @param string tradeApiKey
+ * @param string tradeApiSecret
+ *
+ * @return *Client
+ */
 func (c *Client) WithCredential(tradeApiKey, tradeApiSecret string) *Client {
-	c.Credential = &Credential{
+	derived := c.withoutCredential()
+	derived.Credential = &Credential{
 		TradeApiKey:    tradeApiKey,
 		TradeApiSecret: tradeApiSecret,
 	}
 
-	return c
+	return derived
+}
+
+func (c *Client) WithHttpClient(httpClient *http.Client) *Client {
+	derived := *c
+	derived.HttpClient = httpClient
+
+	return &derived
+}
+
+func (c *Client) WithLogger(logger *slog.Logger) *Client {
+	derived := *c
+	derived.Logger = logger
+
+	return &derived
 }
 
 /*
- * Logging
+ * Copy of the client without credential, signer and credential provider. The copy shares
+ * the configuration, http client, logger, metrics, tracer and middleware of c
  *
- * @param string level
- * @param interface{} data
- *
- * @return void
+ * @return *Client
  */
-func (c *Client) log(level string, data interface{}) {
-	if c.Config.Log != nil && c.Config.Log.Enable {
-		if c.Config.Log.Level == "error" && level != "error" {
-			return
-		}
+func (c *Client) withoutCredential() *Client {
+	derived := *c
+	derived.Credential = nil
+	derived.Signer = nil
+	derived.CredentialProvider = nil
 
-		msg := map[string]interface{}{
-			"level": level,
-			"data":  data,
-		}
-
-		_ = goutil.WriteJsonToFile(msg, c.Config.Log.Path, c.Config.Log.Filename, c.Config.Log.Extension, c.Config.Log.Rotation)
-	}
+	return &derived
 }
 
 /*
  * Generate signature
  *
+ * @param context.Context ctx
+ * @param Signer signer
  * @param map[string]interface{} data
  *
  * @return *string
  * @return error
  */
-func (c *Client) generateSign(data map[string]interface{}) (*string, error) {
+func (c *Client) generateSign(ctx context.Context, signer Signer, data map[string]interface{}) (*string, error) {
 	queryString, err := goutil.GenerateQueryString(data)
 	if err != nil {
 		return nil, err
@@ -63,16 +85,11 @@ func (c *Client) generateSign(data map[string]interface{}) (*string, error) {
 		return nil, errors.New("generated query string is nil")
 	}
 
-	if c.Credential == nil || len(c.Credential.TradeApiSecret) <= 0 {
-		return nil, errors.New("invalid credential")
+	signature, err := signer.Sign(ctx, []byte(*queryString))
+	if err != nil {
+		return nil, err
 	}
 
-	h := hmac.New(sha512.New, []byte(c.Credential.TradeApiSecret))
-
-	h.Write([]byte(*queryString))
-
-	signature := hex.EncodeToString(h.Sum(nil))
-
 	return &signature, nil
 }
 
@@ -105,3 +122,70 @@ func (c *Client) getNetworkName(currency, network string) string {
 
 	return network
 }
+
+/*
+ * Convert loosely typed api value to string
+ *
+ * @param interface{} value
+ *
+ * @return string
+ */
+func toString(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+
+	return fmt.Sprintf("%v", value)
+}
+
+/*
+ * Convert loosely typed api value to float64
+ *
+ * @param interface{} value
+ *
+ * @return float64
+ */
+func toFloat(value interface{}) float64 {
+	switch v := value.(type) {
+	case float64:
+		return v
+	case int:
+		return float64(v)
+	case int64:
+		return float64(v)
+	}
+
+	f, err := strconv.ParseFloat(strings.TrimSpace(toString(value)), 64)
+	if err != nil {
+		return 0
+	}
+
+	return f
+}
+
+/*
+ * Convert loosely typed unix timestamp to time
+ *
+ * @param interface{} value
+ *
+ * @return time.Time
+ */
+func toTime(value interface{}) time.Time {
+	ts := int64(toFloat(value))
+	if ts <= 0 {
+		return time.Time{}
+	}
+
+	if ts > 1e12 {
+		return time.UnixMilli(ts)
+	}
+
+	return time.Unix(ts, 0)
+}
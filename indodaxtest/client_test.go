@@ -0,0 +1,81 @@
+package indodaxtest
+
+import (
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/vannleonheart/indodax-api-go"
+)
+
+func TestDerivedClientsAreIndependent(t *testing.T) {
+	server := NewServer("key", "secret")
+	defer server.Close()
+
+	server.SetBalance("idr", 1000)
+
+	base := indodax.New(indodax.Config{PublicApiBaseUrl: server.URL, PrivateApiBaseUrl: server.URL})
+
+	var counted atomic.Int64
+
+	counter := func(next indodax.ApiHandler) indodax.ApiHandler {
+		return func(req *indodax.ApiRequest) (*[]byte, error) {
+			counted.Add(1)
+			return next(req)
+		}
+	}
+
+	var wg sync.WaitGroup
+
+	errs := make(chan error, 300)
+
+	for i := 0; i < 100; i++ {
+		wg.Add(3)
+
+		go func() {
+			defer wg.Done()
+
+			metrics := indodax.NewMetrics("test")
+
+			if _, err := base.WithMetrics(metrics).WithMiddleware(counter).WithCredential("key", "secret").GetInfo(); err != nil {
+				errs <- err
+			}
+		}()
+
+		go func() {
+			defer wg.Done()
+
+			if _, err := base.WithCredential("key", "wrong").GetInfo(); err == nil || !strings.Contains(err.Error(), "Bad sign") {
+				errs <- err
+			}
+		}()
+
+		go func() {
+			defer wg.Done()
+
+			if _, err := base.WithSigner(indodax.NewHmacSigner("key", "secret")).GetInfo(); err != nil {
+				errs <- err
+			}
+		}()
+	}
+
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Errorf("unexpected result: %v", err)
+	}
+
+	if counted.Load() != 100 {
+		t.Errorf("expected the middleware to see only the calls of its client, got %d", counted.Load())
+	}
+
+	if base.Credential != nil || base.Signer != nil || base.Metrics != nil || len(base.Middleware) > 0 {
+		t.Errorf("expected the base client to stay unchanged, got %+v", base)
+	}
+
+	if _, err := base.GetInfo(); err == nil {
+		t.Error("expected the base client to have no credential")
+	}
+}
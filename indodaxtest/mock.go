@@ -0,0 +1,582 @@
+package indodaxtest
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/vannleonheart/indodax-api-go"
+)
+
+var ErrNoMockResponse = errors.New("no mock response configured")
+
+type MockCall struct {
+	Method string        `json:"method"`
+	Args   []interface{} `json:"args"`
+}
+
+type MockResponse struct {
+	Result interface{}
+	Err    error
+}
+
+type MockClient struct {
+	GetServerTimeFunc                  func() (*indodax.GetServerTimeResponseBody, error)
+	GetPairsFunc                       func() (*[]indodax.Pair, error)
+	GetPriceIncrementsFunc             func() (*indodax.GetPriceIncrementsResponseBody, error)
+	GetSummariesFunc                   func() (*indodax.GetSummariesResponseBody, error)
+	GetTickerFunc                      func(pairId string) (*indodax.GetTickerResponseBody, error)
+	GetTickerAllFunc                   func() (*indodax.GetTickerAllResponseBody, error)
+	GetTradesFunc                      func(pairId string) (*[]indodax.Trade, error)
+	GetDepthFunc                       func(pairId string) (*indodax.GetDepthResponseBody, error)
+	GetOHLCHistoryFunc                 func(pairId, timeFrame string, from, to int64) (*[]indodax.OHLC, error)
+	GetTradeHistoryFunc                func(pair string, fromId, toId, order *string, since, end, count *int64, orderId *string) (*indodax.GetTradeHistoryResponseBody, error)
+	GetOpenOrdersFunc                  func(pair *string) (interface{}, error)
+	GetOrderHistoryFunc                func(pair string, count, from *int) (*indodax.GetOrderHistoryResponseBody, error)
+	GetOrderFunc                       func(pair, orderId string) (*indodax.GetOrderResponseBody, error)
+	GetOrderByClientOrderIdFunc        func(clientOrderId string) (*indodax.GetOrderResponseBody, error)
+	TradeFunc                          func(tradeType, pair, orderType string, price, amount float64, timeInForce, clientOrderId *string, forceCoinAmount bool) (*indodax.ResponseBody, error)
+	CancelOrderFunc                    func(pair, orderId, tradeType string, orderType *string) (*map[string]interface{}, error)
+	CancelOrderByClientOrderIdFunc     func(clientOrderId string) (*map[string]interface{}, error)
+	GetInfoFunc                        func() (*indodax.GetInfoResponseBody, error)
+	GetTransactionHistoryFunc          func(fromDate, toDate string) (*indodax.GetTransactionHistoryResponseBody, error)
+	WithdrawFunc                       func(requestId, currency, address, network, amount, memo string) (*indodax.WithdrawCoinResponseBody, error)
+	GetWithdrawFeeFunc                 func(currency string, coinNetwork *string) (*map[string]interface{}, error)
+	PrivateApiCallFunc                 func(method string, data *map[string]interface{}) (*indodax.ResponseBody, error)
+	PrivateApiCallWithCustomResultFunc func(method string, data *map[string]interface{}, result interface{}) error
+
+	mu        sync.Mutex
+	calls     []MockCall
+	responses map[string][]MockResponse
+}
+
+var (
+	_ indodax.PublicAPI  = (*MockClient)(nil)
+	_ indodax.TradingAPI = (*MockClient)(nil)
+	_ indodax.WalletAPI  = (*MockClient)(nil)
+	_ indodax.PrivateAPI = (*MockClient)(nil)
+)
+
+func NewMockClient() *MockClient {
+	return &MockClient{responses: map[string][]MockResponse{}}
+}
+
+/*
+ * Queue a canned response for a method, queued responses are returned in order
+ * and the last one is repeated once the queue is drained
+ *
+ * @param string method
+ * @param interface{} result
+ * @param error err
+ *
+ * @return *MockClient
+ */
+func (m *MockClient) On(method string, result interface{}, err error) *MockClient {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.responses == nil {
+		m.responses = map[string][]MockResponse{}
+	}
+
+	m.responses[method] = append(m.responses[method], MockResponse{Result: result, Err: err})
+
+	return m
+}
+
+func (m *MockClient) Calls() []MockCall {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	calls := make([]MockCall, len(m.calls))
+	copy(calls, m.calls)
+
+	return calls
+}
+
+func (m *MockClient) CallsTo(method string) []MockCall {
+	var result []MockCall
+
+	for _, call := range m.Calls() {
+		if call.Method == method {
+			result = append(result, call)
+		}
+	}
+
+	return result
+}
+
+func (m *MockClient) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.calls = nil
+	m.responses = map[string][]MockResponse{}
+}
+
+func (m *MockClient) GetServerTime() (*indodax.GetServerTimeResponseBody, error) {
+	m.record("GetServerTime", nil)
+
+	if m.GetServerTimeFunc != nil {
+		return m.GetServerTimeFunc()
+	}
+
+	result, err := m.response("GetServerTime")
+	if result == nil {
+		return nil, err
+	}
+
+	typed, ok := result.(*indodax.GetServerTimeResponseBody)
+	if !ok {
+		return nil, fmt.Errorf("mock response for GetServerTime has type %T", result)
+	}
+
+	return typed, err
+}
+
+func (m *MockClient) GetPairs() (*[]indodax.Pair, error) {
+	m.record("GetPairs", nil)
+
+	if m.GetPairsFunc != nil {
+		return m.GetPairsFunc()
+	}
+
+	result, err := m.response("GetPairs")
+	if result == nil {
+		return nil, err
+	}
+
+	typed, ok := result.(*[]indodax.Pair)
+	if !ok {
+		return nil, fmt.Errorf("mock response for GetPairs has type %T", result)
+	}
+
+	return typed, err
+}
+
+func (m *MockClient) GetPriceIncrements() (*indodax.GetPriceIncrementsResponseBody, error) {
+	m.record("GetPriceIncrements", nil)
+
+	if m.GetPriceIncrementsFunc != nil {
+		return m.GetPriceIncrementsFunc()
+	}
+
+	result, err := m.response("GetPriceIncrements")
+	if result == nil {
+		return nil, err
+	}
+
+	typed, ok := result.(*indodax.GetPriceIncrementsResponseBody)
+	if !ok {
+		return nil, fmt.Errorf("mock response for GetPriceIncrements has type %T", result)
+	}
+
+	return typed, err
+}
+
+func (m *MockClient) GetSummaries() (*indodax.GetSummariesResponseBody, error) {
+	m.record("GetSummaries", nil)
+
+	if m.GetSummariesFunc != nil {
+		return m.GetSummariesFunc()
+	}
+
+	result, err := m.response("GetSummaries")
+	if result == nil {
+		return nil, err
+	}
+
+	typed, ok := result.(*indodax.GetSummariesResponseBody)
+	if !ok {
+		return nil, fmt.Errorf("mock response for GetSummaries has type %T", result)
+	}
+
+	return typed, err
+}
+
+func (m *MockClient) GetTicker(pairId string) (*indodax.GetTickerResponseBody, error) {
+	m.record("GetTicker", []interface{}{pairId})
+
+	if m.GetTickerFunc != nil {
+		return m.GetTickerFunc(pairId)
+	}
+
+	result, err := m.response("GetTicker")
+	if result == nil {
+		return nil, err
+	}
+
+	typed, ok := result.(*indodax.GetTickerResponseBody)
+	if !ok {
+		return nil, fmt.Errorf("mock response for GetTicker has type %T", result)
+	}
+
+	return typed, err
+}
+
+func (m *MockClient) GetTickerAll() (*indodax.GetTickerAllResponseBody, error) {
+	m.record("GetTickerAll", nil)
+
+	if m.GetTickerAllFunc != nil {
+		return m.GetTickerAllFunc()
+	}
+
+	result, err := m.response("GetTickerAll")
+	if result == nil {
+		return nil, err
+	}
+
+	typed, ok := result.(*indodax.GetTickerAllResponseBody)
+	if !ok {
+		return nil, fmt.Errorf("mock response for GetTickerAll has type %T", result)
+	}
+
+	return typed, err
+}
+
+func (m *MockClient) GetTrades(pairId string) (*[]indodax.Trade, error) {
+	m.record("GetTrades", []interface{}{pairId})
+
+	if m.GetTradesFunc != nil {
+		return m.GetTradesFunc(pairId)
+	}
+
+	result, err := m.response("GetTrades")
+	if result == nil {
+		return nil, err
+	}
+
+	typed, ok := result.(*[]indodax.Trade)
+	if !ok {
+		return nil, fmt.Errorf("mock response for GetTrades has type %T", result)
+	}
+
+	return typed, err
+}
+
+func (m *MockClient) GetDepth(pairId string) (*indodax.GetDepthResponseBody, error) {
+	m.record("GetDepth", []interface{}{pairId})
+
+	if m.GetDepthFunc != nil {
+		return m.GetDepthFunc(pairId)
+	}
+
+	result, err := m.response("GetDepth")
+	if result == nil {
+		return nil, err
+	}
+
+	typed, ok := result.(*indodax.GetDepthResponseBody)
+	if !ok {
+		return nil, fmt.Errorf("mock response for GetDepth has type %T", result)
+	}
+
+	return typed, err
+}
+
+func (m *MockClient) GetOHLCHistory(pairId, timeFrame string, from, to int64) (*[]indodax.OHLC, error) {
+	m.record("GetOHLCHistory", []interface{}{pairId, timeFrame, from, to})
+
+	if m.GetOHLCHistoryFunc != nil {
+		return m.GetOHLCHistoryFunc(pairId, timeFrame, from, to)
+	}
+
+	result, err := m.response("GetOHLCHistory")
+	if result == nil {
+		return nil, err
+	}
+
+	typed, ok := result.(*[]indodax.OHLC)
+	if !ok {
+		return nil, fmt.Errorf("mock response for GetOHLCHistory has type %T", result)
+	}
+
+	return typed, err
+}
+
+func (m *MockClient) GetTradeHistory(pair string, fromId, toId, order *string, since, end, count *int64, orderId *string) (*indodax.GetTradeHistoryResponseBody, error) {
+	m.record("GetTradeHistory", []interface{}{pair, fromId, toId, order, since, end, count, orderId})
+
+	if m.GetTradeHistoryFunc != nil {
+		return m.GetTradeHistoryFunc(pair, fromId, toId, order, since, end, count, orderId)
+	}
+
+	result, err := m.response("GetTradeHistory")
+	if result == nil {
+		return nil, err
+	}
+
+	typed, ok := result.(*indodax.GetTradeHistoryResponseBody)
+	if !ok {
+		return nil, fmt.Errorf("mock response for GetTradeHistory has type %T", result)
+	}
+
+	return typed, err
+}
+
+func (m *MockClient) GetOpenOrders(pair *string) (interface{}, error) {
+	m.record("GetOpenOrders", []interface{}{pair})
+
+	if m.GetOpenOrdersFunc != nil {
+		return m.GetOpenOrdersFunc(pair)
+	}
+
+	return m.response("GetOpenOrders")
+}
+
+func (m *MockClient) GetOrderHistory(pair string, count, from *int) (*indodax.GetOrderHistoryResponseBody, error) {
+	m.record("GetOrderHistory", []interface{}{pair, count, from})
+
+	if m.GetOrderHistoryFunc != nil {
+		return m.GetOrderHistoryFunc(pair, count, from)
+	}
+
+	result, err := m.response("GetOrderHistory")
+	if result == nil {
+		return nil, err
+	}
+
+	typed, ok := result.(*indodax.GetOrderHistoryResponseBody)
+	if !ok {
+		return nil, fmt.Errorf("mock response for GetOrderHistory has type %T", result)
+	}
+
+	return typed, err
+}
+
+func (m *MockClient) GetOrder(pair, orderId string) (*indodax.GetOrderResponseBody, error) {
+	m.record("GetOrder", []interface{}{pair, orderId})
+
+	if m.GetOrderFunc != nil {
+		return m.GetOrderFunc(pair, orderId)
+	}
+
+	result, err := m.response("GetOrder")
+	if result == nil {
+		return nil, err
+	}
+
+	typed, ok := result.(*indodax.GetOrderResponseBody)
+	if !ok {
+		return nil, fmt.Errorf("mock response for GetOrder has type %T", result)
+	}
+
+	return typed, err
+}
+
+func (m *MockClient) GetOrderByClientOrderId(clientOrderId string) (*indodax.GetOrderResponseBody, error) {
+	m.record("GetOrderByClientOrderId", []interface{}{clientOrderId})
+
+	if m.GetOrderByClientOrderIdFunc != nil {
+		return m.GetOrderByClientOrderIdFunc(clientOrderId)
+	}
+
+	result, err := m.response("GetOrderByClientOrderId")
+	if result == nil {
+		return nil, err
+	}
+
+	typed, ok := result.(*indodax.GetOrderResponseBody)
+	if !ok {
+		return nil, fmt.Errorf("mock response for GetOrderByClientOrderId has type %T", result)
+	}
+
+	return typed, err
+}
+
+func (m *MockClient) Trade(tradeType, pair, orderType string, price, amount float64, timeInForce, clientOrderId *string, forceCoinAmount bool) (*indodax.ResponseBody, error) {
+	m.record("Trade", []interface{}{tradeType, pair, orderType, price, amount, timeInForce, clientOrderId, forceCoinAmount})
+
+	if m.TradeFunc != nil {
+		return m.TradeFunc(tradeType, pair, orderType, price, amount, timeInForce, clientOrderId, forceCoinAmount)
+	}
+
+	result, err := m.response("Trade")
+	if result == nil {
+		return nil, err
+	}
+
+	typed, ok := result.(*indodax.ResponseBody)
+	if !ok {
+		return nil, fmt.Errorf("mock response for Trade has type %T", result)
+	}
+
+	return typed, err
+}
+
+func (m *MockClient) CancelOrder(pair, orderId, tradeType string, orderType *string) (*map[string]interface{}, error) {
+	m.record("CancelOrder", []interface{}{pair, orderId, tradeType, orderType})
+
+	if m.CancelOrderFunc != nil {
+		return m.CancelOrderFunc(pair, orderId, tradeType, orderType)
+	}
+
+	result, err := m.response("CancelOrder")
+	if result == nil {
+		return nil, err
+	}
+
+	typed, ok := result.(*map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("mock response for CancelOrder has type %T", result)
+	}
+
+	return typed, err
+}
+
+func (m *MockClient) CancelOrderByClientOrderId(clientOrderId string) (*map[string]interface{}, error) {
+	m.record("CancelOrderByClientOrderId", []interface{}{clientOrderId})
+
+	if m.CancelOrderByClientOrderIdFunc != nil {
+		return m.CancelOrderByClientOrderIdFunc(clientOrderId)
+	}
+
+	result, err := m.response("CancelOrderByClientOrderId")
+	if result == nil {
+		return nil, err
+	}
+
+	typed, ok := result.(*map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("mock response for CancelOrderByClientOrderId has type %T", result)
+	}
+
+	return typed, err
+}
+
+func (m *MockClient) GetInfo() (*indodax.GetInfoResponseBody, error) {
+	m.record("GetInfo", nil)
+
+	if m.GetInfoFunc != nil {
+		return m.GetInfoFunc()
+	}
+
+	result, err := m.response("GetInfo")
+	if result == nil {
+		return nil, err
+	}
+
+	typed, ok := result.(*indodax.GetInfoResponseBody)
+	if !ok {
+		return nil, fmt.Errorf("mock response for GetInfo has type %T", result)
+	}
+
+	return typed, err
+}
+
+func (m *MockClient) GetTransactionHistory(fromDate, toDate string) (*indodax.GetTransactionHistoryResponseBody, error) {
+	m.record("GetTransactionHistory", []interface{}{fromDate, toDate})
+
+	if m.GetTransactionHistoryFunc != nil {
+		return m.GetTransactionHistoryFunc(fromDate, toDate)
+	}
+
+	result, err := m.response("GetTransactionHistory")
+	if result == nil {
+		return nil, err
+	}
+
+	typed, ok := result.(*indodax.GetTransactionHistoryResponseBody)
+	if !ok {
+		return nil, fmt.Errorf("mock response for GetTransactionHistory has type %T", result)
+	}
+
+	return typed, err
+}
+
+func (m *MockClient) Withdraw(requestId, currency, address, network, amount, memo string) (*indodax.WithdrawCoinResponseBody, error) {
+	m.record("Withdraw", []interface{}{requestId, currency, address, network, amount, memo})
+
+	if m.WithdrawFunc != nil {
+		return m.WithdrawFunc(requestId, currency, address, network, amount, memo)
+	}
+
+	result, err := m.response("Withdraw")
+	if result == nil {
+		return nil, err
+	}
+
+	typed, ok := result.(*indodax.WithdrawCoinResponseBody)
+	if !ok {
+		return nil, fmt.Errorf("mock response for Withdraw has type %T", result)
+	}
+
+	return typed, err
+}
+
+func (m *MockClient) GetWithdrawFee(currency string, coinNetwork *string) (*map[string]interface{}, error) {
+	m.record("GetWithdrawFee", []interface{}{currency, coinNetwork})
+
+	if m.GetWithdrawFeeFunc != nil {
+		return m.GetWithdrawFeeFunc(currency, coinNetwork)
+	}
+
+	result, err := m.response("GetWithdrawFee")
+	if result == nil {
+		return nil, err
+	}
+
+	typed, ok := result.(*map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("mock response for GetWithdrawFee has type %T", result)
+	}
+
+	return typed, err
+}
+
+func (m *MockClient) PrivateApiCall(method string, data *map[string]interface{}) (*indodax.ResponseBody, error) {
+	m.record("PrivateApiCall", []interface{}{method, data})
+
+	if m.PrivateApiCallFunc != nil {
+		return m.PrivateApiCallFunc(method, data)
+	}
+
+	result, err := m.response("PrivateApiCall")
+	if result == nil {
+		return nil, err
+	}
+
+	typed, ok := result.(*indodax.ResponseBody)
+	if !ok {
+		return nil, fmt.Errorf("mock response for PrivateApiCall has type %T", result)
+	}
+
+	return typed, err
+}
+
+func (m *MockClient) PrivateApiCallWithCustomResult(method string, data *map[string]interface{}, result interface{}) error {
+	m.record("PrivateApiCallWithCustomResult", []interface{}{method, data, result})
+
+	if m.PrivateApiCallWithCustomResultFunc != nil {
+		return m.PrivateApiCallWithCustomResultFunc(method, data, result)
+	}
+
+	_, err := m.response("PrivateApiCallWithCustomResult")
+
+	return err
+}
+
+func (m *MockClient) record(method string, args []interface{}) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.calls = append(m.calls, MockCall{Method: method, Args: args})
+}
+
+func (m *MockClient) response(method string) (interface{}, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	queue := m.responses[method]
+	if len(queue) == 0 {
+		return nil, fmt.Errorf("%w for %s", ErrNoMockResponse, method)
+	}
+
+	response := queue[0]
+
+	if len(queue) > 1 {
+		m.responses[method] = queue[1:]
+	}
+
+	return response.Result, response.Err
+}
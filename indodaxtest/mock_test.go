@@ -0,0 +1,92 @@
+package indodaxtest
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/vannleonheart/indodax-api-go"
+)
+
+func TestMockClientPublicAPI(t *testing.T) {
+	mock := NewMockClient()
+
+	var api indodax.PublicAPI = mock
+
+	if _, err := api.GetTicker("btcidr"); !errors.Is(err, ErrNoMockResponse) {
+		t.Fatalf("expected ErrNoMockResponse without a response, got %v", err)
+	}
+
+	first := &indodax.GetTickerResponseBody{}
+	second := &indodax.GetTickerResponseBody{}
+
+	mock.On("GetTicker", first, nil).On("GetTicker", second, nil)
+
+	for i, want := range []*indodax.GetTickerResponseBody{first, second, second} {
+		got, err := api.GetTicker("btcidr")
+		if err != nil || got != want {
+			t.Fatalf("call %d: expected queued response %p, got %p %v", i, want, got, err)
+		}
+	}
+
+	calls := mock.CallsTo("GetTicker")
+	if len(calls) != 4 || calls[0].Args[0] != "btcidr" {
+		t.Fatalf("expected 4 recorded calls with their pair, got %+v", calls)
+	}
+
+	mock.On("GetPairs", &indodax.GetTickerResponseBody{}, nil)
+
+	if _, err := api.GetPairs(); err == nil {
+		t.Fatal("expected a response of the wrong type to fail")
+	}
+}
+
+func TestMockClientTradingAPI(t *testing.T) {
+	mock := NewMockClient()
+
+	var api indodax.TradingAPI = mock
+
+	rejected := errors.New("insufficient balance")
+
+	mock.On("Trade", nil, rejected)
+
+	if _, err := api.Trade(indodax.TradeTypeBuy, "btc_idr", indodax.OrderTypeLimit, 100, 1, nil, nil, false); !errors.Is(err, rejected) {
+		t.Fatalf("expected the stubbed error, got %v", err)
+	}
+
+	mock.CancelOrderFunc = func(pair, orderId, tradeType string, orderType *string) (*map[string]interface{}, error) {
+		return &map[string]interface{}{"order_id": orderId}, nil
+	}
+
+	resp, err := api.CancelOrder("btc_idr", "42", indodax.TradeTypeBuy, nil)
+	if err != nil || (*resp)["order_id"] != "42" {
+		t.Fatalf("expected the function to answer, got %v %v", resp, err)
+	}
+
+	calls := mock.Calls()
+	if len(calls) != 2 || calls[0].Method != "Trade" || calls[0].Args[1] != "btc_idr" || calls[1].Method != "CancelOrder" {
+		t.Fatalf("unexpected calls %+v", calls)
+	}
+}
+
+func TestMockClientWalletAPI(t *testing.T) {
+	mock := NewMockClient()
+
+	var api indodax.WalletAPI = mock
+
+	mock.On("GetInfo", &indodax.GetInfoResponseBody{Name: "test"}, nil)
+
+	info, err := api.GetInfo()
+	if err != nil || info.Name != "test" {
+		t.Fatalf("expected the stubbed response, got %+v %v", info, err)
+	}
+
+	mock.Reset()
+
+	if len(mock.Calls()) != 0 {
+		t.Fatalf("expected Reset to clear calls, got %+v", mock.Calls())
+	}
+
+	if _, err = api.GetInfo(); !errors.Is(err, ErrNoMockResponse) {
+		t.Fatalf("expected Reset to clear responses, got %v", err)
+	}
+}
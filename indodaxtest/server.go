@@ -0,0 +1,1145 @@
+package indodaxtest
+
+import (
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vannleonheart/indodax-api-go"
+)
+
+const maxHistoryCount = 1000
+
+type Server struct {
+	*httptest.Server
+
+	ApiKey    string
+	ApiSecret string
+
+	mu           sync.Mutex
+	balances     map[string]float64
+	holds        map[string]float64
+	addresses    map[string]string
+	tickers      map[string]Ticker
+	pairs        []indodax.Pair
+	depths       map[string]indodax.GetDepthResponseBody
+	trades       map[string][]indodax.Trade
+	ohlc         map[string][]indodax.OHLC
+	orders       []*Order
+	ownTrades    []ownTrade
+	deposits     []*Transfer
+	withdrawals  []*Transfer
+	withdrawFees map[string]float64
+	failures     map[string]*Failure
+	latencies    map[string]time.Duration
+	sequence     int64
+}
+
+func NewServer(apiKey, apiSecret string) *Server {
+	s := &Server{
+		ApiKey:       apiKey,
+		ApiSecret:    apiSecret,
+		balances:     map[string]float64{},
+		holds:        map[string]float64{},
+		addresses:    map[string]string{},
+		tickers:      map[string]Ticker{},
+		depths:       map[string]indodax.GetDepthResponseBody{},
+		trades:       map[string][]indodax.Trade{},
+		ohlc:         map[string][]indodax.OHLC{},
+		withdrawFees: map[string]float64{},
+		failures:     map[string]*Failure{},
+		latencies:    map[string]time.Duration{},
+		sequence:     1000,
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/", s.handlePublic)
+	mux.HandleFunc("/tradingview/history_v2", s.handleOHLC)
+	mux.HandleFunc("/tapi", s.handlePrivate)
+
+	s.Server = httptest.NewServer(mux)
+
+	return s
+}
+
+func (s *Server) Client() *indodax.Client {
+	return indodax.New(indodax.Config{
+		PublicApiBaseUrl:  s.URL,
+		PrivateApiBaseUrl: s.URL,
+	}).WithCredential(s.ApiKey, s.ApiSecret)
+}
+
+func (s *Server) SetBalance(currency string, amount float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.balances[strings.ToLower(currency)] = amount
+}
+
+func (s *Server) Balance(currency string) (float64, float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	currency = strings.ToLower(currency)
+
+	return s.balances[currency], s.holds[currency]
+}
+
+func (s *Server) SetAddress(currency, address string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.addresses[strings.ToLower(currency)] = address
+}
+
+func (s *Server) SetTicker(pair string, ticker Ticker) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.tickers[pairKey(pair)] = ticker
+}
+
+func (s *Server) SetPairs(pairs ...indodax.Pair) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.pairs = pairs
+}
+
+func (s *Server) SetDepth(pair string, depth indodax.GetDepthResponseBody) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.depths[pairKey(pair)] = depth
+}
+
+func (s *Server) SetTrades(pair string, trades ...indodax.Trade) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.trades[pairKey(pair)] = trades
+}
+
+func (s *Server) SetOHLC(pair string, bars ...indodax.OHLC) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.ohlc[pairKey(pair)] = bars
+}
+
+func (s *Server) SetWithdrawFee(currency string, fee float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.withdrawFees[strings.ToLower(currency)] = fee
+}
+
+/*
+ * Inject a failure for a tapi method (e.g. "trade") or a public endpoint (e.g. "ticker"),
+ * a failure with zero times is returned until it is cleared
+ *
+ * @param string method
+ * @param Failure failure
+ *
+ * @return void
+ */
+func (s *Server) InjectFailure(method string, failure Failure) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.failures[method] = &failure
+}
+
+func (s *Server) ClearFailure(method string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.failures, method)
+}
+
+func (s *Server) SetLatency(method string, latency time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.latencies[method] = latency
+}
+
+func (s *Server) AddDeposit(currency string, amount float64, txId, status string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	currency = strings.ToLower(currency)
+	now := time.Now()
+
+	deposit := &Transfer{
+		Id:         s.nextId(),
+		Currency:   currency,
+		Amount:     amount,
+		Address:    s.addresses[currency],
+		TxId:       txId,
+		Status:     status,
+		SubmitTime: now,
+		UpdateTime: now,
+	}
+
+	if status == indodax.DepositStatusSuccess {
+		s.balances[currency] += amount
+	}
+
+	s.deposits = append(s.deposits, deposit)
+
+	return deposit.Id
+}
+
+func (s *Server) SetDepositStatus(id, status string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, deposit := range s.deposits {
+		if deposit.Id != id {
+			continue
+		}
+
+		if deposit.Status != indodax.DepositStatusSuccess && status == indodax.DepositStatusSuccess {
+			s.balances[deposit.Currency] += deposit.Amount
+		}
+
+		deposit.Status = status
+		deposit.UpdateTime = time.Now()
+
+		return true
+	}
+
+	return false
+}
+
+func (s *Server) SetWithdrawalStatus(id, status string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, withdrawal := range s.withdrawals {
+		if withdrawal.Id != id {
+			continue
+		}
+
+		if withdrawal.Status != indodax.WithdrawalStatusCancelled && status == indodax.WithdrawalStatusCancelled {
+			s.balances[withdrawal.Currency] += withdrawal.Amount
+		}
+
+		withdrawal.Status = status
+		withdrawal.UpdateTime = time.Now()
+
+		return true
+	}
+
+	return false
+}
+
+func (s *Server) Orders() []Order {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := make([]Order, 0, len(s.orders))
+
+	for _, o := range s.orders {
+		result = append(result, *o)
+	}
+
+	return result
+}
+
+/*
+ * Fill an open order, partially when amount is less than the remaining amount
+ *
+ * @param string orderId
+ * @param float64 amount
+ *
+ * @return error
+ */
+func (s *Server) FillOrder(orderId string, amount float64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	o := s.findOrder(orderId, "")
+	if o == nil || o.Status != "open" {
+		return fmt.Errorf("order %s is not open", orderId)
+	}
+
+	if amount <= 0 || amount > o.Remain {
+		amount = o.Remain
+	}
+
+	s.fill(o, o.Price, amount, s.tradeFee(o.Pair, true))
+
+	return nil
+}
+
+func (s *Server) handlePublic(w http.ResponseWriter, r *http.Request) {
+	segments := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/"), "/"), "/")
+	endpoint := segments[0]
+
+	if s.intercept(w, endpoint) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var pair string
+
+	if len(segments) > 1 {
+		pair = pairKey(segments[1])
+	}
+
+	switch endpoint {
+	case "server_time":
+		writeJson(w, http.StatusOK, indodax.GetServerTimeResponseBody{Timezone: "UTC", ServerTime: time.Now().UnixMilli()})
+	case "pairs":
+		pairs := s.pairs
+		if pairs == nil {
+			pairs = []indodax.Pair{}
+		}
+
+		writeJson(w, http.StatusOK, pairs)
+	case "price_increments":
+		increments := map[string]interface{}{}
+
+		for _, p := range s.pairs {
+			increments[p.Id] = p.PriceRound.String()
+		}
+
+		writeJson(w, http.StatusOK, indodax.GetPriceIncrementsResponseBody{Increments: increments})
+	case "summaries", "ticker_all":
+		tickers := map[string]map[string]interface{}{}
+
+		for key, t := range s.tickers {
+			tickers[pairId(key)] = tickerMap(key, t)
+		}
+
+		writeJson(w, http.StatusOK, map[string]interface{}{"tickers": tickers})
+	case "ticker":
+		t, exist := s.tickers[pair]
+		if !exist {
+			writeJson(w, http.StatusOK, map[string]interface{}{"error": "invalid_pair", "error_description": "Invalid Pair"})
+			return
+		}
+
+		writeJson(w, http.StatusOK, map[string]interface{}{"ticker": tickerMap(pair, t)})
+	case "trades":
+		trades := s.trades[pair]
+		if trades == nil {
+			trades = []indodax.Trade{}
+		}
+
+		writeJson(w, http.StatusOK, trades)
+	case "depth":
+		depth := s.depths[pair]
+
+		if depth.Buy == nil {
+			depth.Buy = [][2]json.Number{}
+		}
+
+		if depth.Sell == nil {
+			depth.Sell = [][2]json.Number{}
+		}
+
+		writeJson(w, http.StatusOK, depth)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (s *Server) handleOHLC(w http.ResponseWriter, r *http.Request) {
+	if s.intercept(w, "ohlc") {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	query := r.URL.Query()
+	from, _ := strconv.ParseInt(query.Get("from"), 10, 64)
+	to, _ := strconv.ParseInt(query.Get("to"), 10, 64)
+
+	result := []indodax.OHLC{}
+
+	for _, bar := range s.ohlc[pairKey(query.Get("symbol"))] {
+		if (from == 0 || bar.Time >= from) && (to == 0 || bar.Time <= to) {
+			result = append(result, bar)
+		}
+	}
+
+	writeJson(w, http.StatusOK, result)
+}
+
+func (s *Server) handlePrivate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	raw, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	form, err := url.ParseQuery(string(raw))
+	if err != nil {
+		writeError(w, "bad_request", err.Error())
+		return
+	}
+
+	if r.Header.Get("Key") != s.ApiKey {
+		writeError(w, "invalid_credentials", "Invalid credentials. API not found or session has expired.")
+		return
+	}
+
+	h := hmac.New(sha512.New, []byte(s.ApiSecret))
+	h.Write(raw)
+
+	if !hmac.Equal([]byte(hex.EncodeToString(h.Sum(nil))), []byte(r.Header.Get("Sign"))) {
+		writeError(w, "invalid_credentials", "Invalid credentials. Bad sign.")
+		return
+	}
+
+	method := form.Get("method")
+
+	if s.intercept(w, method) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch method {
+	case indodax.MethodGetInfo:
+		s.getInfo(w)
+	case indodax.MethodGetTransactionHistory:
+		s.transHistory(w, form)
+	case indodax.MethodGetTradeHistory:
+		s.tradeHistory(w, form)
+	case indodax.MethodGetOpenOrders:
+		s.openOrders(w, form)
+	case indodax.MethodGetOrderHistory:
+		s.orderHistory(w, form)
+	case indodax.MethodGetOrder:
+		s.getOrder(w, form.Get("order_id"), "")
+	case indodax.MethodGetOrderByClientOrderId:
+		s.getOrder(w, "", form.Get("client_order_id"))
+	case indodax.MethodTrade:
+		s.trade(w, form)
+	case indodax.MethodCancelOrder:
+		s.cancelOrder(w, form.Get("order_id"), "")
+	case indodax.MethodCancelOrderByClientOrderId:
+		s.cancelOrder(w, "", form.Get("client_order_id"))
+	case indodax.MethodWithdrawFee:
+		s.withdrawFee(w, form)
+	case indodax.MethodWithdrawCoin:
+		s.withdrawCoin(w, form)
+	default:
+		writeError(w, "invalid_method", fmt.Sprintf("Method %s not found", method))
+	}
+}
+
+/*
+ * Apply injected latency and failure for a method
+ *
+ * @param http.ResponseWriter w
+ * @param string method
+ *
+ * @return bool
+ */
+func (s *Server) intercept(w http.ResponseWriter, method string) bool {
+	s.mu.Lock()
+	latency := s.latencies[method]
+	failure, exist := s.failures[method]
+
+	var f Failure
+
+	if exist {
+		f = *failure
+
+		if failure.Times > 0 {
+			failure.Times--
+
+			if failure.Times == 0 {
+				delete(s.failures, method)
+			}
+		}
+	}
+
+	s.mu.Unlock()
+
+	if latency > 0 {
+		time.Sleep(latency)
+	}
+
+	if !exist {
+		return false
+	}
+
+	if f.Status > 0 && f.Status != http.StatusOK {
+		writeJson(w, f.Status, map[string]interface{}{"success": 0, "error": f.Message, "error_code": f.Code})
+		return true
+	}
+
+	writeError(w, f.Code, f.Message)
+
+	return true
+}
+
+func (s *Server) getInfo(w http.ResponseWriter) {
+	balance := map[string]json.Number{}
+	hold := map[string]json.Number{}
+
+	for currency, amount := range s.balances {
+		balance[currency] = number(amount)
+	}
+
+	for currency, amount := range s.holds {
+		hold[currency] = number(amount)
+	}
+
+	writeReturn(w, indodax.GetInfoResponseBody{
+		UserId:             "1",
+		Name:               "indodaxtest",
+		Email:              "test@example.com",
+		VerificationStatus: "verified",
+		Balance:            balance,
+		BalanceHold:        hold,
+		Address:            s.addresses,
+		ServerTime:         time.Now().Unix(),
+	})
+}
+
+/*
+ * Serve transactions submitted between the start and end dates, like the exchange
+ * the range can not be longer than 7 days
+ *
+ * @param http.ResponseWriter w
+ * @param url.Values form
+ *
+ * @return void
+ */
+func (s *Server) transHistory(w http.ResponseWriter, form url.Values) {
+	var from, to time.Time
+
+	if start := form.Get("start"); len(start) > 0 {
+		parsed, err := time.ParseInLocation(indodax.TransactionHistoryDateLayout, start, time.Local)
+		if err != nil {
+			writeError(w, "invalid_date", "Invalid start date")
+			return
+		}
+
+		from = parsed
+	}
+
+	if end := form.Get("end"); len(end) > 0 {
+		parsed, err := time.ParseInLocation(indodax.TransactionHistoryDateLayout, end, time.Local)
+		if err != nil {
+			writeError(w, "invalid_date", "Invalid end date")
+			return
+		}
+
+		to = parsed.AddDate(0, 0, 1)
+	}
+
+	if !from.IsZero() && !to.IsZero() && (to.Before(from) || to.Sub(from) > indodax.TransactionHistoryMaxRange) {
+		writeError(w, "invalid_date_range", "Date range can not be longer than 7 days")
+		return
+	}
+
+	inRange := func(t time.Time) bool {
+		return (from.IsZero() || !t.Before(from)) && (to.IsZero() || t.Before(to))
+	}
+
+	result := indodax.GetTransactionHistoryResponseBody{
+		Withdraw: map[string][]map[string]interface{}{},
+		Deposit:  map[string][]map[string]interface{}{},
+	}
+
+	for _, d := range s.deposits {
+		if !inRange(d.SubmitTime) {
+			continue
+		}
+
+		result.Deposit[d.Currency] = append(result.Deposit[d.Currency], map[string]interface{}{
+			"status":       d.Status,
+			d.Currency:     number(d.Amount),
+			"amount":       number(d.Amount),
+			"fee":          number(d.Fee),
+			"submit_time":  strconv.FormatInt(d.SubmitTime.Unix(), 10),
+			"success_time": strconv.FormatInt(d.UpdateTime.Unix(), 10),
+			"deposit_id":   d.Id,
+			"tx":           d.TxId,
+		})
+	}
+
+	for _, wd := range s.withdrawals {
+		if !inRange(wd.SubmitTime) {
+			continue
+		}
+
+		result.Withdraw[wd.Currency] = append(result.Withdraw[wd.Currency], map[string]interface{}{
+			"status":       wd.Status,
+			wd.Currency:    number(wd.Amount),
+			"amount":       number(wd.Amount - wd.Fee),
+			"fee":          number(wd.Fee),
+			"submit_time":  strconv.FormatInt(wd.SubmitTime.Unix(), 10),
+			"success_time": strconv.FormatInt(wd.UpdateTime.Unix(), 10),
+			"withdraw_id":  wd.Id,
+			"tx":           wd.TxId,
+		})
+	}
+
+	writeReturn(w, result)
+}
+
+/*
+ * Serve own trades of a pair filtered by trade id, time and order, newest first unless
+ * order is asc, and at most count trades
+ *
+ * @param http.ResponseWriter w
+ * @param url.Values form
+ *
+ * @return void
+ */
+func (s *Server) tradeHistory(w http.ResponseWriter, form url.Values) {
+	pair := pairId(form.Get("pair"))
+	coin, _ := splitPair(pair)
+	since, _ := strconv.ParseInt(form.Get("since"), 10, 64)
+	end, _ := strconv.ParseInt(form.Get("end"), 10, 64)
+	fromId, _ := strconv.ParseInt(form.Get("from_id"), 10, 64)
+	endId, _ := strconv.ParseInt(form.Get("end_id"), 10, 64)
+
+	count, err := strconv.Atoi(form.Get("count"))
+	if err != nil || count <= 0 || count > maxHistoryCount {
+		count = maxHistoryCount
+	}
+
+	var matched []ownTrade
+
+	for _, t := range s.ownTrades {
+		if t.pair != pair || (since > 0 && t.time.Unix() < since) || (end > 0 && t.time.Unix() > end) {
+			continue
+		}
+
+		id, _ := strconv.ParseInt(t.tradeId, 10, 64)
+		if (fromId > 0 && id < fromId) || (endId > 0 && id > endId) {
+			continue
+		}
+
+		if orderId := form.Get("order_id"); len(orderId) > 0 && orderId != t.orderId {
+			continue
+		}
+
+		matched = append(matched, t)
+	}
+
+	if form.Get("order") != "asc" {
+		for i, j := 0, len(matched)-1; i < j; i, j = i+1, j-1 {
+			matched[i], matched[j] = matched[j], matched[i]
+		}
+	}
+
+	if len(matched) > count {
+		matched = matched[:count]
+	}
+
+	trades := []map[string]interface{}{}
+
+	for _, t := range matched {
+		trades = append(trades, map[string]interface{}{
+			"trade_id":        t.tradeId,
+			"order_id":        t.orderId,
+			"client_order_id": t.clientOrderId,
+			"type":            t.tradeType,
+			coin:              number(t.amount),
+			"price":           number(t.price),
+			"fee":             number(t.fee),
+			"trade_time":      strconv.FormatInt(t.time.Unix(), 10),
+		})
+	}
+
+	writeReturn(w, indodax.GetTradeHistoryResponseBody{Trades: trades})
+}
+
+func (s *Server) openOrders(w http.ResponseWriter, form url.Values) {
+	pair := form.Get("pair")
+
+	if len(pair) > 0 {
+		orders := []map[string]interface{}{}
+
+		for _, o := range s.orders {
+			if o.Status == "open" && o.Pair == pairId(pair) {
+				orders = append(orders, orderMap(o))
+			}
+		}
+
+		writeReturn(w, indodax.GetPairOpenOrdersResponseBody{Orders: orders})
+		return
+	}
+
+	orders := map[string][]map[string]interface{}{}
+
+	for _, o := range s.orders {
+		if o.Status == "open" {
+			orders[o.Pair] = append(orders[o.Pair], orderMap(o))
+		}
+	}
+
+	writeReturn(w, indodax.GetOpenOrdersResponseBody{Orders: orders})
+}
+
+func (s *Server) orderHistory(w http.ResponseWriter, form url.Values) {
+	pair := pairId(form.Get("pair"))
+	orders := []map[string]interface{}{}
+
+	count, err := strconv.Atoi(form.Get("count"))
+	if err != nil || count <= 0 || count > maxHistoryCount {
+		count = maxHistoryCount
+	}
+
+	offset, _ := strconv.Atoi(form.Get("from"))
+
+	for i := len(s.orders) - 1; i >= 0; i-- {
+		if s.orders[i].Pair == pair {
+			orders = append(orders, orderMap(s.orders[i]))
+		}
+	}
+
+	if offset > len(orders) {
+		offset = len(orders)
+	}
+
+	orders = orders[offset:]
+
+	if len(orders) > count {
+		orders = orders[:count]
+	}
+
+	writeReturn(w, indodax.GetOrderHistoryResponseBody{Orders: orders})
+}
+
+func (s *Server) getOrder(w http.ResponseWriter, orderId, clientOrderId string) {
+	o := s.findOrder(orderId, clientOrderId)
+	if o == nil {
+		writeError(w, "order_not_found", "Order not found")
+		return
+	}
+
+	writeReturn(w, indodax.GetOrderResponseBody{Order: orderMap(o)})
+}
+
+func (s *Server) trade(w http.ResponseWriter, form url.Values) {
+	pair := pairId(form.Get("pair"))
+
+	coin, currency := splitPair(pair)
+	if len(coin) == 0 {
+		writeError(w, "invalid_pair", "Invalid pair")
+		return
+	}
+
+	clientOrderId := form.Get("client_order_id")
+	if len(clientOrderId) > 0 && s.findOrder("", clientOrderId) != nil {
+		writeError(w, "duplicate_client_order_id", "Duplicate client order id")
+		return
+	}
+
+	tradeType := form.Get("type")
+	orderType := form.Get("order_type")
+	ticker := s.tickers[pairKey(pair)]
+
+	o := &Order{
+		OrderId:       s.nextId(),
+		ClientOrderId: clientOrderId,
+		Pair:          pair,
+		Type:          tradeType,
+		OrderType:     orderType,
+		Status:        "open",
+		SubmitTime:    time.Now(),
+	}
+
+	if len(o.OrderType) == 0 {
+		o.OrderType = indodax.OrderTypeLimit
+	}
+
+	price, _ := strconv.ParseFloat(form.Get("price"), 64)
+	amount, _ := strconv.ParseFloat(form.Get(coin), 64)
+
+	if o.OrderType == indodax.OrderTypeMarket {
+		price = ticker.Sell
+		if tradeType == indodax.TradeTypeSell {
+			price = ticker.Buy
+		}
+
+		if price <= 0 {
+			price = ticker.Last
+		}
+
+		if spend, err := strconv.ParseFloat(form.Get(currency), 64); err == nil && spend > 0 && tradeType == indodax.TradeTypeBuy && price > 0 {
+			amount = spend / price
+		}
+	}
+
+	if price <= 0 || amount <= 0 {
+		writeError(w, "invalid_order", "Invalid price or amount")
+		return
+	}
+
+	o.Price = price
+	o.Amount = amount
+	o.Remain = amount
+
+	o.holdFee = s.tradeFee(pair, false)
+
+	holdCurrency, holdAmount := coin, amount
+	if tradeType == indodax.TradeTypeBuy {
+		holdCurrency, holdAmount = currency, price*amount*(1+o.holdFee)
+	} else if tradeType != indodax.TradeTypeSell {
+		writeError(w, "invalid_type", "Invalid trade type")
+		return
+	}
+
+	if s.balances[holdCurrency] < holdAmount {
+		writeError(w, "insufficient_balance", "Insufficient balance.")
+		return
+	}
+
+	s.balances[holdCurrency] -= holdAmount
+	s.holds[holdCurrency] += holdAmount
+	s.orders = append(s.orders, o)
+
+	crosses := (tradeType == indodax.TradeTypeBuy && ticker.Sell > 0 && price >= ticker.Sell) ||
+		(tradeType == indodax.TradeTypeSell && ticker.Buy > 0 && price <= ticker.Buy)
+
+	var fee float64
+
+	if o.OrderType == indodax.OrderTypeMarket || crosses {
+		fee = s.fill(o, price, amount, s.tradeFee(pair, false))
+	}
+
+	ret := map[string]interface{}{
+		"order_id":                     o.OrderId,
+		"client_order_id":              o.ClientOrderId,
+		fmt.Sprintf("remain_%s", coin): number(o.Remain),
+		"balance":                      s.balanceMap(),
+	}
+
+	if tradeType == indodax.TradeTypeBuy {
+		ret[fmt.Sprintf("receive_%s", coin)] = number(amount - o.Remain)
+		ret[fmt.Sprintf("spend_%s", currency)] = number(price*(amount-o.Remain) + fee)
+	} else {
+		ret[fmt.Sprintf("sold_%s", coin)] = number(amount - o.Remain)
+		ret[fmt.Sprintf("receive_%s", currency)] = number(price*(amount-o.Remain) - fee)
+	}
+
+	writeReturn(w, ret)
+}
+
+func (s *Server) cancelOrder(w http.ResponseWriter, orderId, clientOrderId string) {
+	o := s.findOrder(orderId, clientOrderId)
+	if o == nil || o.Status != "open" {
+		writeError(w, "order_not_found", "Order not found or already finished")
+		return
+	}
+
+	coin, currency := splitPair(o.Pair)
+
+	if o.Type == indodax.TradeTypeBuy {
+		s.release(currency, o.Price*o.Remain*(1+o.holdFee))
+	} else {
+		s.release(coin, o.Remain)
+	}
+
+	o.Status = "cancelled"
+	o.FinishTime = time.Now()
+
+	writeReturn(w, map[string]interface{}{
+		"order_id":        o.OrderId,
+		"client_order_id": o.ClientOrderId,
+		"type":            o.Type,
+		"pair":            o.Pair,
+		"balance":         s.balanceMap(),
+	})
+}
+
+func (s *Server) withdrawFee(w http.ResponseWriter, form url.Values) {
+	currency := strings.ToLower(form.Get("currency"))
+
+	writeReturn(w, map[string]interface{}{
+		"server_time":  time.Now().Unix(),
+		"withdraw_fee": number(s.withdrawFees[currency]),
+		"currency":     currency,
+	})
+}
+
+func (s *Server) withdrawCoin(w http.ResponseWriter, form url.Values) {
+	currency := strings.ToLower(form.Get("currency"))
+
+	amount, err := strconv.ParseFloat(form.Get("withdraw_amount"), 64)
+	if err != nil || amount <= 0 {
+		writeError(w, "invalid_amount", "Invalid withdraw amount")
+		return
+	}
+
+	if s.balances[currency] < amount {
+		writeError(w, "insufficient_balance", "Insufficient balance.")
+		return
+	}
+
+	now := time.Now()
+	fee := s.withdrawFees[currency]
+
+	withdrawal := &Transfer{
+		Id:         s.nextId(),
+		Currency:   currency,
+		Amount:     amount,
+		Fee:        fee,
+		Address:    form.Get("withdraw_address"),
+		TxId:       fmt.Sprintf("tx-%s", form.Get("request_id")),
+		Status:     indodax.WithdrawalStatusPending,
+		SubmitTime: now,
+		UpdateTime: now,
+	}
+
+	s.balances[currency] -= amount
+	s.withdrawals = append(s.withdrawals, withdrawal)
+
+	writeJson(w, http.StatusOK, map[string]interface{}{
+		"success":           1,
+		"status":            "approved",
+		"withdraw_currency": currency,
+		"withdraw_address":  withdrawal.Address,
+		"withdraw_amount":   number(amount),
+		"fee":               number(fee),
+		"amount_after_fee":  number(amount - fee),
+		"submit_time":       strconv.FormatInt(now.Unix(), 10),
+		"withdraw_id":       withdrawal.Id,
+		"tx_id":             withdrawal.TxId,
+	})
+}
+
+/*
+ * Fill part of an order and settle balances, the fee is charged in the quote currency
+ *
+ * @param *Order o
+ * @param float64 price
+ * @param float64 amount
+ * @param float64 feeRate
+ *
+ * @return float64 fee
+ */
+func (s *Server) fill(o *Order, price, amount, feeRate float64) float64 {
+	coin, currency := splitPair(o.Pair)
+	fee := price * amount * feeRate
+
+	if o.Type == indodax.TradeTypeBuy {
+		held := o.Price * amount * (1 + o.holdFee)
+
+		s.holds[currency] -= held
+		s.balances[currency] += held - price*amount - fee
+		s.balances[coin] += amount
+	} else {
+		s.holds[coin] -= amount
+		s.balances[currency] += price*amount - fee
+	}
+
+	o.Remain -= amount
+
+	if o.Remain <= 1e-12 {
+		o.Remain = 0
+		o.Status = "filled"
+		o.FinishTime = time.Now()
+	}
+
+	s.ownTrades = append(s.ownTrades, ownTrade{
+		tradeId:       s.nextId(),
+		orderId:       o.OrderId,
+		clientOrderId: o.ClientOrderId,
+		pair:          o.Pair,
+		tradeType:     o.Type,
+		price:         price,
+		amount:        amount,
+		fee:           fee,
+		time:          time.Now(),
+	})
+
+	return fee
+}
+
+/*
+ * Fee rate of a pair set with SetPairs, resting orders pay the maker fee and
+ * orders filled on submission the taker fee
+ *
+ * @param string pair
+ * @param bool maker
+ *
+ * @return float64
+ */
+func (s *Server) tradeFee(pair string, maker bool) float64 {
+	for _, p := range s.pairs {
+		if pairKey(p.Id) != pairKey(pair) && pairKey(p.TickerId) != pairKey(pair) {
+			continue
+		}
+
+		percent := p.TradeFeePercentTaker
+		if maker {
+			percent = p.TradeFeePercentMaker
+		}
+
+		if len(percent) == 0 {
+			percent = p.TradeFeePercent
+		}
+
+		rate, _ := percent.Float64()
+
+		return rate / 100
+	}
+
+	return 0
+}
+
+func (s *Server) release(currency string, amount float64) {
+	s.holds[currency] -= amount
+	s.balances[currency] += amount
+}
+
+func (s *Server) findOrder(orderId, clientOrderId string) *Order {
+	for _, o := range s.orders {
+		if (len(orderId) > 0 && o.OrderId == orderId) || (len(clientOrderId) > 0 && o.ClientOrderId == clientOrderId) {
+			return o
+		}
+	}
+
+	return nil
+}
+
+func (s *Server) balanceMap() map[string]json.Number {
+	result := map[string]json.Number{}
+
+	for currency, amount := range s.balances {
+		result[currency] = number(amount)
+	}
+
+	return result
+}
+
+func (s *Server) nextId() string {
+	s.sequence++
+
+	return strconv.FormatInt(s.sequence, 10)
+}
+
+func orderMap(o *Order) map[string]interface{} {
+	coin, _ := splitPair(o.Pair)
+
+	result := map[string]interface{}{
+		"order_id":                     o.OrderId,
+		"client_order_id":              o.ClientOrderId,
+		"pair":                         o.Pair,
+		"type":                         o.Type,
+		"order_type":                   o.OrderType,
+		"price":                        number(o.Price),
+		"status":                       o.Status,
+		"submit_time":                  strconv.FormatInt(o.SubmitTime.Unix(), 10),
+		"finish_time":                  "0",
+		fmt.Sprintf("order_%s", coin):  number(o.Amount),
+		fmt.Sprintf("remain_%s", coin): number(o.Remain),
+	}
+
+	if !o.FinishTime.IsZero() {
+		result["finish_time"] = strconv.FormatInt(o.FinishTime.Unix(), 10)
+	}
+
+	return result
+}
+
+func tickerMap(pair string, t Ticker) map[string]interface{} {
+	coin, currency := splitPair(pairId(pair))
+
+	return map[string]interface{}{
+		"name":                          strings.ToUpper(coin),
+		"last":                          number(t.Last),
+		"buy":                           number(t.Buy),
+		"sell":                          number(t.Sell),
+		"high":                          number(t.High),
+		"low":                           number(t.Low),
+		fmt.Sprintf("vol_%s", coin):     "0",
+		fmt.Sprintf("vol_%s", currency): "0",
+		"server_time":                   time.Now().Unix(),
+	}
+}
+
+func writeReturn(w http.ResponseWriter, ret interface{}) {
+	writeJson(w, http.StatusOK, map[string]interface{}{"success": 1, "return": ret})
+}
+
+func writeError(w http.ResponseWriter, code, message string) {
+	writeJson(w, http.StatusOK, map[string]interface{}{"success": 0, "error": message, "error_code": code})
+}
+
+func writeJson(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func number(v float64) json.Number {
+	return json.Number(strconv.FormatFloat(v, 'f', -1, 64))
+}
+
+/*
+ * Normalize pair to the key used by ticker, depth and trades endpoints, e.g. btcidr
+ *
+ * @param string pair
+ *
+ * @return string
+ */
+func pairKey(pair string) string {
+	return strings.ReplaceAll(strings.ToLower(pair), "_", "")
+}
+
+/*
+ * Normalize pair to the id used by private endpoints, e.g. btc_idr
+ *
+ * @param string pair
+ *
+ * @return string
+ */
+func pairId(pair string) string {
+	pair = strings.ToLower(pair)
+
+	if strings.Contains(pair, "_") {
+		return pair
+	}
+
+	for _, quote := range []string{"idr", "usdt", "btc"} {
+		if strings.HasSuffix(pair, quote) && len(pair) > len(quote) {
+			return fmt.Sprintf("%s_%s", strings.TrimSuffix(pair, quote), quote)
+		}
+	}
+
+	return pair
+}
+
+func splitPair(pair string) (string, string) {
+	slPair := strings.Split(pair, "_")
+	if len(slPair) != 2 {
+		return "", ""
+	}
+
+	return slPair[0], slPair[1]
+}
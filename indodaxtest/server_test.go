@@ -0,0 +1,153 @@
+package indodaxtest
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/vannleonheart/indodax-api-go"
+)
+
+func TestServerTradeHistoryPagination(t *testing.T) {
+	server := NewServer("key", "secret")
+	defer server.Close()
+
+	start := time.Now().Add(-time.Hour)
+
+	server.mu.Lock()
+	for i := 0; i < 2500; i++ {
+		server.ownTrades = append(server.ownTrades, ownTrade{
+			tradeId:   server.nextId(),
+			orderId:   "1",
+			pair:      "btc_idr",
+			tradeType: indodax.TradeTypeBuy,
+			price:     100,
+			amount:    1,
+			time:      start.Add(time.Duration(i) * time.Millisecond),
+		})
+	}
+	server.mu.Unlock()
+
+	done := make(chan struct{})
+
+	var trades []indodax.OwnTrade
+	var err error
+
+	go func() {
+		defer close(done)
+		trades, err = server.Client().GetOwnTrades("btc_idr", nil, nil)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("trade history pagination did not terminate")
+	}
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(trades) != 2500 {
+		t.Fatalf("expected 2500 trades, got %d", len(trades))
+	}
+
+	seen := map[string]bool{}
+	for _, trade := range trades {
+		if seen[trade.TradeId] {
+			t.Fatalf("trade %s returned twice", trade.TradeId)
+		}
+
+		seen[trade.TradeId] = true
+	}
+}
+
+func TestServerTransactionHistoryRange(t *testing.T) {
+	server := NewServer("key", "secret")
+	defer server.Close()
+
+	id := server.AddDeposit("btc", 1, "tx-1", indodax.DepositStatusSuccess)
+
+	server.mu.Lock()
+	server.deposits[0].SubmitTime = time.Now().AddDate(0, 0, -20)
+	server.mu.Unlock()
+
+	client := server.Client()
+	now := time.Now()
+
+	recent, err := client.GetTransactionHistory(now.AddDate(0, 0, -6).Format(indodax.TransactionHistoryDateLayout), now.Format(indodax.TransactionHistoryDateLayout))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(recent.Deposit["btc"]) != 0 {
+		t.Fatalf("expected no deposit in the last week, got %v", recent.Deposit)
+	}
+
+	if _, err = client.GetTransactionHistory(now.AddDate(0, 0, -30).Format(indodax.TransactionHistoryDateLayout), now.Format(indodax.TransactionHistoryDateLayout)); err == nil {
+		t.Fatal("expected a range longer than 7 days to be rejected")
+	}
+
+	history, err := client.GetTransactionHistoryRange(now.AddDate(0, 0, -30), now)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if deposits := history.Deposit["btc"]; len(deposits) != 1 || deposits[0]["deposit_id"] != id {
+		t.Fatalf("expected the deposit in the 30 day range, got %v", history.Deposit)
+	}
+}
+
+func TestServerChargesTradeFees(t *testing.T) {
+	server := NewServer("key", "secret")
+	defer server.Close()
+
+	server.SetPairs(indodax.Pair{Id: "btcidr", TickerId: "btc_idr", TradeFeePercentTaker: "0.3", TradeFeePercentMaker: "0.1"})
+	server.SetTicker("btc_idr", Ticker{Last: 100, Buy: 99, Sell: 100})
+	server.SetBalance("idr", 1000)
+	server.SetBalance("btc", 1)
+
+	client := server.Client()
+
+	if _, err := client.Trade(indodax.TradeTypeBuy, "btc_idr", indodax.OrderTypeLimit, 100, 1, nil, nil, true); err != nil {
+		t.Fatal(err)
+	}
+
+	if idr, _ := server.Balance("idr"); math.Abs(idr-899.7) > 1e-9 {
+		t.Fatalf("expected the taker fee to be charged, idr balance is %v", idr)
+	}
+
+	if _, err := client.Trade(indodax.TradeTypeSell, "btc_idr", indodax.OrderTypeLimit, 200, 1, nil, nil, true); err != nil {
+		t.Fatal(err)
+	}
+
+	orders := server.Orders()
+
+	if err := server.FillOrder(orders[len(orders)-1].OrderId, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	if idr, _ := server.Balance("idr"); math.Abs(idr-(899.7+200-0.2)) > 1e-9 {
+		t.Fatalf("expected the maker fee to be charged, idr balance is %v", idr)
+	}
+
+	trades, err := client.GetOwnTrades("btc_idr", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(trades) != 2 || math.Abs(trades[0].Fee-0.3) > 1e-9 || math.Abs(trades[1].Fee-0.2) > 1e-9 {
+		t.Fatalf("expected fees in the trade history, got %+v", trades)
+	}
+}
+
+func TestServerOrderNotFound(t *testing.T) {
+	server := NewServer("key", "secret")
+	defer server.Close()
+
+	_, err := server.Client().GetOrderByClientOrderId("missing")
+	if !errors.Is(err, indodax.ErrOrderNotFound) {
+		t.Fatalf("expected ErrOrderNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,58 @@
+package indodaxtest
+
+import "time"
+
+type Failure struct {
+	Status  int    `json:"status"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Times   int    `json:"times"`
+}
+
+type Ticker struct {
+	Last float64 `json:"last"`
+	Buy  float64 `json:"buy"`
+	Sell float64 `json:"sell"`
+	High float64 `json:"high"`
+	Low  float64 `json:"low"`
+}
+
+type Order struct {
+	OrderId       string    `json:"order_id"`
+	ClientOrderId string    `json:"client_order_id"`
+	Pair          string    `json:"pair"`
+	Type          string    `json:"type"`
+	OrderType     string    `json:"order_type"`
+	Price         float64   `json:"price"`
+	Amount        float64   `json:"amount"`
+	Remain        float64   `json:"remain"`
+	Status        string    `json:"status"`
+	SubmitTime    time.Time `json:"submit_time"`
+	FinishTime    time.Time `json:"finish_time"`
+
+	holdFee float64
+}
+
+type Transfer struct {
+	Id         string    `json:"id"`
+	Currency   string    `json:"currency"`
+	Amount     float64   `json:"amount"`
+	Fee        float64   `json:"fee"`
+	Address    string    `json:"address"`
+	TxId       string    `json:"tx_id"`
+	Status     string    `json:"status"`
+	SubmitTime time.Time `json:"submit_time"`
+	UpdateTime time.Time `json:"update_time"`
+}
+
+type ownTrade struct {
+	tradeId       string
+	orderId       string
+	clientOrderId string
+	pair          string
+	tradeType     string
+	price         float64
+	amount        float64
+	fee           float64
+	time          time.Time
+}
@@ -0,0 +1,68 @@
+package indodax
+
+import "log/slog"
+
+type PublicAPI interface {
+	GetServerTime() (*GetServerTimeResponseBody, error)
+	GetPairs() (*[]Pair, error)
+	GetPriceIncrements() (*GetPriceIncrementsResponseBody, error)
+	GetSummaries() (*GetSummariesResponseBody, error)
+	GetTicker(pairId string) (*GetTickerResponseBody, error)
+	GetTickerAll() (*GetTickerAllResponseBody, error)
+	GetTrades(pairId string) (*[]Trade, error)
+	GetDepth(pairId string) (*GetDepthResponseBody, error)
+	GetOHLCHistory(pairId, timeFrame string, from, to int64) (*[]OHLC, error)
+}
+
+type TradingAPI interface {
+	GetTradeHistory(pair string, fromId, toId, order *string, since, end, count *int64, orderId *string) (*GetTradeHistoryResponseBody, error)
+	GetOpenOrders(pair *string) (interface{}, error)
+	GetOrderHistory(pair string, count, from *int) (*GetOrderHistoryResponseBody, error)
+	GetOrder(pair, orderId string) (*GetOrderResponseBody, error)
+	GetOrderByClientOrderId(clientOrderId string) (*GetOrderResponseBody, error)
+	Trade(tradeType, pair, orderType string, price, amount float64, timeInForce, clientOrderId *string, forceCoinAmount bool) (*ResponseBody, error)
+	CancelOrder(pair, orderId, tradeType string, orderType *string) (*map[string]interface{}, error)
+	CancelOrderByClientOrderId(clientOrderId string) (*map[string]interface{}, error)
+}
+
+type WalletAPI interface {
+	GetInfo() (*GetInfoResponseBody, error)
+	GetTransactionHistory(fromDate, toDate string) (*GetTransactionHistoryResponseBody, error)
+	Withdraw(requestId, currency, address, network, amount, memo string) (*WithdrawCoinResponseBody, error)
+	GetWithdrawFee(currency string, coinNetwork *string) (*map[string]interface{}, error)
+}
+
+type PrivateAPI interface {
+	TradingAPI
+	WalletAPI
+	PrivateApiCall(method string, data *map[string]interface{}) (*ResponseBody, error)
+	PrivateApiCallWithCustomResult(method string, data *map[string]interface{}, result interface{}) error
+}
+
+var (
+	_ PublicAPI  = (*Client)(nil)
+	_ TradingAPI = (*Client)(nil)
+	_ WalletAPI  = (*Client)(nil)
+	_ PrivateAPI = (*Client)(nil)
+)
+
+type logger interface {
+	log(level slog.Level, message string, data map[string]interface{})
+}
+
+/*
+ * Log error through the api implementation when it supports logging
+ *
+ * @param interface{} api
+ * @param string message
+ * @param error err
+ *
+ * @return void
+ */
+func logApiError(api interface{}, message string, err error) {
+	if l, ok := api.(logger); ok {
+		l.log(slog.LevelError, message, map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+}
@@ -0,0 +1,199 @@
+package indodax
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/vannleonheart/goutil"
+	"log/slog"
+	"sort"
+	"strings"
+)
+
+const redactedValue = "REDACTED"
+
+var defaultRedactKeys = []string{
+	// credentials and signatures
+	"key", "sign", "secret", "trade_api_key", "trade_api_secret", "authorization", "api_key", "password", "token",
+	// personal data
+	"address", "withdraw_address", "memo", "withdraw_memo", "email", "name", "user_id", "profile_picture", "phone",
+}
+
+// keys ending with one of these suffixes are redacted as well, such as deposit_address or withdraw_memo
+var defaultRedactKeySuffixes = []string{"_key", "_secret", "_address", "_memo"}
+
+/*
+ * Logging, data is redacted before it is passed to the logger. The client logger
+ * is used when set, otherwise the file logger configured by Config.Log
+ *
+ * @param slog.Level level
+ * @param string message
+ * @param map[string]interface{} data
+ *
+ * @return void
+ */
+func (c *Client) log(level slog.Level, message string, data map[string]interface{}) {
+	logger := c.Logger
+
+	if logger == nil {
+		if c.Config.Log == nil || !c.Config.Log.Enable {
+			return
+		}
+
+		logger = slog.New(slog.NewJSONHandler(&logFileWriter{config: c.Config.Log}, &slog.HandlerOptions{
+			Level: parseLogLevel(c.Config.Log.Level),
+		}))
+	}
+
+	if !logger.Enabled(context.Background(), level) {
+		return
+	}
+
+	keys := make([]string, 0, len(data))
+
+	for key := range data {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	redact := c.redactKeys()
+	attrs := make([]slog.Attr, 0, len(keys))
+
+	for _, key := range keys {
+		attrs = append(attrs, slog.Any(key, redactValue(key, data[key], redact)))
+	}
+
+	logger.LogAttrs(context.Background(), level, message, attrs...)
+}
+
+func (c *Client) redactKeys() map[string]bool {
+	keys := map[string]bool{}
+
+	if c.Config.Log != nil && c.Config.Log.DisableRedaction {
+		return keys
+	}
+
+	for _, key := range defaultRedactKeys {
+		keys[key] = true
+	}
+
+	if c.Config.Log != nil {
+		for _, key := range c.Config.Log.RedactKeys {
+			keys[strings.ToLower(key)] = true
+		}
+	}
+
+	return keys
+}
+
+/*
+ * Redact a value when its key is sensitive, maps, slices and json encoded
+ * strings are walked recursively
+ *
+ * @param string key
+ * @param interface{} value
+ * @param map[string]bool redact
+ *
+ * @return interface{}
+ */
+func redactValue(key string, value interface{}, redact map[string]bool) interface{} {
+	if len(redact) == 0 {
+		return value
+	}
+
+	if isRedactedKey(strings.ToLower(key), redact) {
+		return redactedValue
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(v))
+
+		for k, item := range v {
+			result[k] = redactValue(k, item, redact)
+		}
+
+		return result
+	case *map[string]interface{}:
+		if v == nil {
+			return nil
+		}
+
+		return redactValue(key, *v, redact)
+	case map[string]string:
+		result := make(map[string]interface{}, len(v))
+
+		for k, item := range v {
+			result[k] = redactValue(k, item, redact)
+		}
+
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(v))
+
+		for i, item := range v {
+			result[i] = redactValue(key, item, redact)
+		}
+
+		return result
+	case string:
+		trimmed := strings.TrimSpace(v)
+
+		if strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[") {
+			var decoded interface{}
+
+			decoder := json.NewDecoder(strings.NewReader(trimmed))
+			decoder.UseNumber()
+
+			if err := decoder.Decode(&decoded); err == nil {
+				return redactValue(key, decoded, redact)
+			}
+		}
+	}
+
+	return value
+}
+
+func isRedactedKey(key string, redact map[string]bool) bool {
+	if redact[key] {
+		return true
+	}
+
+	for _, suffix := range defaultRedactKeySuffixes {
+		if strings.HasSuffix(key, suffix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func parseLogLevel(level string) slog.Level {
+	switch strings.ToLower(level) {
+	case "info":
+		return slog.LevelInfo
+	case "warn", "warning":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
+	}
+
+	return slog.LevelDebug
+}
+
+/*
+ * logFileWriter appends log lines to the rotated file configured by LogConfig
+ */
+type logFileWriter struct {
+	config *LogConfig
+}
+
+func (w *logFileWriter) Write(p []byte) (int, error) {
+	line := strings.TrimRight(string(p), "\n")
+
+	if err := goutil.WriteStringToFile(line, w.config.Path, w.config.Filename, w.config.Extension, w.config.Rotation); err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
@@ -0,0 +1,52 @@
+package indodax
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestLogRedactsSensitiveKeys(t *testing.T) {
+	var buf bytes.Buffer
+
+	client := New(Config{Log: &LogConfig{RedactKeys: []string{"Request_Id"}}})
+	client.Logger = slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	client.log(slog.LevelDebug, "request", map[string]interface{}{
+		"headers": map[string]string{"Key": "api-key", "Sign": "signature"},
+		"params": map[string]interface{}{
+			"method":        "withdrawCoin",
+			"withdraw_memo": "memo-1",
+			"request_id":    "request-1",
+		},
+		"response": `{"return":{"deposit_address":"address-1","payment_key":"payment-1","balance":{"idr":"1000"}}}`,
+	})
+
+	logged := buf.String()
+
+	for _, secret := range []string{"api-key", "signature", "memo-1", "request-1", "address-1", "payment-1"} {
+		if strings.Contains(logged, secret) {
+			t.Errorf("log contains %q: %s", secret, logged)
+		}
+	}
+
+	for _, kept := range []string{"withdrawCoin", `"1000"`} {
+		if !strings.Contains(logged, kept) {
+			t.Errorf("log is missing %q: %s", kept, logged)
+		}
+	}
+}
+
+func TestLogDisableRedaction(t *testing.T) {
+	var buf bytes.Buffer
+
+	client := New(Config{Log: &LogConfig{DisableRedaction: true}})
+	client.Logger = slog.New(slog.NewJSONHandler(&buf, nil))
+
+	client.log(slog.LevelInfo, "request", map[string]interface{}{"withdraw_memo": "memo-1"})
+
+	if !strings.Contains(buf.String(), "memo-1") {
+		t.Errorf("expected the memo to be logged, got %s", buf.String())
+	}
+}
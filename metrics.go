@@ -0,0 +1,360 @@
+package indodax
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/vannleonheart/goutil"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var DefaultLatencyBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// error codes used as metric labels, other codes are counted as api_error to keep the label set bounded
+var metricsErrorCodes = map[string]bool{
+	"bad_request":               true,
+	"duplicate_client_order_id": true,
+	"insufficient_balance":      true,
+	"invalid_amount":            true,
+	"invalid_credentials":       true,
+	"invalid_date":              true,
+	"invalid_date_range":        true,
+	"invalid_method":            true,
+	"invalid_nonce":             true,
+	"invalid_order":             true,
+	"invalid_pair":              true,
+	"invalid_price":             true,
+	"invalid_type":              true,
+	ErrorCodeOrderNotFound:      true,
+	ErrorCodeTooManyRequests:    true,
+}
+
+/*
+ * Metrics collects request counts, latencies, errors, rate limit waits and retries of api
+ * calls and exposes them in the prometheus text format without depending on a prometheus client.
+ * Mount it as the handler of a scrape path, or write it with WritePrometheus into an
+ * existing exposition
+ */
+type Metrics struct {
+	namespace string
+	buckets   []float64
+	mu        sync.Mutex
+	requests  map[metricsKey]float64
+	errors    map[metricsKey]float64
+	waits     map[metricsKey]float64
+	waitTime  map[metricsKey]float64
+	retries   map[metricsKey]float64
+	latencies map[metricsKey]*metricsHistogram
+}
+
+type metricsKey struct {
+	api      string
+	endpoint string
+	code     string
+}
+
+type metricsHistogram struct {
+	counts []float64
+	sum    float64
+	count  float64
+}
+
+func NewMetrics(namespace string, buckets ...float64) *Metrics {
+	if len(namespace) == 0 {
+		namespace = DefaultMetricsNamespace
+	}
+
+	if len(buckets) == 0 {
+		buckets = DefaultLatencyBuckets
+	}
+
+	sorted := make([]float64, len(buckets))
+	copy(sorted, buckets)
+	sort.Float64s(sorted)
+
+	return &Metrics{
+		namespace: namespace,
+		buckets:   sorted,
+		requests:  map[metricsKey]float64{},
+		errors:    map[metricsKey]float64{},
+		waits:     map[metricsKey]float64{},
+		waitTime:  map[metricsKey]float64{},
+		retries:   map[metricsKey]float64{},
+		latencies: map[metricsKey]*metricsHistogram{},
+	}
+}
+
+func (c *Client) WithMetrics(metrics *Metrics) *Client {
+	derived := *c
+	derived.Metrics = metrics
+
+	return &derived
+}
+
+/*
+ * Record a finished request, an empty error code means the request succeeded
+ *
+ * @param string api
+ * @param string endpoint
+ * @param time.Duration duration
+ * @param string errorCode
+ *
+ * @return void
+ */
+func (m *Metrics) ObserveRequest(api, endpoint string, duration time.Duration, errorCode string) {
+	if m == nil {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := metricsKey{api: api, endpoint: endpoint}
+
+	m.requests[key]++
+
+	h, exist := m.latencies[key]
+	if !exist {
+		h = &metricsHistogram{counts: make([]float64, len(m.buckets))}
+		m.latencies[key] = h
+	}
+
+	seconds := duration.Seconds()
+
+	for i, bound := range m.buckets {
+		if seconds <= bound {
+			h.counts[i]++
+		}
+	}
+
+	h.sum += seconds
+	h.count++
+
+	if len(errorCode) > 0 {
+		m.errors[metricsKey{api: api, endpoint: endpoint, code: errorCode}]++
+	}
+}
+
+func (m *Metrics) ObserveRateLimitWait(api, endpoint string, wait time.Duration) {
+	if m == nil {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := metricsKey{api: api, endpoint: endpoint}
+
+	m.waits[key]++
+	m.waitTime[key] += wait.Seconds()
+}
+
+func (m *Metrics) ObserveRetry(api, endpoint string) {
+	if m == nil {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.retries[metricsKey{api: api, endpoint: endpoint}]++
+}
+
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+
+	_ = m.WritePrometheus(w)
+}
+
+/*
+ * Write all metrics in the prometheus text exposition format, a nil Metrics writes nothing
+ *
+ * @param io.Writer w
+ *
+ * @return error
+ */
+func (m *Metrics) WritePrometheus(w io.Writer) error {
+	if m == nil {
+		return nil
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	bw := bufio.NewWriter(w)
+
+	m.writeCounter(bw, "api_requests_total", "Total number of api requests.", m.requests)
+	m.writeCounter(bw, "api_errors_total", "Total number of failed api requests by error code.", m.errors)
+	m.writeCounter(bw, "api_rate_limit_waits_total", "Total number of waits caused by rate limiting.", m.waits)
+	m.writeCounter(bw, "api_rate_limit_wait_seconds_total", "Total time spent waiting for rate limits.", m.waitTime)
+	m.writeCounter(bw, "api_retries_total", "Total number of retried api requests.", m.retries)
+
+	name := m.name("api_request_duration_seconds")
+
+	fmt.Fprintf(bw, "# HELP %s Latency of api requests.\n# TYPE %s histogram\n", name, name)
+
+	for _, key := range sortedMetricsKeys(m.latencies) {
+		h := m.latencies[key]
+		labels := key.labels()
+
+		for i, bound := range m.buckets {
+			fmt.Fprintf(bw, "%s_bucket{%s,le=\"%s\"} %s\n", name, labels, formatMetricValue(bound), formatMetricValue(h.counts[i]))
+		}
+
+		fmt.Fprintf(bw, "%s_bucket{%s,le=\"+Inf\"} %s\n", name, labels, formatMetricValue(h.count))
+		fmt.Fprintf(bw, "%s_sum{%s} %s\n", name, labels, formatMetricValue(h.sum))
+		fmt.Fprintf(bw, "%s_count{%s} %s\n", name, labels, formatMetricValue(h.count))
+	}
+
+	return bw.Flush()
+}
+
+func (m *Metrics) writeCounter(w io.Writer, name, help string, values map[metricsKey]float64) {
+	name = m.name(name)
+
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s counter\n", name, help, name)
+
+	for _, key := range sortedMetricsKeys(values) {
+		fmt.Fprintf(w, "%s{%s} %s\n", name, key.labels(), formatMetricValue(values[key]))
+	}
+}
+
+func (m *Metrics) name(name string) string {
+	return fmt.Sprintf("%s_%s", m.namespace, name)
+}
+
+func (k metricsKey) labels() string {
+	labels := fmt.Sprintf("api=%q,endpoint=%q", k.api, k.endpoint)
+
+	if len(k.code) > 0 {
+		labels = fmt.Sprintf("%s,code=%q", labels, k.code)
+	}
+
+	return labels
+}
+
+func sortedMetricsKeys[V any](values map[metricsKey]V) []metricsKey {
+	keys := make([]metricsKey, 0, len(values))
+
+	for key := range values {
+		keys = append(keys, key)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].labels() < keys[j].labels()
+	})
+
+	return keys
+}
+
+func formatMetricValue(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+/*
+ * Record a finished api call on the client metrics
+ *
+ * @param string api
+ * @param string endpoint
+ * @param time.Time start
+ * @param error err
+ * @param *[]byte raw
+ *
+ * @return void
+ */
+func (c *Client) observe(api, endpoint string, start time.Time, err error, raw *[]byte) {
+	if c.Metrics == nil {
+		return
+	}
+
+	c.Metrics.ObserveRequest(api, endpoint, time.Since(start), metricsErrorCode(err, raw))
+}
+
+/*
+ * Get the error code of a call, indodax error codes are preferred over http status codes
+ *
+ * @param error err
+ * @param *[]byte raw
+ *
+ * @return string
+ */
+func metricsErrorCode(err error, raw *[]byte) string {
+	var httpErr goutil.HttpResponseError
+
+	if errors.As(err, &httpErr) {
+		if httpErr.ResponseBodyRaw != nil {
+			if code := responseErrorCode(*httpErr.ResponseBodyRaw); len(code) > 0 {
+				return code
+			}
+		}
+
+		return fmt.Sprintf("http_%d", httpErr.Code)
+	}
+
+	if errors.Is(err, ErrCircuitOpen) {
+		return "circuit_open"
+	}
+
+	if err != nil {
+		return "transport"
+	}
+
+	if raw == nil {
+		return ""
+	}
+
+	return responseErrorCode(*raw)
+}
+
+func responseErrorCode(raw []byte) string {
+	var body struct {
+		Success   *json.Number `json:"success"`
+		Error     interface{}  `json:"error"`
+		ErrorCode string       `json:"error_code"`
+	}
+
+	if err := json.Unmarshal(raw, &body); err != nil {
+		return ""
+	}
+
+	code := body.ErrorCode
+
+	if message, ok := body.Error.(string); ok && len(code) == 0 {
+		code = message
+	}
+
+	if metricsErrorCodes[code] {
+		return code
+	}
+
+	if len(code) > 0 || (body.Success != nil && body.Success.String() != "1") {
+		return "api_error"
+	}
+
+	return ""
+}
+
+/*
+ * Endpoint label of a public api uri, path parameters and query strings are dropped
+ *
+ * @param string uri
+ *
+ * @return string
+ */
+func publicEndpoint(uri string) string {
+	path, _, _ := strings.Cut(uri, "?")
+
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	if len(segments) > 2 {
+		segments = segments[:2]
+	}
+
+	return "/" + strings.Join(segments, "/")
+}
@@ -0,0 +1,198 @@
+package indodax
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/vannleonheart/goutil"
+)
+
+func TestMetricsErrorCode(t *testing.T) {
+	raw := func(body string) *[]byte {
+		b := []byte(body)
+		return &b
+	}
+
+	tests := []struct {
+		name string
+		err  error
+		raw  *[]byte
+		want string
+	}{
+		{"success", nil, raw(`{"success":1,"return":{}}`), ""},
+		{"known code", nil, raw(`{"success":0,"error":"Insufficient balance.","error_code":"insufficient_balance"}`), "insufficient_balance"},
+		{"unknown code", nil, raw(`{"success":0,"error":"Order 123 is locked","error_code":"order_123_locked"}`), "api_error"},
+		{"message only", nil, raw(`{"success":0,"error":"Invalid credentials. API not found or session has expired."}`), "api_error"},
+		{"code as message", nil, raw(`{"success":0,"error":"invalid_pair"}`), "invalid_pair"},
+		{"http error", goutil.HttpResponseError{Code: 502}, nil, "http_502"},
+		{"http error with code", goutil.HttpResponseError{Code: 400, ResponseBodyRaw: raw(`{"error_code":"invalid_nonce"}`)}, nil, "invalid_nonce"},
+		{"transport", errors.New("connection reset"), nil, "transport"},
+	}
+
+	for _, test := range tests {
+		if got := metricsErrorCode(test.err, test.raw); got != test.want {
+			t.Errorf("%s: expected %q, got %q", test.name, test.want, got)
+		}
+	}
+}
+
+func TestMetricsWritePrometheus(t *testing.T) {
+	metrics := NewMetrics("test", 1)
+
+	metrics.ObserveRequest(ApiPublic, "/api/ticker", 500*time.Millisecond, "")
+	metrics.ObserveRequest(ApiPrivate, MethodTrade, 2*time.Second, "insufficient_balance")
+	metrics.ObserveRetry(ApiPrivate, MethodTrade)
+
+	rec := httptest.NewRecorder()
+	metrics.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	body := rec.Body.String()
+
+	for _, line := range []string{
+		`test_api_requests_total{api="public",endpoint="/api/ticker"} 1`,
+		`test_api_errors_total{api="private",endpoint="trade",code="insufficient_balance"} 1`,
+		`test_api_retries_total{api="private",endpoint="trade"} 1`,
+		`test_api_request_duration_seconds_bucket{api="public",endpoint="/api/ticker",le="1"} 1`,
+		`test_api_request_duration_seconds_bucket{api="private",endpoint="trade",le="1"} 0`,
+		`test_api_request_duration_seconds_count{api="private",endpoint="trade"} 1`,
+	} {
+		if !strings.Contains(body, line+"\n") {
+			t.Errorf("expected %q in\n%s", line, body)
+		}
+	}
+}
+
+func TestMetricsNil(t *testing.T) {
+	var metrics *Metrics
+
+	metrics.ObserveRequest(ApiPublic, "/api/ticker", time.Second, "")
+	metrics.ObserveRetry(ApiPrivate, MethodTrade)
+
+	rec := httptest.NewRecorder()
+	metrics.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	if rec.Code != http.StatusOK || rec.Body.Len() > 0 {
+		t.Errorf("expected an empty response, got %d %q", rec.Code, rec.Body.String())
+	}
+}
+
+func TestMetricsCountsTradeRetries(t *testing.T) {
+	server := newTradeServer(t, false, "")
+	metrics := NewMetrics("test")
+
+	if _, err := server.client().WithMetrics(metrics).Trade(TradeTypeBuy, "btc_idr", OrderTypeLimit, 100, 1, nil, nil, true); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf strings.Builder
+
+	if err := metrics.WritePrometheus(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(buf.String(), `test_api_retries_total{api="private",endpoint="trade"} 1`) {
+		t.Errorf("expected the trade retry to be counted, got\n%s", buf.String())
+	}
+}
+
+func TestMetricsCountsRateLimitWaits(t *testing.T) {
+	var (
+		mu         sync.Mutex
+		calls      int
+		timestamps []string
+		signs      []string
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		calls++
+
+		if r.Method == http.MethodGet {
+			if calls < 3 {
+				w.WriteHeader(http.StatusTooManyRequests)
+				return
+			}
+
+			_, _ = io.WriteString(w, `{"ticker":{"last":"100"}}`)
+			return
+		}
+
+		_ = r.ParseForm()
+
+		timestamps = append(timestamps, r.PostForm.Get("timestamp"))
+		signs = append(signs, r.Header.Get("Sign"))
+
+		if len(signs) == 1 {
+			_, _ = io.WriteString(w, `{"success":0,"error":"Too many requests","error_code":"too_many_requests"}`)
+			return
+		}
+
+		_, _ = io.WriteString(w, `{"success":1,"return":{"balance":{"idr":"1"}}}`)
+	}))
+	defer server.Close()
+
+	metrics := NewMetrics("test")
+
+	client := New(Config{
+		PublicApiBaseUrl:  server.URL,
+		PrivateApiBaseUrl: server.URL,
+		RateLimitRetries:  2,
+		RateLimitBackoff:  5 * time.Millisecond,
+	}).WithCredential("key", "secret").WithMetrics(metrics)
+
+	if _, err := client.GetTicker("btcidr"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := client.GetInfo(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(signs) != 2 || signs[0] == signs[1] || timestamps[0] == timestamps[1] {
+		t.Errorf("expected the retried private call to be signed again, got timestamps %v and signs %v", timestamps, signs)
+	}
+
+	var buf strings.Builder
+
+	if err := metrics.WritePrometheus(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, line := range []string{
+		`test_api_rate_limit_waits_total{api="public",endpoint="/api/ticker"} 2`,
+		`test_api_rate_limit_wait_seconds_total{api="public",endpoint="/api/ticker"} 0.015`,
+		`test_api_retries_total{api="public",endpoint="/api/ticker"} 2`,
+		`test_api_rate_limit_waits_total{api="private",endpoint="getInfo"} 1`,
+		`test_api_requests_total{api="private",endpoint="getInfo"} 1`,
+	} {
+		if !strings.Contains(buf.String(), line+"\n") {
+			t.Errorf("expected %q in\n%s", line, buf.String())
+		}
+	}
+}
+
+func TestRateLimitRetriesDisabled(t *testing.T) {
+	var calls int
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	_, err := New(Config{PublicApiBaseUrl: server.URL}).GetTicker("btcidr")
+
+	var httpErr goutil.HttpResponseError
+
+	if !errors.As(err, &httpErr) || httpErr.Code != http.StatusTooManyRequests || calls != 1 {
+		t.Errorf("expected a single rate limited call, got %d calls and %v", calls, err)
+	}
+}
@@ -0,0 +1,102 @@
+package indodax
+
+import (
+	"context"
+	"time"
+)
+
+/*
+ * ApiRequest is an api call passing through the middleware chain. Data is the private
+ * request body before it is signed, middleware may change it or set the Key and Sign
+ * headers to sign the request itself
+ */
+type ApiRequest struct {
+	Context  context.Context
+	Api      string
+	Endpoint string
+	Method   string
+	Pair     string
+	Url      string
+	Data     map[string]interface{}
+	Header   map[string]string
+}
+
+type ApiHandler func(req *ApiRequest) (*[]byte, error)
+
+type Middleware func(next ApiHandler) ApiHandler
+
+/*
+ * Derive a client adding middleware to the chain of public and private calls, the first
+ * middleware added is the outermost and sees requests first and responses last
+ *
+ * @param ...Middleware middleware
+ *
+ * @return *Client
+ */
+func (c *Client) WithMiddleware(middleware ...Middleware) *Client {
+	chain := make([]Middleware, 0, len(c.Middleware)+len(middleware))
+	chain = append(chain, c.Middleware...)
+	chain = append(chain, middleware...)
+
+	derived := *c
+	derived.Middleware = chain
+
+	return &derived
+}
+
+/*
+ * Wrap the handler sending a request with the middleware chain. Metrics and tracing
+ * wrap the whole chain, so calls answered by middleware are observed as well, and
+ * rate limited requests are sent again inside of it
+ *
+ * @param ApiHandler send
+ *
+ * @return ApiHandler
+ */
+func (c *Client) chain(send ApiHandler) ApiHandler {
+	handler := c.retryRateLimited(send)
+
+	for i := len(c.Middleware) - 1; i >= 0; i-- {
+		handler = c.Middleware[i](handler)
+	}
+
+	return c.instrument(handler)
+}
+
+/*
+ * Record the metrics and the span of a call, the context of the span is passed
+ * on to the middleware and the transport
+ *
+ * @param ApiHandler next
+ *
+ * @return ApiHandler
+ */
+func (c *Client) instrument(next ApiHandler) ApiHandler {
+	return func(req *ApiRequest) (*[]byte, error) {
+		endpoint := req.Endpoint
+
+		attributes := map[string]interface{}{
+			"indodax.api":      req.Api,
+			"indodax.endpoint": req.Endpoint,
+			"indodax.pair":     req.Pair,
+		}
+
+		if req.Api == ApiPrivate {
+			endpoint = req.Method
+			attributes["indodax.method"] = req.Method
+		}
+
+		ctx, span := c.startSpan(req.Context, "indodax."+req.Api, attributes)
+		req.Context = ctx
+
+		start := time.Now()
+
+		raw, err := next(req)
+
+		c.observe(req.Api, endpoint, start, err, raw)
+
+		endSpan(span, err, raw)
+
+		return raw, err
+	}
+}
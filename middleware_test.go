@@ -0,0 +1,223 @@
+package indodax
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// privateServer records the requests it receives and answers every call successfully
+type privateServer struct {
+	*httptest.Server
+	mu       sync.Mutex
+	bodies   []string
+	headers  []http.Header
+	requests int
+}
+
+func newPrivateServer(t *testing.T) *privateServer {
+	s := &privateServer{}
+
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+
+		s.mu.Lock()
+		s.requests++
+		s.bodies = append(s.bodies, string(body))
+		s.headers = append(s.headers, r.Header.Clone())
+		s.mu.Unlock()
+
+		_, _ = io.WriteString(w, `{"success":1,"return":{"balance":{"idr":"1"}}}`)
+	}))
+
+	t.Cleanup(s.Close)
+
+	return s
+}
+
+type recordingTracer struct {
+	mu    sync.Mutex
+	spans []string
+	ended int
+}
+
+func (r *recordingTracer) Start(ctx context.Context, name string, _ map[string]interface{}) (context.Context, Span) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.spans = append(r.spans, name)
+
+	return ctx, recordingSpan{r}
+}
+
+type recordingSpan struct {
+	tracer *recordingTracer
+}
+
+func (recordingSpan) SetAttributes(map[string]interface{}) {}
+
+func (recordingSpan) RecordError(error) {}
+
+func (s recordingSpan) End() {
+	s.tracer.mu.Lock()
+	defer s.tracer.mu.Unlock()
+
+	s.tracer.ended++
+}
+
+func TestMiddlewareOrderAndMutation(t *testing.T) {
+	server := newPrivateServer(t)
+
+	var calls []string
+
+	trace := func(name string) Middleware {
+		return func(next ApiHandler) ApiHandler {
+			return func(req *ApiRequest) (*[]byte, error) {
+				calls = append(calls, name+" in")
+
+				raw, err := next(req)
+
+				calls = append(calls, name+" out")
+
+				return raw, err
+			}
+		}
+	}
+
+	mutate := func(next ApiHandler) ApiHandler {
+		return func(req *ApiRequest) (*[]byte, error) {
+			req.Data["note"] = "changed"
+			req.Header["X-Test"] = "1"
+
+			return next(req)
+		}
+	}
+
+	client := New(Config{PrivateApiBaseUrl: server.URL}).
+		WithCredential("key", "secret").
+		WithMiddleware(trace("outer"), trace("inner")).
+		WithMiddleware(mutate)
+
+	if _, err := client.GetInfo(); err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := []string{"outer in", "inner in", "inner out", "outer out"}; !reflect.DeepEqual(calls, expected) {
+		t.Errorf("expected calls %v, got %v", expected, calls)
+	}
+
+	body, header := server.bodies[0], server.headers[0]
+
+	values, err := url.ParseQuery(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if values.Get("note") != "changed" || header.Get("X-Test") != "1" {
+		t.Errorf("expected the changed request to be sent, got %s with %v", body, header)
+	}
+
+	// the request is signed after the middleware changed it
+	mac := hmac.New(sha512.New, []byte("secret"))
+	mac.Write([]byte(body))
+
+	if header.Get("Key") != "key" || header.Get("Sign") != hex.EncodeToString(mac.Sum(nil)) {
+		t.Errorf("expected the sent body to be signed, got key %q and sign %q", header.Get("Key"), header.Get("Sign"))
+	}
+}
+
+func TestMiddlewareShortCircuitIsObserved(t *testing.T) {
+	server := newPrivateServer(t)
+	metrics := NewMetrics("test")
+	tracer := &recordingTracer{}
+
+	var reject bool
+
+	answer := func(next ApiHandler) ApiHandler {
+		return func(req *ApiRequest) (*[]byte, error) {
+			if reject {
+				return nil, &CircuitOpenError{Group: CircuitGroupAccount, Reason: "test"}
+			}
+
+			raw := []byte(`{"success":1,"return":{"balance":{"idr":"2"}}}`)
+
+			return &raw, nil
+		}
+	}
+
+	client := New(Config{PrivateApiBaseUrl: server.URL}).
+		WithCredential("key", "secret").
+		WithMetrics(metrics).
+		WithTracer(tracer).
+		WithMiddleware(answer)
+
+	info, err := client.GetInfo()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if info.Balance["idr"] != "2" {
+		t.Errorf("expected the middleware answer, got %+v", info.Balance)
+	}
+
+	reject = true
+
+	if _, err = client.GetInfo(); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("expected the middleware error, got %v", err)
+	}
+
+	if server.requests != 0 {
+		t.Errorf("expected no request to reach the exchange, got %d", server.requests)
+	}
+
+	if len(tracer.spans) != 2 || tracer.ended != 2 || tracer.spans[0] != "indodax.private" {
+		t.Errorf("expected two ended private spans, got %v with %d ended", tracer.spans, tracer.ended)
+	}
+
+	var buf strings.Builder
+
+	if err = metrics.WritePrometheus(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, line := range []string{
+		`test_api_requests_total{api="private",endpoint="getInfo"} 2`,
+		`test_api_errors_total{api="private",endpoint="getInfo",code="circuit_open"} 1`,
+	} {
+		if !strings.Contains(buf.String(), line+"\n") {
+			t.Errorf("expected %q in\n%s", line, buf.String())
+		}
+	}
+}
+
+func TestMiddlewareSignWithoutKey(t *testing.T) {
+	server := newPrivateServer(t)
+
+	sign := func(next ApiHandler) ApiHandler {
+		return func(req *ApiRequest) (*[]byte, error) {
+			req.Header["Sign"] = "external"
+
+			return next(req)
+		}
+	}
+
+	client := New(Config{PrivateApiBaseUrl: server.URL}).WithCredential("key", "secret").WithMiddleware(sign)
+
+	if _, err := client.GetInfo(); err != nil {
+		t.Fatal(err)
+	}
+
+	if header := server.headers[0]; header.Get("Key") != "key" || header.Get("Sign") != "external" {
+		t.Errorf("expected the key of the client with the middleware signature, got key %q and sign %q", header.Get("Key"), header.Get("Sign"))
+	}
+}
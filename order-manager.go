@@ -0,0 +1,484 @@
+package indodax
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const orderStoreKey = "orders"
+
+var orderStateTransitions = map[string][]string{
+	OrderStatePending:         {OrderStateOpen, OrderStatePartiallyFilled, OrderStateFilled, OrderStateCancelled, OrderStateRejected},
+	OrderStateOpen:            {OrderStatePartiallyFilled, OrderStateFilled, OrderStateCancelled},
+	OrderStatePartiallyFilled: {OrderStateFilled, OrderStateCancelled},
+}
+
+type OrderManager struct {
+	client   TradingAPI
+	store    Store
+	config   OrderManagerConfig
+	handler  func(OrderStateChange)
+	mu       sync.Mutex
+	orders   map[string]*ManagedOrder
+	sequence int64
+}
+
+func NewOrderManager(client TradingAPI, store Store, config OrderManagerConfig) (*OrderManager, error) {
+	if client == nil {
+		return nil, errors.New("client is required")
+	}
+
+	if store == nil {
+		store = NewMemoryStore()
+	}
+
+	if config.ReconcileInterval <= 0 {
+		config.ReconcileInterval = DefaultOrderReconcileInterval
+	}
+
+	if config.PendingTimeout <= 0 {
+		config.PendingTimeout = DefaultOrderPendingTimeout
+	}
+
+	if config.HistoryCount <= 0 {
+		config.HistoryCount = DefaultOrderHistoryCount
+	}
+
+	m := &OrderManager{
+		client: client,
+		store:  store,
+		config: config,
+		orders: map[string]*ManagedOrder{},
+	}
+
+	var saved []ManagedOrder
+
+	if err := store.Load(orderStoreKey, &saved); err != nil && !errors.Is(err, ErrStateNotFound) {
+		return nil, err
+	}
+
+	for i := range saved {
+		o := saved[i]
+		m.orders[o.Id] = &o
+
+		if id, err := strconv.ParseInt(o.Id, 10, 64); err == nil && id > m.sequence {
+			m.sequence = id
+		}
+	}
+
+	return m, nil
+}
+
+func (m *OrderManager) WithHandler(handler func(OrderStateChange)) *OrderManager {
+	m.handler = handler
+
+	return m
+}
+
+/*
+ * Place an order through the client and track it, the order gets a client order id and is
+ * persisted as pending before it is sent so that it can be recovered after a restart
+ *
+ * @return *ManagedOrder
+ * @return error
+ */
+func (m *OrderManager) Trade(tradeType, pair, orderType string, price, amount float64, timeInForce, clientOrderId *string, forceCoinAmount bool) (*ManagedOrder, error) {
+	now := time.Now()
+
+	m.mu.Lock()
+
+	m.sequence++
+
+	o := &ManagedOrder{
+		Id:        strconv.FormatInt(m.sequence, 10),
+		Pair:      strings.ToLower(pair),
+		Type:      tradeType,
+		OrderType: orderType,
+		Price:     price,
+		Amount:    amount,
+		Remain:    amount,
+		State:     OrderStatePending,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	if clientOrderId == nil || len(*clientOrderId) == 0 {
+		id := NewClientOrderId("")
+		clientOrderId = &id
+	}
+
+	o.ClientOrderId = *clientOrderId
+
+	m.orders[o.Id] = o
+	err := m.save()
+
+	m.mu.Unlock()
+
+	if err != nil {
+		return nil, err
+	}
+
+	resp, tradeErr := m.client.Trade(tradeType, o.Pair, orderType, price, amount, timeInForce, clientOrderId, forceCoinAmount)
+
+	var changes []OrderStateChange
+
+	m.mu.Lock()
+
+	if tradeErr != nil {
+		o.Error = tradeErr.Error()
+
+		// an unknown outcome stays pending until it is resolved by client order id
+		if !errors.Is(tradeErr, ErrOrderOutcomeUnknown) {
+			if change, ok := m.transition(o, OrderStateRejected, now); ok {
+				changes = append(changes, change)
+			}
+		}
+	} else {
+		ret, _ := resp.Return.(map[string]interface{})
+		coin, _, _ := splitPair(o.Pair)
+
+		update := OrderUpdate{
+			OrderId: toString(ret["order_id"]),
+			Status:  OrderStatusOpen,
+			Remain:  o.Remain,
+		}
+
+		if remain, exist := ret[fmt.Sprintf("remain_%s", coin)]; exist {
+			update.Remain = toFloat(remain)
+		}
+
+		update.Filled = toFloat(ret[fmt.Sprintf("receive_%s", coin)]) + toFloat(ret[fmt.Sprintf("sold_%s", coin)])
+
+		if change, ok := m.apply(o, update, now); ok {
+			changes = append(changes, change)
+		}
+	}
+
+	result := *o
+	err = m.save()
+
+	m.mu.Unlock()
+
+	m.emit(changes)
+
+	if tradeErr != nil {
+		return &result, tradeErr
+	}
+
+	return &result, err
+}
+
+func (m *OrderManager) Order(id string) (*ManagedOrder, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	o, exist := m.orders[id]
+	if !exist {
+		return nil, false
+	}
+
+	result := *o
+
+	return &result, true
+}
+
+func (m *OrderManager) Orders() []ManagedOrder {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.list(false)
+}
+
+func (m *OrderManager) Active() []ManagedOrder {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.list(true)
+}
+
+/*
+ * Reconcile active orders with open orders and order history of the exchange,
+ * pending orders are resolved by client order id, and rejected after the pending
+ * timeout only when the exchange confirms the client order id does not exist
+ *
+ * @return error
+ */
+func (m *OrderManager) Reconcile() error {
+	active := m.Active()
+
+	pairs := map[string]bool{}
+
+	for _, pair := range m.config.Pairs {
+		pairs[strings.ToLower(pair)] = true
+	}
+
+	for _, o := range active {
+		pairs[o.Pair] = true
+	}
+
+	updates := map[string]OrderUpdate{}
+
+	var errs []error
+
+	for pair := range pairs {
+		p := pair
+
+		resp, err := m.client.GetOpenOrders(&p)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		open, ok := resp.(*GetPairOpenOrdersResponseBody)
+		if !ok {
+			errs = append(errs, fmt.Errorf("unexpected open orders response %T", resp))
+			continue
+		}
+
+		for _, order := range open.Orders {
+			update := parseOrderUpdate(pair, order)
+			update.Status = OrderStatusOpen
+			updates[update.OrderId] = update
+		}
+	}
+
+	history := map[string]bool{}
+	now := time.Now()
+
+	var changes []OrderStateChange
+
+	for _, o := range active {
+		update, found := m.lookup(updates, o)
+
+		if !found && len(o.OrderId) > 0 && !history[o.Pair] {
+			history[o.Pair] = true
+
+			count := m.config.HistoryCount
+
+			resp, err := m.client.GetOrderHistory(o.Pair, &count, nil)
+			if err != nil {
+				errs = append(errs, err)
+			} else {
+				for _, order := range resp.Orders {
+					u := parseOrderUpdate(o.Pair, order)
+					if _, exist := updates[u.OrderId]; !exist {
+						updates[u.OrderId] = u
+					}
+				}
+			}
+
+			update, found = m.lookup(updates, o)
+		}
+
+		if !found && len(o.OrderId) > 0 {
+			resp, err := m.client.GetOrder(o.Pair, o.OrderId)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+
+			update, found = parseOrderUpdate(o.Pair, resp.Order), true
+		}
+
+		unresolved := false
+
+		if !found && o.State == OrderStatePending && len(o.ClientOrderId) > 0 {
+			resp, err := m.client.GetOrderByClientOrderId(o.ClientOrderId)
+
+			switch {
+			case err == nil && len(resp.Order) > 0:
+				update, found = parseOrderUpdate(o.Pair, resp.Order), true
+			case err != nil && !errors.Is(err, ErrOrderNotFound):
+				errs = append(errs, fmt.Errorf("failed to look up client order id %s: %w", o.ClientOrderId, err))
+				unresolved = true
+			}
+		}
+
+		m.mu.Lock()
+
+		current := m.orders[o.Id]
+
+		if found {
+			if change, ok := m.apply(current, update, now); ok {
+				changes = append(changes, change)
+			}
+		} else if !unresolved && current.State == OrderStatePending && now.Sub(current.CreatedAt) >= m.config.PendingTimeout {
+			if change, ok := m.transition(current, OrderStateRejected, now); ok {
+				changes = append(changes, change)
+			}
+		}
+
+		m.mu.Unlock()
+	}
+
+	m.mu.Lock()
+	err := m.save()
+	m.mu.Unlock()
+
+	m.emit(changes)
+
+	if err != nil {
+		return err
+	}
+
+	return errors.Join(errs...)
+}
+
+func (m *OrderManager) Run(ctx context.Context) error {
+	ticker := time.NewTicker(m.config.ReconcileInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := m.Reconcile(); err != nil {
+			logApiError(m.client, "failed to reconcile orders", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func (m *OrderManager) lookup(updates map[string]OrderUpdate, o ManagedOrder) (OrderUpdate, bool) {
+	if len(o.OrderId) > 0 {
+		if update, exist := updates[o.OrderId]; exist {
+			return update, true
+		}
+	}
+
+	for _, update := range updates {
+		if len(o.ClientOrderId) > 0 && update.ClientOrderId == o.ClientOrderId {
+			return update, true
+		}
+	}
+
+	return OrderUpdate{}, false
+}
+
+/*
+ * Apply an exchange view of an order to a managed order, partially filled
+ * orders report every new fill
+ *
+ * @param *ManagedOrder o
+ * @param OrderUpdate update
+ * @param time.Time now
+ *
+ * @return OrderStateChange
+ * @return bool
+ */
+func (m *OrderManager) apply(o *ManagedOrder, update OrderUpdate, now time.Time) (OrderStateChange, bool) {
+	if !isActiveOrderState(o.State) {
+		return OrderStateChange{}, false
+	}
+
+	previousFilled := o.Filled
+
+	if len(o.OrderId) == 0 {
+		o.OrderId = update.OrderId
+	}
+
+	if update.Amount > 0 || update.Filled > 0 {
+		o.Filled = update.Filled
+		o.Remain = update.Remain
+	}
+
+	state := OrderStateOpen
+
+	switch update.Status {
+	case OrderStatusFilled:
+		state = OrderStateFilled
+	case OrderStatusCancelled:
+		state = OrderStateCancelled
+	default:
+		if o.Filled > 0 && o.Remain <= simulatedEpsilon {
+			state = OrderStateFilled
+		} else if o.Filled > 0 {
+			state = OrderStatePartiallyFilled
+		}
+	}
+
+	if state == o.State {
+		if o.Filled == previousFilled {
+			return OrderStateChange{}, false
+		}
+
+		o.UpdatedAt = now
+
+		return OrderStateChange{Order: *o, From: state, To: state}, true
+	}
+
+	return m.transition(o, state, now)
+}
+
+/*
+ * Move a managed order to a new state, transitions that are not allowed by the state machine are ignored
+ *
+ * @param *ManagedOrder o
+ * @param string state
+ * @param time.Time now
+ *
+ * @return OrderStateChange
+ * @return bool
+ */
+func (m *OrderManager) transition(o *ManagedOrder, state string, now time.Time) (OrderStateChange, bool) {
+	for _, next := range orderStateTransitions[o.State] {
+		if next != state {
+			continue
+		}
+
+		previous := o.State
+
+		o.State = state
+		o.UpdatedAt = now
+
+		return OrderStateChange{Order: *o, From: previous, To: state}, true
+	}
+
+	return OrderStateChange{}, false
+}
+
+func (m *OrderManager) list(activeOnly bool) []ManagedOrder {
+	result := make([]ManagedOrder, 0, len(m.orders))
+
+	for _, o := range m.orders {
+		if activeOnly && !isActiveOrderState(o.State) {
+			continue
+		}
+
+		result = append(result, *o)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return compareIds(result[i].Id, result[j].Id) < 0
+	})
+
+	return result
+}
+
+func (m *OrderManager) emit(changes []OrderStateChange) {
+	if m.handler == nil {
+		return
+	}
+
+	for _, change := range changes {
+		m.handler(change)
+	}
+}
+
+func (m *OrderManager) save() error {
+	return m.store.Save(orderStoreKey, m.list(false))
+}
+
+func isActiveOrderState(state string) bool {
+	_, active := orderStateTransitions[state]
+
+	return active
+}
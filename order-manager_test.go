@@ -0,0 +1,76 @@
+package indodax
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// fakeOrders answers open orders with an empty book and client order id lookups with lookupErr or order
+type fakeOrders struct {
+	unimplementedPrivate
+	lookupErr error
+	order     map[string]interface{}
+}
+
+func (f *fakeOrders) GetOpenOrders(_ *string) (interface{}, error) {
+	return &GetPairOpenOrdersResponseBody{}, nil
+}
+
+func (f *fakeOrders) GetOrderByClientOrderId(_ string) (*GetOrderResponseBody, error) {
+	if f.lookupErr != nil {
+		return nil, f.lookupErr
+	}
+
+	return &GetOrderResponseBody{Order: f.order}, nil
+}
+
+func TestOrderManagerReconcilePendingOrder(t *testing.T) {
+	tests := []struct {
+		name      string
+		api       *fakeOrders
+		state     string
+		expectErr bool
+	}{
+		{"lookup failed", &fakeOrders{lookupErr: errors.New("connection reset")}, OrderStatePending, true},
+		{"not found", &fakeOrders{lookupErr: fmt.Errorf("%w: Order not found", ErrOrderNotFound)}, OrderStateRejected, false},
+		{"found", &fakeOrders{order: map[string]interface{}{"order_id": "10", "status": "open", "client_order_id": "c1"}}, OrderStateOpen, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			store := NewMemoryStore()
+			created := time.Now().Add(-time.Hour)
+
+			if err := store.Save(orderStoreKey, []ManagedOrder{{
+				Id:            "1",
+				ClientOrderId: "c1",
+				Pair:          "btc_idr",
+				Type:          TradeTypeBuy,
+				Price:         100,
+				Amount:        1,
+				Remain:        1,
+				State:         OrderStatePending,
+				CreatedAt:     created,
+				UpdatedAt:     created,
+			}}); err != nil {
+				t.Fatal(err)
+			}
+
+			manager, err := NewOrderManager(test.api, store, OrderManagerConfig{PendingTimeout: time.Minute})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if err = manager.Reconcile(); (err != nil) != test.expectErr {
+				t.Fatalf("expected error %v, got %v", test.expectErr, err)
+			}
+
+			o, _ := manager.Order("1")
+			if o.State != test.state {
+				t.Fatalf("expected state %s, got %s", test.state, o.State)
+			}
+		})
+	}
+}
@@ -0,0 +1,391 @@
+package indodax
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+type PaperTrader struct {
+	*simulatedExchange
+	market      PublicAPI
+	feesLoaded  bool
+	feeOverride map[string]simulatedFee
+	consumed    map[string]map[paperLevel]float64
+}
+
+// price level of one side of the order book
+type paperLevel struct {
+	side  string
+	price float64
+}
+
+var (
+	_ TradingAPI = (*PaperTrader)(nil)
+	_ WalletAPI  = (*PaperTrader)(nil)
+)
+
+func NewPaperTrader(market PublicAPI, balances map[string]float64) (*PaperTrader, error) {
+	if market == nil {
+		return nil, errors.New("market data api is required")
+	}
+
+	return &PaperTrader{
+		simulatedExchange: newSimulatedExchange(balances),
+		market:            market,
+		feeOverride:       map[string]simulatedFee{},
+		consumed:          map[string]map[paperLevel]float64{},
+	}, nil
+}
+
+/*
+ * Override maker and taker fee of a pair, rates are fractions e.g. 0.003 for 0.3%
+ *
+ * @param string pair
+ * @param float64 maker
+ * @param float64 taker
+ *
+ * @return *PaperTrader
+ */
+func (p *PaperTrader) WithFee(pair string, maker, taker float64) *PaperTrader {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	fee := simulatedFee{maker: maker, taker: taker}
+
+	p.feeOverride[strings.ToLower(pair)] = fee
+	p.fees[strings.ToLower(pair)] = fee
+
+	return p
+}
+
+func (p *PaperTrader) Trade(tradeType, pair, orderType string, price, amount float64, timeInForce, clientOrderId *string, forceCoinAmount bool) (*ResponseBody, error) {
+	if err := p.loadFees(); err != nil {
+		return nil, err
+	}
+
+	depth, err := p.market.GetDepth(publicPairId(pair))
+	if err != nil {
+		return nil, err
+	}
+
+	levels := depthLevels(depth.Sell, false)
+	if tradeType == TradeTypeSell {
+		levels = depthLevels(depth.Buy, true)
+	}
+
+	var spend float64
+
+	if orderType == OrderTypeMarket && tradeType == TradeTypeBuy && !forceCoinAmount {
+		spend, amount = amount, 0
+	}
+
+	var tif, clientId string
+
+	if timeInForce != nil {
+		tif = *timeInForce
+	}
+
+	if clientOrderId != nil {
+		clientId = *clientOrderId
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	key := strings.ToLower(pair)
+	levels = p.availableLevels(key, tradeType, levels)
+
+	resp, err := p.place(tradeType, pair, orderType, price, amount, spend, tif, clientId, levels)
+	if err != nil {
+		return nil, err
+	}
+
+	o := p.orders[len(p.orders)-1]
+	p.consume(key, tradeType, levels, o.amount-o.remain)
+
+	return resp, nil
+}
+
+/*
+ * Match resting orders against the current order book and trades printed since
+ * the last match, resting orders are filled at their limit price as maker. Orders
+ * share the liquidity in price and time priority, and book depth matched by an
+ * earlier call is not matched again while the level is still on the book
+ *
+ * @return error
+ */
+func (p *PaperTrader) Match() error {
+	p.mu.Lock()
+
+	pairs := map[string]bool{}
+
+	for _, o := range p.openOrders() {
+		pairs[o.pair] = true
+	}
+
+	p.mu.Unlock()
+
+	for pair := range pairs {
+		depth, err := p.market.GetDepth(publicPairId(pair))
+		if err != nil {
+			return err
+		}
+
+		trades, err := p.market.GetTrades(publicPairId(pair))
+		if err != nil {
+			return err
+		}
+
+		p.mu.Lock()
+		p.matchPair(pair, depth, *trades)
+		p.mu.Unlock()
+	}
+
+	return nil
+}
+
+/*
+ * Match resting orders of a pair, the caller holds the lock
+ *
+ * @param string pair
+ * @param *GetDepthResponseBody depth
+ * @param []Trade trades
+ *
+ * @return void
+ */
+func (p *PaperTrader) matchPair(pair string, depth *GetDepthResponseBody, trades []Trade) {
+	books := map[string][][2]float64{
+		TradeTypeBuy:  depthLevels(depth.Sell, false),
+		TradeTypeSell: depthLevels(depth.Buy, true),
+	}
+
+	consumed := p.consumed[pair]
+	left := map[paperLevel]float64{}
+
+	for side, levels := range books {
+		for _, level := range levels {
+			key := paperLevel{side: side, price: level[0]}
+			left[key] = level[1] - consumed[key]
+		}
+	}
+
+	tradeLeft := make([]float64, len(trades))
+
+	for i, t := range trades {
+		tradeLeft[i] = toFloat(t.Amount)
+	}
+
+	var orders []*simulatedOrder
+
+	for _, o := range p.openOrders() {
+		if o.pair == pair {
+			orders = append(orders, o)
+		}
+	}
+
+	sort.SliceStable(orders, func(i, j int) bool {
+		if orders[i].tradeType != orders[j].tradeType || orders[i].price == orders[j].price {
+			return false
+		}
+
+		if orders[i].tradeType == TradeTypeBuy {
+			return orders[i].price > orders[j].price
+		}
+
+		return orders[i].price < orders[j].price
+	})
+
+	for _, o := range orders {
+		available := 0.0
+
+		for _, level := range books[o.tradeType] {
+			if available >= o.remain || !levelCrosses(o.tradeType, o.price, level[0]) {
+				break
+			}
+
+			key := paperLevel{side: o.tradeType, price: level[0]}
+			used := math.Min(math.Max(left[key], 0), o.remain-available)
+
+			left[key] -= used
+			available += used
+		}
+
+		latest := o.lastMatch
+
+		for i, t := range trades {
+			tradeTime := toTime(t.Date)
+			if !tradeTime.After(o.lastMatch) {
+				continue
+			}
+
+			if tradeTime.After(latest) {
+				latest = tradeTime
+			}
+
+			if available < o.remain && levelCrosses(o.tradeType, o.price, toFloat(t.Price)) {
+				used := math.Min(tradeLeft[i], o.remain-available)
+
+				tradeLeft[i] -= used
+				available += used
+			}
+		}
+
+		o.lastMatch = latest
+
+		if available > simulatedEpsilon {
+			p.fill(o, o.price, available, true)
+		}
+	}
+
+	next := map[paperLevel]float64{}
+
+	for side, levels := range books {
+		for _, level := range levels {
+			key := paperLevel{side: side, price: level[0]}
+
+			if used := level[1] - math.Max(left[key], 0); used > simulatedEpsilon {
+				next[key] = used
+			}
+		}
+	}
+
+	p.consumed[pair] = next
+}
+
+/*
+ * Remove the depth already matched by paper orders from the order book levels
+ *
+ * @param string pair
+ * @param string side
+ * @param [][2]float64 levels
+ *
+ * @return [][2]float64
+ */
+func (p *PaperTrader) availableLevels(pair, side string, levels [][2]float64) [][2]float64 {
+	result := make([][2]float64, 0, len(levels))
+
+	for _, level := range levels {
+		amount := level[1] - p.consumed[pair][paperLevel{side: side, price: level[0]}]
+
+		if amount > simulatedEpsilon {
+			result = append(result, [2]float64{level[0], amount})
+		}
+	}
+
+	return result
+}
+
+/*
+ * Record depth matched by an order filled on placement, levels are consumed best price first
+ *
+ * @param string pair
+ * @param string side
+ * @param [][2]float64 levels
+ * @param float64 amount
+ *
+ * @return void
+ */
+func (p *PaperTrader) consume(pair, side string, levels [][2]float64, amount float64) {
+	if amount <= simulatedEpsilon {
+		return
+	}
+
+	if p.consumed[pair] == nil {
+		p.consumed[pair] = map[paperLevel]float64{}
+	}
+
+	for _, level := range levels {
+		if amount <= simulatedEpsilon {
+			return
+		}
+
+		used := math.Min(level[1], amount)
+
+		p.consumed[pair][paperLevel{side: side, price: level[0]}] += used
+		amount -= used
+	}
+}
+
+func (p *PaperTrader) Run(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			if err := p.Match(); err != nil {
+				logApiError(p.market, "failed to match paper orders", err)
+			}
+		}
+	}
+}
+
+func (p *PaperTrader) loadFees() error {
+	p.mu.Lock()
+	loaded := p.feesLoaded
+	p.mu.Unlock()
+
+	if loaded {
+		return nil
+	}
+
+	pairs, err := p.market.GetPairs()
+	if err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, pair := range *pairs {
+		key := strings.ToLower(pair.TickerId)
+		if len(key) == 0 {
+			key = strings.ToLower(pair.Id)
+		}
+
+		if _, exist := p.feeOverride[key]; !exist {
+			p.fees[key] = pairFee(pair)
+		}
+	}
+
+	p.feesLoaded = true
+
+	return nil
+}
+
+/*
+ * Convert depth side to sorted price levels, asks ascending and bids descending
+ *
+ * @param [][2]json.Number side
+ * @param bool descending
+ *
+ * @return [][2]float64
+ */
+func depthLevels(side [][2]json.Number, descending bool) [][2]float64 {
+	levels := make([][2]float64, 0, len(side))
+
+	for _, row := range side {
+		levels = append(levels, [2]float64{toFloat(row[0]), toFloat(row[1])})
+	}
+
+	sort.SliceStable(levels, func(i, j int) bool {
+		if descending {
+			return levels[i][0] > levels[j][0]
+		}
+
+		return levels[i][0] < levels[j][0]
+	})
+
+	return levels
+}
+
+func publicPairId(pair string) string {
+	return strings.ReplaceAll(strings.ToLower(pair), "_", "")
+}
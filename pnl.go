@@ -0,0 +1,353 @@
+package indodax
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+type PnLEngine struct {
+	method   string
+	mu       sync.Mutex
+	books    map[string]*pnlBook
+	trades   map[string][]OwnTrade
+	seen     map[string]bool
+	realized []RealizedPnL
+}
+
+type pnlBook struct {
+	lots []pnlLot
+}
+
+type pnlLot struct {
+	amount   float64
+	unitCost float64
+}
+
+func NewPnLEngine(method string) (*PnLEngine, error) {
+	method = strings.ToLower(method)
+
+	if len(method) == 0 {
+		method = LotMethodFifo
+	}
+
+	switch method {
+	case LotMethodFifo, LotMethodLifo, LotMethodAverage:
+	default:
+		return nil, fmt.Errorf("invalid lot method %s", method)
+	}
+
+	return &PnLEngine{
+		method: method,
+		books:  map[string]*pnlBook{},
+		trades: map[string][]OwnTrade{},
+		seen:   map[string]bool{},
+	}, nil
+}
+
+func (e *PnLEngine) Sync(client TradingAPI, pairs []string, since *time.Time) error {
+	for _, pair := range pairs {
+		trades, err := getOwnTrades(client, pair, since, nil)
+		if err != nil {
+			return err
+		}
+
+		e.Ingest(trades...)
+	}
+
+	return nil
+}
+
+/*
+ * Ingest own trades, trades are processed in chronological order and
+ * trades that have been ingested before are ignored. A trade older than the
+ * trades already ingested for its pair rebuilds the lots of the pair
+ *
+ * @param ...OwnTrade trades
+ *
+ * @return void
+ */
+func (e *PnLEngine) Ingest(trades ...OwnTrade) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	added := map[string][]OwnTrade{}
+
+	for _, trade := range trades {
+		key := fmt.Sprintf("%s:%s", trade.Pair, trade.TradeId)
+		if len(trade.TradeId) > 0 && e.seen[key] {
+			continue
+		}
+
+		e.seen[key] = true
+
+		if trade.Amount <= 0 {
+			continue
+		}
+
+		added[trade.Pair] = append(added[trade.Pair], trade)
+	}
+
+	pairs := make([]string, 0, len(added))
+
+	for pair := range added {
+		pairs = append(pairs, pair)
+	}
+
+	sort.Strings(pairs)
+
+	rebuilt := false
+
+	for _, pair := range pairs {
+		sorted := added[pair]
+
+		sort.SliceStable(sorted, func(i, j int) bool {
+			return sorted[i].Time.Before(sorted[j].Time)
+		})
+
+		existing := e.trades[pair]
+
+		if len(existing) == 0 || !sorted[0].Time.Before(existing[len(existing)-1].Time) {
+			e.trades[pair] = append(existing, sorted...)
+
+			for _, trade := range sorted {
+				e.apply(trade)
+			}
+
+			continue
+		}
+
+		merged := append(append(make([]OwnTrade, 0, len(existing)+len(sorted)), existing...), sorted...)
+
+		sort.SliceStable(merged, func(i, j int) bool {
+			return merged[i].Time.Before(merged[j].Time)
+		})
+
+		e.trades[pair] = merged
+		e.rebuild(pair)
+
+		rebuilt = true
+	}
+
+	if rebuilt {
+		sort.SliceStable(e.realized, func(i, j int) bool {
+			return e.realized[i].Time.Before(e.realized[j].Time)
+		})
+	}
+}
+
+func (e *PnLEngine) Realized(from, to time.Time) []RealizedPnL {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	var result []RealizedPnL
+
+	for _, r := range e.realized {
+		if (from.IsZero() || !r.Time.Before(from)) && (to.IsZero() || r.Time.Before(to)) {
+			result = append(result, r)
+		}
+	}
+
+	return result
+}
+
+func (e *PnLEngine) Report(period string, from, to time.Time) ([]PnLPeriod, error) {
+	layout, err := periodLayout(period)
+	if err != nil {
+		return nil, err
+	}
+
+	index := map[string]*PnLPeriod{}
+
+	var keys []string
+
+	for _, r := range e.Realized(from, to) {
+		label := r.Time.Format(layout)
+		key := fmt.Sprintf("%s|%s", label, r.Pair)
+
+		p, exist := index[key]
+		if !exist {
+			p = &PnLPeriod{Pair: r.Pair, Period: label}
+			index[key] = p
+			keys = append(keys, key)
+		}
+
+		p.Realized += r.PnL
+		p.Fees += r.Fee
+		p.Volume += r.Proceeds + r.Fee
+		p.Trades++
+	}
+
+	sort.Strings(keys)
+
+	result := make([]PnLPeriod, 0, len(keys))
+
+	for _, key := range keys {
+		result = append(result, *index[key])
+	}
+
+	return result, nil
+}
+
+func (e *PnLEngine) Unrealized(tickers map[string]map[string]interface{}, priceSource string) []UnrealizedPnL {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if len(priceSource) == 0 {
+		priceSource = PriceSourceLast
+	}
+
+	var result []UnrealizedPnL
+
+	for pair, book := range e.books {
+		u := UnrealizedPnL{Pair: pair}
+
+		for _, lot := range book.lots {
+			u.Amount += lot.amount
+			u.CostBasis += lot.amount * lot.unitCost
+		}
+
+		if u.Amount <= 0 {
+			continue
+		}
+
+		u.AverageCost = u.CostBasis / u.Amount
+
+		if price, ok := tickerPrice(tickers, pair, priceSource); ok {
+			u.Price = price
+			u.MarketValue = price * u.Amount
+			u.PnL = u.MarketValue - u.CostBasis
+		}
+
+		result = append(result, u)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Pair < result[j].Pair
+	})
+
+	return result
+}
+
+func (e *PnLEngine) apply(trade OwnTrade) {
+	book, exist := e.books[trade.Pair]
+	if !exist {
+		book = &pnlBook{}
+		e.books[trade.Pair] = book
+	}
+
+	switch trade.Type {
+	case TradeTypeBuy:
+		book.buy(e.method, trade)
+	case TradeTypeSell:
+		e.realized = append(e.realized, book.sell(e.method, trade))
+	}
+}
+
+/*
+ * Replay the trades of a pair from empty lots, realized results of the pair are replaced
+ *
+ * @param string pair
+ *
+ * @return void
+ */
+func (e *PnLEngine) rebuild(pair string) {
+	realized := e.realized[:0]
+
+	for _, r := range e.realized {
+		if r.Pair != pair {
+			realized = append(realized, r)
+		}
+	}
+
+	e.realized = realized
+	e.books[pair] = &pnlBook{}
+
+	for _, trade := range e.trades[pair] {
+		e.apply(trade)
+	}
+}
+
+func (b *pnlBook) buy(method string, trade OwnTrade) {
+	unitCost := (trade.Price*trade.Amount + trade.Fee) / trade.Amount
+
+	if method != LotMethodAverage || len(b.lots) == 0 {
+		b.lots = append(b.lots, pnlLot{amount: trade.Amount, unitCost: unitCost})
+		return
+	}
+
+	lot := &b.lots[0]
+	total := lot.amount + trade.Amount
+
+	lot.unitCost = (lot.amount*lot.unitCost + trade.Amount*unitCost) / total
+	lot.amount = total
+}
+
+/*
+ * Consume lots for a sell trade, amounts sold beyond the known lots are
+ * reported as unmatched and carry no cost basis
+ *
+ * @param string method
+ * @param OwnTrade trade
+ *
+ * @return RealizedPnL
+ */
+func (b *pnlBook) sell(method string, trade OwnTrade) RealizedPnL {
+	r := RealizedPnL{
+		Pair:     trade.Pair,
+		TradeId:  trade.TradeId,
+		Time:     trade.Time,
+		Amount:   trade.Amount,
+		Proceeds: trade.Price*trade.Amount - trade.Fee,
+		Fee:      trade.Fee,
+	}
+
+	remaining := trade.Amount
+
+	for remaining > 0 && len(b.lots) > 0 {
+		i := 0
+		if method == LotMethodLifo {
+			i = len(b.lots) - 1
+		}
+
+		lot := &b.lots[i]
+		used := remaining
+
+		if lot.amount < used {
+			used = lot.amount
+		}
+
+		r.CostBasis += used * lot.unitCost
+		lot.amount -= used
+		remaining -= used
+
+		if lot.amount <= 1e-12 {
+			b.lots = append(b.lots[:i], b.lots[i+1:]...)
+		}
+	}
+
+	if remaining > 1e-12 {
+		r.UnmatchedAmount = remaining
+	}
+
+	matched := (trade.Amount - r.UnmatchedAmount) / trade.Amount
+
+	r.PnL = r.Proceeds*matched - r.CostBasis
+
+	return r
+}
+
+func periodLayout(period string) (string, error) {
+	switch period {
+	case PeriodDaily:
+		return "2006-01-02", nil
+	case PeriodMonthly:
+		return "2006-01", nil
+	case PeriodYearly:
+		return "2006", nil
+	}
+
+	return "", fmt.Errorf("invalid period %s", period)
+}
@@ -0,0 +1,73 @@
+package indodax
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func pnlTrade(id, side string, price, amount, fee float64, at time.Time) OwnTrade {
+	return OwnTrade{TradeId: id, Pair: "btc_idr", Type: side, Price: price, Amount: amount, Fee: fee, Time: at}
+}
+
+func TestPnLEngineLotMethods(t *testing.T) {
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	trades := []OwnTrade{
+		pnlTrade("1", TradeTypeBuy, 100, 1, 0, base),
+		pnlTrade("2", TradeTypeBuy, 200, 1, 0, base.Add(time.Hour)),
+		pnlTrade("3", TradeTypeSell, 300, 1, 10, base.Add(2*time.Hour)),
+	}
+
+	tests := map[string]float64{
+		LotMethodFifo:    300 - 10 - 100,
+		LotMethodLifo:    300 - 10 - 200,
+		LotMethodAverage: 300 - 10 - 150,
+	}
+
+	for method, expected := range tests {
+		engine, err := NewPnLEngine(method)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		engine.Ingest(trades...)
+
+		realized := engine.Realized(time.Time{}, time.Time{})
+		if len(realized) != 1 || math.Abs(realized[0].PnL-expected) > 1e-9 {
+			t.Errorf("%s: expected pnl %v, got %+v", method, expected, realized)
+		}
+	}
+}
+
+func TestPnLEngineIngestOutOfOrder(t *testing.T) {
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	engine, err := NewPnLEngine(LotMethodFifo)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	engine.Ingest(
+		pnlTrade("2", TradeTypeBuy, 200, 1, 0, base.Add(time.Hour)),
+		pnlTrade("3", TradeTypeSell, 300, 1, 0, base.Add(2*time.Hour)),
+	)
+
+	// an older buy arriving later must be matched first by fifo
+	engine.Ingest(pnlTrade("1", TradeTypeBuy, 100, 1, 0, base))
+	engine.Ingest(pnlTrade("1", TradeTypeBuy, 100, 1, 0, base))
+
+	realized := engine.Realized(time.Time{}, time.Time{})
+	if len(realized) != 1 {
+		t.Fatalf("expected 1 realized result, got %+v", realized)
+	}
+
+	if realized[0].CostBasis != 100 || realized[0].PnL != 200 || realized[0].UnmatchedAmount != 0 {
+		t.Fatalf("expected the sell to be matched against the oldest buy, got %+v", realized[0])
+	}
+
+	unrealized := engine.Unrealized(nil, "")
+	if len(unrealized) != 1 || unrealized[0].Amount != 1 || unrealized[0].CostBasis != 200 {
+		t.Fatalf("expected the 200 lot to remain open, got %+v", unrealized)
+	}
+}
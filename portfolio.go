@@ -0,0 +1,218 @@
+package indodax
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+func (c *Client) GetPortfolioValuation(config PortfolioConfig) (*PortfolioValuation, error) {
+	info, err := c.GetInfo()
+	if err != nil {
+		return nil, err
+	}
+
+	tickers, err := c.GetTickerAll()
+	if err != nil {
+		return nil, err
+	}
+
+	free := map[string]float64{}
+	held := map[string]float64{}
+
+	for currency, amount := range info.Balance {
+		free[strings.ToLower(currency)] = toFloat(amount)
+	}
+
+	for currency, amount := range info.BalanceHold {
+		held[strings.ToLower(currency)] = toFloat(amount)
+	}
+
+	return ValuePortfolio(free, held, tickers.Tickers, config)
+}
+
+/*
+ * Mark free and held balances to idr, and optionally usdt, using ticker prices.
+ * Assets without a direct idr pair are routed through the configured intermediates.
+ *
+ * @param map[string]float64 free
+ * @param map[string]float64 held
+ * @param map[string]map[string]interface{} tickers
+ * @param PortfolioConfig config
+ *
+ * @return *PortfolioValuation
+ * @return error
+ */
+func ValuePortfolio(free, held map[string]float64, tickers map[string]map[string]interface{}, config PortfolioConfig) (*PortfolioValuation, error) {
+	if len(config.PriceSource) == 0 {
+		config.PriceSource = PriceSourceLast
+	}
+
+	switch config.PriceSource {
+	case PriceSourceLast, PriceSourceBid, PriceSourceAsk, PriceSourceMid:
+	default:
+		return nil, fmt.Errorf("invalid price source %s", config.PriceSource)
+	}
+
+	if config.Intermediates == nil {
+		config.Intermediates = []string{CurrencyUsdt, CurrencyBtc}
+	}
+
+	valuation := PortfolioValuation{
+		Time:        time.Now(),
+		PriceSource: config.PriceSource,
+	}
+
+	currencies := map[string]bool{}
+
+	for currency := range free {
+		currencies[currency] = true
+	}
+
+	for currency := range held {
+		currencies[currency] = true
+	}
+
+	for currency := range currencies {
+		asset := AssetValuation{
+			Currency: currency,
+			Free:     free[currency],
+			Held:     held[currency],
+		}
+
+		asset.Total = asset.Free + asset.Held
+
+		if asset.Total == 0 {
+			continue
+		}
+
+		price, route, ok := priceInIdr(tickers, currency, config.PriceSource, config.Intermediates)
+		if !ok {
+			valuation.Unpriced = append(valuation.Unpriced, currency)
+			continue
+		}
+
+		asset.Price = price
+		asset.Value = price * asset.Total
+		asset.Route = route
+
+		valuation.Total += asset.Value
+		valuation.Assets = append(valuation.Assets, asset)
+	}
+
+	if config.IncludeUsdt {
+		usdtPrice, ok := tickerPrice(tickers, fmt.Sprintf("%s_%s", CurrencyUsdt, CurrencyIdr), config.PriceSource)
+		if !ok || usdtPrice <= 0 {
+			return nil, errors.New("usdt idr price is not available")
+		}
+
+		valuation.UsdtIdrPrice = usdtPrice
+		valuation.TotalUsdt = valuation.Total / usdtPrice
+	}
+
+	for i := range valuation.Assets {
+		if valuation.Total > 0 {
+			valuation.Assets[i].Weight = valuation.Assets[i].Value / valuation.Total
+		}
+
+		if valuation.UsdtIdrPrice > 0 {
+			valuation.Assets[i].ValueUsdt = valuation.Assets[i].Value / valuation.UsdtIdrPrice
+		}
+	}
+
+	sort.Slice(valuation.Assets, func(i, j int) bool {
+		return valuation.Assets[i].Value > valuation.Assets[j].Value
+	})
+
+	sort.Strings(valuation.Unpriced)
+
+	return &valuation, nil
+}
+
+/*
+ * Get price of a currency in idr, directly or through an intermediate currency
+ *
+ * @param map[string]map[string]interface{} tickers
+ * @param string currency
+ * @param string source
+ * @param []string intermediates
+ *
+ * @return float64
+ * @return []string
+ * @return bool
+ */
+func priceInIdr(tickers map[string]map[string]interface{}, currency, source string, intermediates []string) (float64, []string, bool) {
+	if currency == CurrencyIdr {
+		return 1, nil, true
+	}
+
+	direct := fmt.Sprintf("%s_%s", currency, CurrencyIdr)
+
+	if price, ok := tickerPrice(tickers, direct, source); ok {
+		return price, []string{direct}, true
+	}
+
+	for _, intermediate := range intermediates {
+		if intermediate == currency {
+			continue
+		}
+
+		first := fmt.Sprintf("%s_%s", currency, intermediate)
+		second := fmt.Sprintf("%s_%s", intermediate, CurrencyIdr)
+
+		crossPrice, ok := tickerPrice(tickers, first, source)
+		if !ok {
+			continue
+		}
+
+		idrPrice, ok := tickerPrice(tickers, second, source)
+		if !ok {
+			continue
+		}
+
+		return crossPrice * idrPrice, []string{first, second}, true
+	}
+
+	return 0, nil, false
+}
+
+/*
+ * Get price from ticker by price source
+ *
+ * @param map[string]map[string]interface{} tickers
+ * @param string pair
+ * @param string source
+ *
+ * @return float64
+ * @return bool
+ */
+func tickerPrice(tickers map[string]map[string]interface{}, pair, source string) (float64, bool) {
+	ticker, exist := tickers[strings.ToLower(pair)]
+	if !exist {
+		ticker, exist = tickers[strings.ReplaceAll(strings.ToLower(pair), "_", "")]
+	}
+
+	if !exist {
+		return 0, false
+	}
+
+	var price float64
+
+	switch source {
+	case PriceSourceBid:
+		price = toFloat(ticker["buy"])
+	case PriceSourceAsk:
+		price = toFloat(ticker["sell"])
+	case PriceSourceMid:
+		bid, ask := toFloat(ticker["buy"]), toFloat(ticker["sell"])
+		if bid > 0 && ask > 0 {
+			price = (bid + ask) / 2
+		}
+	default:
+		price = toFloat(ticker["last"])
+	}
+
+	return price, price > 0
+}
@@ -0,0 +1,156 @@
+package indodax
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestValuePortfolio(t *testing.T) {
+	market := &fakeMarket{tickers: map[string]map[string]interface{}{
+		"btc_idr":  {"last": "1000000000", "buy": "990000000", "sell": "1010000000"},
+		"usdt_idr": {"last": "16000", "buy": "15900", "sell": "16100"},
+		"xyz_usdt": {"last": "2", "buy": "1.9", "sell": "2.1"},
+		"abc_btc":  {"last": "0.001", "buy": "0.001", "sell": "0.001"},
+		"ethidr":   {"last": "50000000", "buy": "50000000", "sell": "50000000"},
+		"dead_idr": {"last": "0", "buy": "0", "sell": "0"},
+	}}
+
+	resp, err := market.GetTickerAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type asset struct {
+		price float64
+		value float64
+		route []string
+	}
+
+	tests := []struct {
+		name      string
+		free      map[string]float64
+		held      map[string]float64
+		config    PortfolioConfig
+		assets    map[string]asset
+		total     float64
+		totalUsdt float64
+		unpriced  []string
+		fails     bool
+	}{
+		{
+			name:   "direct pairs",
+			free:   map[string]float64{"idr": 500000, "btc": 0.001},
+			held:   map[string]float64{"btc": 0.001, "eth": 0},
+			assets: map[string]asset{"idr": {1, 500000, nil}, "btc": {1000000000, 2000000, []string{"btc_idr"}}},
+			total:  2500000,
+		},
+		{
+			name:   "pair id without underscore",
+			free:   map[string]float64{"eth": 0.1},
+			assets: map[string]asset{"eth": {50000000, 5000000, []string{"eth_idr"}}},
+			total:  5000000,
+		},
+		{
+			name:   "cross rate through usdt",
+			free:   map[string]float64{"xyz": 10},
+			assets: map[string]asset{"xyz": {32000, 320000, []string{"xyz_usdt", "usdt_idr"}}},
+			total:  320000,
+		},
+		{
+			name:   "cross rate through btc",
+			free:   map[string]float64{"abc": 3},
+			assets: map[string]asset{"abc": {1000000, 3000000, []string{"abc_btc", "btc_idr"}}},
+			total:  3000000,
+		},
+		{
+			name:     "missing and zero priced tickers",
+			free:     map[string]float64{"idr": 100, "nope": 1, "dead": 5, "abc": 1},
+			config:   PortfolioConfig{Intermediates: []string{CurrencyUsdt}},
+			assets:   map[string]asset{"idr": {1, 100, nil}},
+			total:    100,
+			unpriced: []string{"abc", "dead", "nope"},
+		},
+		{
+			name:   "mid price",
+			free:   map[string]float64{"xyz": 10},
+			config: PortfolioConfig{PriceSource: PriceSourceMid},
+			assets: map[string]asset{"xyz": {32000, 320000, []string{"xyz_usdt", "usdt_idr"}}},
+			total:  320000,
+		},
+		{
+			name:   "bid price",
+			free:   map[string]float64{"btc": 1},
+			config: PortfolioConfig{PriceSource: PriceSourceBid},
+			assets: map[string]asset{"btc": {990000000, 990000000, []string{"btc_idr"}}},
+			total:  990000000,
+		},
+		{
+			name:      "usdt totals",
+			free:      map[string]float64{"idr": 32000, "usdt": 2},
+			config:    PortfolioConfig{IncludeUsdt: true},
+			assets:    map[string]asset{"idr": {1, 32000, nil}, "usdt": {16000, 32000, []string{"usdt_idr"}}},
+			total:     64000,
+			totalUsdt: 4,
+		},
+		{
+			name:   "invalid price source",
+			free:   map[string]float64{"idr": 1},
+			config: PortfolioConfig{PriceSource: "close"},
+			fails:  true,
+		},
+	}
+
+	for _, test := range tests {
+		valuation, err := ValuePortfolio(test.free, test.held, resp.Tickers, test.config)
+		if test.fails {
+			if err == nil {
+				t.Errorf("%s: expected an error", test.name)
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+
+		if math.Abs(valuation.Total-test.total) > 1e-6 || math.Abs(valuation.TotalUsdt-test.totalUsdt) > 1e-9 {
+			t.Errorf("%s: expected total %v (%v usdt), got %v (%v usdt)", test.name, test.total, test.totalUsdt, valuation.Total, valuation.TotalUsdt)
+		}
+
+		if !reflect.DeepEqual(valuation.Unpriced, test.unpriced) {
+			t.Errorf("%s: expected unpriced %v, got %v", test.name, test.unpriced, valuation.Unpriced)
+		}
+
+		if len(valuation.Assets) != len(test.assets) {
+			t.Errorf("%s: expected %d assets, got %+v", test.name, len(test.assets), valuation.Assets)
+			continue
+		}
+
+		for i, got := range valuation.Assets {
+			want := test.assets[got.Currency]
+
+			if math.Abs(got.Price-want.price) > 1e-6 || math.Abs(got.Value-want.value) > 1e-6 || !reflect.DeepEqual(got.Route, want.route) {
+				t.Errorf("%s: expected %s %+v, got %+v", test.name, got.Currency, want, got)
+			}
+
+			if math.Abs(got.Weight-got.Value/valuation.Total) > 1e-12 {
+				t.Errorf("%s: unexpected weight of %s: %v", test.name, got.Currency, got.Weight)
+			}
+
+			if i > 0 && got.Value > valuation.Assets[i-1].Value {
+				t.Errorf("%s: expected assets sorted by value, got %+v", test.name, valuation.Assets)
+			}
+		}
+	}
+}
+
+func TestValuePortfolioWithoutUsdtPrice(t *testing.T) {
+	tickers := map[string]map[string]interface{}{"btc_idr": {"last": "1000"}}
+
+	if _, err := ValuePortfolio(map[string]float64{"btc": 1}, nil, tickers, PortfolioConfig{IncludeUsdt: true}); err == nil {
+		t.Fatal("expected an error without usdt price")
+	}
+}
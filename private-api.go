@@ -1,18 +1,26 @@
 package indodax
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"github.com/vannleonheart/goutil"
+	"log/slog"
 	"strings"
 	"time"
 )
 
+var ErrOrderNotFound = errors.New("order not found")
+
 func (c *Client) PrivateApiCall(method string, data *map[string]interface{}) (*ResponseBody, error) {
+	return c.PrivateApiCallContext(c.context(), method, data)
+}
+
+func (c *Client) PrivateApiCallContext(ctx context.Context, method string, data *map[string]interface{}) (*ResponseBody, error) {
 	var respBody ResponseBody
 
-	if err := c.PrivateApiCallWithCustomResult(method, data, &respBody); err != nil {
+	if err := c.PrivateApiCallWithCustomResultContext(ctx, method, data, &respBody); err != nil {
 		return nil, err
 	}
 
@@ -20,6 +28,10 @@ func (c *Client) PrivateApiCall(method string, data *map[string]interface{}) (*R
 }
 
 func (c *Client) PrivateApiCallWithCustomResult(method string, data *map[string]interface{}, result interface{}) error {
+	return c.PrivateApiCallWithCustomResultContext(c.context(), method, data, result)
+}
+
+func (c *Client) PrivateApiCallWithCustomResultContext(ctx context.Context, method string, data *map[string]interface{}, result interface{}) error {
 	if len(c.Config.PrivateApiBaseUrl) == 0 {
 		err := errors.New("private api base url is required")
 
@@ -29,9 +41,8 @@ func (c *Client) PrivateApiCallWithCustomResult(method string, data *map[string]
 			errData = *data
 		}
 
-		c.log("error", map[string]interface{}{
-			"error":   err.Error(),
-			"message": "private api base url is required when calling private api",
+		c.log(slog.LevelError, "private api base url is required when calling private api", map[string]interface{}{
+			"error": err.Error(),
 			"data": map[string]interface{}{
 				"method": method,
 				"data":   errData,
@@ -41,30 +52,6 @@ func (c *Client) PrivateApiCallWithCustomResult(method string, data *map[string]
 		return err
 	}
 
-	targetUrl := fmt.Sprintf("%s/tapi", c.Config.PrivateApiBaseUrl)
-
-	if c.Credential == nil {
-		err := errors.New("credential is required")
-
-		var errData map[string]interface{}
-
-		if data != nil {
-			errData = *data
-		}
-
-		c.log("error", map[string]interface{}{
-			"error":   err.Error(),
-			"message": "credential is required when calling private api",
-			"data": map[string]interface{}{
-				"url":    targetUrl,
-				"method": method,
-				"data":   errData,
-			},
-		})
-
-		return err
-	}
-
 	reqBody := map[string]interface{}{
 		"method":     method,
 		"timestamp":  time.Now().UnixMilli(),
@@ -77,42 +64,20 @@ func (c *Client) PrivateApiCallWithCustomResult(method string, data *map[string]
 		}
 	}
 
-	signature, err := c.generateSign(reqBody)
-	if err != nil {
-		c.log("error", map[string]interface{}{
-			"error":   err.Error(),
-			"message": "error generate signature when calling private api",
-			"data": map[string]interface{}{
-				"url":     targetUrl,
-				"request": reqBody,
-			},
-		})
-
-		return err
-	}
-
-	if signature == nil || len(*signature) <= 0 {
-		err = errors.New("signature is required")
-
-		c.log("error", map[string]interface{}{
-			"error":   err.Error(),
-			"message": "signature is required when calling private api",
-			"data": map[string]interface{}{
-				"url":     targetUrl,
-				"request": reqBody,
-			},
-		})
-
-		return err
-	}
-
-	reqHeader := map[string]string{
-		"Content-Type": "application/x-www-form-urlencoded",
-		"Key":          c.Credential.TradeApiKey,
-		"Sign":         *signature,
+	req := &ApiRequest{
+		Context:  ctx,
+		Api:      ApiPrivate,
+		Endpoint: "/tapi",
+		Method:   method,
+		Pair:     toString(reqBody["pair"]),
+		Url:      fmt.Sprintf("%s/tapi", c.Config.PrivateApiBaseUrl),
+		Data:     reqBody,
+		Header: map[string]string{
+			"Content-Type": "application/x-www-form-urlencoded",
+		},
 	}
 
-	raw, err := goutil.SendHttpPost(targetUrl, &reqBody, &reqHeader, result, nil)
+	raw, err := c.chain(c.sendPrivate)(req)
 
 	var responseBodyRaw string
 
@@ -121,13 +86,12 @@ func (c *Client) PrivateApiCallWithCustomResult(method string, data *map[string]
 	}
 
 	if err != nil {
-		c.log("error", map[string]interface{}{
-			"error":   err.Error(),
-			"message": "error send http post when calling private api",
+		c.log(slog.LevelError, "error send http post when calling private api", map[string]interface{}{
+			"error": err.Error(),
 			"data": map[string]interface{}{
-				"url":      targetUrl,
-				"header":   reqHeader,
-				"request":  reqBody,
+				"url":      req.Url,
+				"header":   req.Header,
+				"request":  req.Data,
 				"response": responseBodyRaw,
 			},
 		})
@@ -135,21 +99,92 @@ func (c *Client) PrivateApiCallWithCustomResult(method string, data *map[string]
 		return err
 	}
 
-	c.log("debug", map[string]interface{}{
-		"message": "success send http post when calling private api",
+	c.log(slog.LevelDebug, "success send http post when calling private api", map[string]interface{}{
 		"data": map[string]interface{}{
-			"url":      targetUrl,
-			"header":   reqHeader,
-			"request":  reqBody,
+			"url":      req.Url,
+			"header":   req.Header,
+			"request":  req.Data,
 			"response": responseBodyRaw,
 		},
 	})
 
+	if result != nil && raw != nil {
+		_ = json.Unmarshal(*raw, result)
+	}
+
 	return nil
 }
 
+/*
+ * Sign and send a private request, requests already signed by middleware are sent as they
+ * are, with the Key header of the client signer when the middleware did not set it
+ *
+ * @param *ApiRequest req
+ *
+ * @return *[]byte
+ * @return error
+ */
+func (c *Client) sendPrivate(req *ApiRequest) (*[]byte, error) {
+	if len(req.Header["Sign"]) > 0 && len(req.Header["Key"]) > 0 {
+		return goutil.SendHttpPost(req.Url, &req.Data, &req.Header, nil, c.httpClient(req.Context))
+	}
+
+	signer, err := c.signer()
+	if err != nil {
+		c.log(slog.LevelError, "failed to get signer when calling private api", map[string]interface{}{
+			"error": err.Error(),
+			"data": map[string]interface{}{
+				"url":     req.Url,
+				"method":  req.Method,
+				"request": req.Data,
+			},
+		})
+
+		return nil, err
+	}
+
+	if len(req.Header["Sign"]) == 0 {
+		signature, err := c.generateSign(req.Context, signer, req.Data)
+		if err != nil {
+			c.log(slog.LevelError, "error generate signature when calling private api", map[string]interface{}{
+				"error": err.Error(),
+				"data": map[string]interface{}{
+					"url":     req.Url,
+					"request": req.Data,
+				},
+			})
+
+			return nil, err
+		}
+
+		if signature == nil || len(*signature) <= 0 {
+			err = errors.New("signature is required")
+
+			c.log(slog.LevelError, "signature is required when calling private api", map[string]interface{}{
+				"error": err.Error(),
+				"data": map[string]interface{}{
+					"url":     req.Url,
+					"request": req.Data,
+				},
+			})
+
+			return nil, err
+		}
+
+		req.Header["Sign"] = *signature
+	}
+
+	req.Header["Key"] = signer.Key()
+
+	return goutil.SendHttpPost(req.Url, &req.Data, &req.Header, nil, c.httpClient(req.Context))
+}
+
 func (c *Client) GetInfo() (*GetInfoResponseBody, error) {
-	resp, err := c.PrivateApiCall(MethodGetInfo, nil)
+	return c.GetInfoContext(c.context())
+}
+
+func (c *Client) GetInfoContext(ctx context.Context) (*GetInfoResponseBody, error) {
+	resp, err := c.PrivateApiCallContext(ctx, MethodGetInfo, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -177,7 +212,11 @@ func (c *Client) GetInfo() (*GetInfoResponseBody, error) {
 }
 
 func (c *Client) GetTransactionHistory(fromDate, toDate string) (*GetTransactionHistoryResponseBody, error) {
-	resp, err := c.PrivateApiCall(MethodGetTransactionHistory, &map[string]interface{}{
+	return c.GetTransactionHistoryContext(c.context(), fromDate, toDate)
+}
+
+func (c *Client) GetTransactionHistoryContext(ctx context.Context, fromDate, toDate string) (*GetTransactionHistoryResponseBody, error) {
+	resp, err := c.PrivateApiCallContext(ctx, MethodGetTransactionHistory, &map[string]interface{}{
 		"start": fromDate,
 		"end":   toDate,
 	})
@@ -209,6 +248,10 @@ func (c *Client) GetTransactionHistory(fromDate, toDate string) (*GetTransaction
 }
 
 func (c *Client) GetTradeHistory(pair string, fromId, toId, order *string, since, end, count *int64, orderId *string) (*GetTradeHistoryResponseBody, error) {
+	return c.GetTradeHistoryContext(c.context(), pair, fromId, toId, order, since, end, count, orderId)
+}
+
+func (c *Client) GetTradeHistoryContext(ctx context.Context, pair string, fromId, toId, order *string, since, end, count *int64, orderId *string) (*GetTradeHistoryResponseBody, error) {
 	reqBody := map[string]interface{}{
 		"pair": pair,
 	}
@@ -241,7 +284,7 @@ func (c *Client) GetTradeHistory(pair string, fromId, toId, order *string, since
 		reqBody["order_id"] = *orderId
 	}
 
-	resp, err := c.PrivateApiCall(MethodGetTradeHistory, &reqBody)
+	resp, err := c.PrivateApiCallContext(ctx, MethodGetTradeHistory, &reqBody)
 
 	if err != nil {
 		return nil, err
@@ -270,13 +313,17 @@ func (c *Client) GetTradeHistory(pair string, fromId, toId, order *string, since
 }
 
 func (c *Client) GetOpenOrders(pair *string) (interface{}, error) {
+	return c.GetOpenOrdersContext(c.context(), pair)
+}
+
+func (c *Client) GetOpenOrdersContext(ctx context.Context, pair *string) (interface{}, error) {
 	reqBody := map[string]interface{}{}
 
 	if pair != nil {
 		reqBody["pair"] = *pair
 	}
 
-	resp, err := c.PrivateApiCall(MethodGetOpenOrders, &reqBody)
+	resp, err := c.PrivateApiCallContext(ctx, MethodGetOpenOrders, &reqBody)
 
 	if err != nil {
 		return nil, err
@@ -315,6 +362,10 @@ func (c *Client) GetOpenOrders(pair *string) (interface{}, error) {
 }
 
 func (c *Client) GetOrderHistory(pair string, count, from *int) (*GetOrderHistoryResponseBody, error) {
+	return c.GetOrderHistoryContext(c.context(), pair, count, from)
+}
+
+func (c *Client) GetOrderHistoryContext(ctx context.Context, pair string, count, from *int) (*GetOrderHistoryResponseBody, error) {
 	reqBody := map[string]interface{}{
 		"pair": pair,
 	}
@@ -327,7 +378,7 @@ func (c *Client) GetOrderHistory(pair string, count, from *int) (*GetOrderHistor
 		reqBody["from"] = *from
 	}
 
-	resp, err := c.PrivateApiCall(MethodGetOrderHistory, &reqBody)
+	resp, err := c.PrivateApiCallContext(ctx, MethodGetOrderHistory, &reqBody)
 
 	if err != nil {
 		return nil, err
@@ -356,21 +407,21 @@ func (c *Client) GetOrderHistory(pair string, count, from *int) (*GetOrderHistor
 }
 
 func (c *Client) GetOrder(pair, orderId string) (*GetOrderResponseBody, error) {
-	resp, err := c.PrivateApiCall(MethodGetOrder, &map[string]interface{}{
+	return c.GetOrderContext(c.context(), pair, orderId)
+}
+
+func (c *Client) GetOrderContext(ctx context.Context, pair, orderId string) (*GetOrderResponseBody, error) {
+	resp, err := c.PrivateApiCallContext(ctx, MethodGetOrder, &map[string]interface{}{
 		"pair":     pair,
 		"order_id": orderId,
 	})
 
 	if err != nil {
-		return nil, err
+		return nil, orderLookupError(err)
 	}
 
 	if resp.Success != 1 {
-		if resp.Error != nil {
-			return nil, errors.New(*resp.Error)
-		}
-
-		return nil, errors.New("api call failed")
+		return nil, orderResponseError(resp)
 	}
 
 	jsonString, err := json.Marshal(resp.Return)
@@ -389,20 +440,20 @@ func (c *Client) GetOrder(pair, orderId string) (*GetOrderResponseBody, error) {
 }
 
 func (c *Client) GetOrderByClientOrderId(clientOrderId string) (*GetOrderResponseBody, error) {
-	resp, err := c.PrivateApiCall(MethodGetOrderByClientOrderId, &map[string]interface{}{
+	return c.GetOrderByClientOrderIdContext(c.context(), clientOrderId)
+}
+
+func (c *Client) GetOrderByClientOrderIdContext(ctx context.Context, clientOrderId string) (*GetOrderResponseBody, error) {
+	resp, err := c.PrivateApiCallContext(ctx, MethodGetOrderByClientOrderId, &map[string]interface{}{
 		"client_order_id": clientOrderId,
 	})
 
 	if err != nil {
-		return nil, err
+		return nil, orderLookupError(err)
 	}
 
 	if resp.Success != 1 {
-		if resp.Error != nil {
-			return nil, errors.New(*resp.Error)
-		}
-
-		return nil, errors.New("api call failed")
+		return nil, orderResponseError(resp)
 	}
 
 	jsonString, err := json.Marshal(resp.Return)
@@ -419,7 +470,58 @@ func (c *Client) GetOrderByClientOrderId(clientOrderId string) (*GetOrderRespons
 	return &ret, nil
 }
 
+/*
+ * Place an order, a client order id is generated when none is given. When the
+ * request fails without a definite answer the order is looked up by its client
+ * order id and only placed again when the exchange confirms it does not exist
+ *
+ * @return *ResponseBody
+ * @return error
+ */
 func (c *Client) Trade(tradeType, pair, orderType string, price, amount float64, timeInForce, clientOrderId *string, forceCoinAmount bool) (*ResponseBody, error) {
+	return c.TradeContext(c.context(), tradeType, pair, orderType, price, amount, timeInForce, clientOrderId, forceCoinAmount)
+}
+
+func (c *Client) TradeContext(ctx context.Context, tradeType, pair, orderType string, price, amount float64, timeInForce, clientOrderId *string, forceCoinAmount bool) (*ResponseBody, error) {
+	if (clientOrderId == nil || len(*clientOrderId) == 0) && !c.Config.DisableClientOrderId {
+		id := NewClientOrderId(c.Config.ClientOrderIdPrefix)
+		clientOrderId = &id
+	}
+
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 {
+			c.Metrics.ObserveRetry(ApiPrivate, MethodTrade)
+		}
+
+		resp, err := c.placeOrder(ctx, tradeType, pair, orderType, price, amount, timeInForce, clientOrderId, forceCoinAmount)
+		if err == nil {
+			if ret, ok := resp.Return.(map[string]interface{}); ok && clientOrderId != nil && len(toString(ret["client_order_id"])) == 0 {
+				ret["client_order_id"] = *clientOrderId
+			}
+
+			return resp, nil
+		}
+
+		if clientOrderId == nil || !isAmbiguousError(err) {
+			return nil, err
+		}
+
+		order, lookupErr := c.resolveClientOrder(ctx, *clientOrderId)
+		if lookupErr == nil {
+			return orderResponse(order), nil
+		}
+
+		if !errors.Is(lookupErr, ErrOrderNotFound) {
+			return nil, fmt.Errorf("%w: client order id %s: %w (lookup: %v)", ErrOrderOutcomeUnknown, *clientOrderId, err, lookupErr)
+		}
+
+		if attempt >= DefaultTradeRetries {
+			return nil, err
+		}
+	}
+}
+
+func (c *Client) placeOrder(ctx context.Context, tradeType, pair, orderType string, price, amount float64, timeInForce, clientOrderId *string, forceCoinAmount bool) (*ResponseBody, error) {
 	slPair := strings.Split(pair, "_")
 	if len(slPair) != 2 {
 		return nil, errors.New("invalid pair")
@@ -454,7 +556,7 @@ func (c *Client) Trade(tradeType, pair, orderType string, price, amount float64,
 		reqBody["client_order_id"] = clientOrderId
 	}
 
-	resp, err := c.PrivateApiCall(MethodTrade, &reqBody)
+	resp, err := c.PrivateApiCallContext(ctx, MethodTrade, &reqBody)
 	if err != nil {
 		return nil, err
 	}
@@ -471,6 +573,10 @@ func (c *Client) Trade(tradeType, pair, orderType string, price, amount float64,
 }
 
 func (c *Client) CancelOrder(pair, orderId, tradeType string, orderType *string) (*map[string]interface{}, error) {
+	return c.CancelOrderContext(c.context(), pair, orderId, tradeType, orderType)
+}
+
+func (c *Client) CancelOrderContext(ctx context.Context, pair, orderId, tradeType string, orderType *string) (*map[string]interface{}, error) {
 	reqBody := map[string]interface{}{
 		"pair":     pair,
 		"order_id": orderId,
@@ -481,7 +587,7 @@ func (c *Client) CancelOrder(pair, orderId, tradeType string, orderType *string)
 		reqBody["order_type"] = *orderType
 	}
 
-	resp, err := c.PrivateApiCall(MethodCancelOrder, &reqBody)
+	resp, err := c.PrivateApiCallContext(ctx, MethodCancelOrder, &reqBody)
 
 	if err != nil {
 		return nil, err
@@ -510,11 +616,15 @@ func (c *Client) CancelOrder(pair, orderId, tradeType string, orderType *string)
 }
 
 func (c *Client) CancelOrderByClientOrderId(clientOrderId string) (*map[string]interface{}, error) {
+	return c.CancelOrderByClientOrderIdContext(c.context(), clientOrderId)
+}
+
+func (c *Client) CancelOrderByClientOrderIdContext(ctx context.Context, clientOrderId string) (*map[string]interface{}, error) {
 	reqBody := map[string]interface{}{
 		"client_order_id": clientOrderId,
 	}
 
-	resp, err := c.PrivateApiCall(MethodCancelOrderByClientOrderId, &reqBody)
+	resp, err := c.PrivateApiCallContext(ctx, MethodCancelOrderByClientOrderId, &reqBody)
 
 	if err != nil {
 		return nil, err
@@ -543,6 +653,10 @@ func (c *Client) CancelOrderByClientOrderId(clientOrderId string) (*map[string]i
 }
 
 func (c *Client) Withdraw(requestId, currency, address, network, amount, memo string) (*WithdrawCoinResponseBody, error) {
+	return c.WithdrawContext(c.context(), requestId, currency, address, network, amount, memo)
+}
+
+func (c *Client) WithdrawContext(ctx context.Context, requestId, currency, address, network, amount, memo string) (*WithdrawCoinResponseBody, error) {
 	reqBody := map[string]interface{}{
 		"request_id":       requestId,
 		"currency":         currency,
@@ -562,7 +676,7 @@ func (c *Client) Withdraw(requestId, currency, address, network, amount, memo st
 
 	var result WithdrawCoinResponseBody
 
-	if err := c.PrivateApiCallWithCustomResult(MethodWithdrawCoin, &reqBody, &result); err != nil {
+	if err := c.PrivateApiCallWithCustomResultContext(ctx, MethodWithdrawCoin, &reqBody, &result); err != nil {
 		return nil, err
 	}
 
@@ -578,6 +692,10 @@ func (c *Client) Withdraw(requestId, currency, address, network, amount, memo st
 }
 
 func (c *Client) GetWithdrawFee(currency string, coinNetwork *string) (*map[string]interface{}, error) {
+	return c.GetWithdrawFeeContext(c.context(), currency, coinNetwork)
+}
+
+func (c *Client) GetWithdrawFeeContext(ctx context.Context, currency string, coinNetwork *string) (*map[string]interface{}, error) {
 	reqBody := map[string]interface{}{
 		"currency": currency,
 	}
@@ -586,7 +704,7 @@ func (c *Client) GetWithdrawFee(currency string, coinNetwork *string) (*map[stri
 		reqBody["network"] = c.getNetworkName(currency, *coinNetwork)
 	}
 
-	resp, err := c.PrivateApiCall(MethodWithdrawFee, &reqBody)
+	resp, err := c.PrivateApiCallContext(ctx, MethodWithdrawFee, &reqBody)
 	if err != nil {
 		return nil, err
 	}
@@ -612,3 +730,61 @@ func (c *Client) GetWithdrawFee(currency string, coinNetwork *string) (*map[stri
 
 	return &ret, nil
 }
+
+/*
+ * Error of a failed order lookup response, an order the exchange does not know
+ * matches ErrOrderNotFound
+ *
+ * @param *ResponseBody resp
+ *
+ * @return error
+ */
+func orderResponseError(resp *ResponseBody) error {
+	message := "api call failed"
+	if resp.Error != nil {
+		message = *resp.Error
+	}
+
+	var code string
+	if resp.ErrorCode != nil {
+		code = *resp.ErrorCode
+	}
+
+	if isOrderNotFound(code, message) {
+		return fmt.Errorf("%w: %s", ErrOrderNotFound, message)
+	}
+
+	return errors.New(message)
+}
+
+/*
+ * Wrap a failed order lookup request with ErrOrderNotFound when the response body
+ * of an http error reports the order does not exist
+ *
+ * @param error err
+ *
+ * @return error
+ */
+func orderLookupError(err error) error {
+	var httpErr goutil.HttpResponseError
+
+	if !errors.As(err, &httpErr) || httpErr.ResponseBodyRaw == nil {
+		return err
+	}
+
+	var body ResponseBody
+
+	if json.Unmarshal(*httpErr.ResponseBodyRaw, &body) != nil || body.Success == 1 {
+		return err
+	}
+
+	if errors.Is(orderResponseError(&body), ErrOrderNotFound) {
+		return fmt.Errorf("%w: %w", ErrOrderNotFound, err)
+	}
+
+	return err
+}
+
+func isOrderNotFound(code, message string) bool {
+	return code == ErrorCodeOrderNotFound || strings.Contains(strings.ToLower(message), "order not found")
+}
@@ -1,22 +1,27 @@
 package indodax
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"github.com/vannleonheart/goutil"
+	"log/slog"
 	"strings"
 )
 
 func (c *Client) PublicApiCall(uri string) (*[]byte, error) {
+	return c.PublicApiCallContext(c.context(), uri)
+}
+
+func (c *Client) PublicApiCallContext(ctx context.Context, uri string) (*[]byte, error) {
 	uri = strings.TrimSpace(strings.Trim(uri, "/"))
 
 	if len(uri) == 0 {
 		err := errors.New("uri can not be empty")
 
-		c.log("error", map[string]interface{}{
-			"error":   err.Error(),
-			"message": "uri can not be empty when calling public api",
+		c.log(slog.LevelError, "uri can not be empty when calling public api", map[string]interface{}{
+			"error": err.Error(),
 			"data": map[string]string{
 				"uri": uri,
 			},
@@ -28,9 +33,8 @@ func (c *Client) PublicApiCall(uri string) (*[]byte, error) {
 	if len(c.Config.PublicApiBaseUrl) == 0 {
 		err := errors.New("public api base url can not be empty")
 
-		c.log("error", map[string]interface{}{
-			"error":   err.Error(),
-			"message": "public api base url can not be empty when calling public api",
+		c.log(slog.LevelError, "public api base url can not be empty when calling public api", map[string]interface{}{
+			"error": err.Error(),
 			"data": map[string]interface{}{
 				"uri": uri,
 			},
@@ -39,9 +43,16 @@ func (c *Client) PublicApiCall(uri string) (*[]byte, error) {
 		return nil, err
 	}
 
-	endpoint := fmt.Sprintf("%s/%s", c.Config.PublicApiBaseUrl, uri)
+	req := &ApiRequest{
+		Context:  ctx,
+		Api:      ApiPublic,
+		Endpoint: publicEndpoint(uri),
+		Pair:     publicPair(uri),
+		Url:      fmt.Sprintf("%s/%s", c.Config.PublicApiBaseUrl, uri),
+		Header:   map[string]string{},
+	}
 
-	respBody, err := goutil.SendHttpGet(endpoint, nil, nil, nil, nil)
+	respBody, err := c.chain(c.sendPublic)(req)
 
 	var rs string
 
@@ -50,11 +61,10 @@ func (c *Client) PublicApiCall(uri string) (*[]byte, error) {
 	}
 
 	if err != nil {
-		c.log("error", map[string]interface{}{
-			"error":   err.Error(),
-			"message": "failed to send http get request when calling public api",
+		c.log(slog.LevelError, "failed to send http get request when calling public api", map[string]interface{}{
+			"error": err.Error(),
 			"data": map[string]interface{}{
-				"url":      endpoint,
+				"url":      req.Url,
 				"response": rs,
 			},
 		})
@@ -62,10 +72,9 @@ func (c *Client) PublicApiCall(uri string) (*[]byte, error) {
 		return nil, err
 	}
 
-	c.log("debug", map[string]interface{}{
-		"message": "public api call success",
+	c.log(slog.LevelDebug, "public api call success", map[string]interface{}{
 		"data": map[string]interface{}{
-			"url":      endpoint,
+			"url":      req.Url,
 			"response": rs,
 		},
 	})
@@ -73,8 +82,16 @@ func (c *Client) PublicApiCall(uri string) (*[]byte, error) {
 	return respBody, nil
 }
 
+func (c *Client) sendPublic(req *ApiRequest) (*[]byte, error) {
+	return goutil.SendHttpGet(req.Url, nil, &req.Header, nil, c.httpClient(req.Context))
+}
+
 func (c *Client) PublicApiCallWithCustomResult(uri string, result interface{}) error {
-	resp, err := c.PublicApiCall(uri)
+	return c.PublicApiCallWithCustomResultContext(c.context(), uri, result)
+}
+
+func (c *Client) PublicApiCallWithCustomResultContext(ctx context.Context, uri string, result interface{}) error {
+	resp, err := c.PublicApiCallContext(ctx, uri)
 	if err != nil {
 		return err
 	}
@@ -87,9 +104,13 @@ func (c *Client) PublicApiCallWithCustomResult(uri string, result interface{}) e
 }
 
 func (c *Client) GetServerTime() (*GetServerTimeResponseBody, error) {
+	return c.GetServerTimeContext(c.context())
+}
+
+func (c *Client) GetServerTimeContext(ctx context.Context) (*GetServerTimeResponseBody, error) {
 	var result GetServerTimeResponseBody
 
-	if err := c.PublicApiCallWithCustomResult("/api/server_time", &result); err != nil {
+	if err := c.PublicApiCallWithCustomResultContext(ctx, "/api/server_time", &result); err != nil {
 		return nil, err
 	}
 
@@ -97,9 +118,13 @@ func (c *Client) GetServerTime() (*GetServerTimeResponseBody, error) {
 }
 
 func (c *Client) GetPairs() (*[]Pair, error) {
+	return c.GetPairsContext(c.context())
+}
+
+func (c *Client) GetPairsContext(ctx context.Context) (*[]Pair, error) {
 	var result []Pair
 
-	if err := c.PublicApiCallWithCustomResult("/api/pairs", &result); err != nil {
+	if err := c.PublicApiCallWithCustomResultContext(ctx, "/api/pairs", &result); err != nil {
 		return nil, err
 	}
 
@@ -107,9 +132,13 @@ func (c *Client) GetPairs() (*[]Pair, error) {
 }
 
 func (c *Client) GetPriceIncrements() (*GetPriceIncrementsResponseBody, error) {
+	return c.GetPriceIncrementsContext(c.context())
+}
+
+func (c *Client) GetPriceIncrementsContext(ctx context.Context) (*GetPriceIncrementsResponseBody, error) {
 	var result GetPriceIncrementsResponseBody
 
-	if err := c.PublicApiCallWithCustomResult("/api/price_increments", &result); err != nil {
+	if err := c.PublicApiCallWithCustomResultContext(ctx, "/api/price_increments", &result); err != nil {
 		return nil, err
 	}
 
@@ -117,9 +146,13 @@ func (c *Client) GetPriceIncrements() (*GetPriceIncrementsResponseBody, error) {
 }
 
 func (c *Client) GetSummaries() (*GetSummariesResponseBody, error) {
+	return c.GetSummariesContext(c.context())
+}
+
+func (c *Client) GetSummariesContext(ctx context.Context) (*GetSummariesResponseBody, error) {
 	var result GetSummariesResponseBody
 
-	if err := c.PublicApiCallWithCustomResult("/api/summaries", &result); err != nil {
+	if err := c.PublicApiCallWithCustomResultContext(ctx, "/api/summaries", &result); err != nil {
 		return nil, err
 	}
 
@@ -127,9 +160,13 @@ func (c *Client) GetSummaries() (*GetSummariesResponseBody, error) {
 }
 
 func (c *Client) GetTicker(pairId string) (*GetTickerResponseBody, error) {
+	return c.GetTickerContext(c.context(), pairId)
+}
+
+func (c *Client) GetTickerContext(ctx context.Context, pairId string) (*GetTickerResponseBody, error) {
 	var result GetTickerResponseBody
 
-	if err := c.PublicApiCallWithCustomResult(fmt.Sprintf("/api/ticker/%s", pairId), &result); err != nil {
+	if err := c.PublicApiCallWithCustomResultContext(ctx, fmt.Sprintf("/api/ticker/%s", pairId), &result); err != nil {
 		return nil, err
 	}
 
@@ -137,9 +174,13 @@ func (c *Client) GetTicker(pairId string) (*GetTickerResponseBody, error) {
 }
 
 func (c *Client) GetTickerAll() (*GetTickerAllResponseBody, error) {
+	return c.GetTickerAllContext(c.context())
+}
+
+func (c *Client) GetTickerAllContext(ctx context.Context) (*GetTickerAllResponseBody, error) {
 	var result GetTickerAllResponseBody
 
-	if err := c.PublicApiCallWithCustomResult("/api/ticker_all", &result); err != nil {
+	if err := c.PublicApiCallWithCustomResultContext(ctx, "/api/ticker_all", &result); err != nil {
 		return nil, err
 	}
 
@@ -147,9 +188,13 @@ func (c *Client) GetTickerAll() (*GetTickerAllResponseBody, error) {
 }
 
 func (c *Client) GetTrades(pairId string) (*[]Trade, error) {
+	return c.GetTradesContext(c.context(), pairId)
+}
+
+func (c *Client) GetTradesContext(ctx context.Context, pairId string) (*[]Trade, error) {
 	var result []Trade
 
-	if err := c.PublicApiCallWithCustomResult(fmt.Sprintf("/api/trades/%s", pairId), &result); err != nil {
+	if err := c.PublicApiCallWithCustomResultContext(ctx, fmt.Sprintf("/api/trades/%s", pairId), &result); err != nil {
 		return nil, err
 	}
 
@@ -157,9 +202,13 @@ func (c *Client) GetTrades(pairId string) (*[]Trade, error) {
 }
 
 func (c *Client) GetDepth(pairId string) (*GetDepthResponseBody, error) {
+	return c.GetDepthContext(c.context(), pairId)
+}
+
+func (c *Client) GetDepthContext(ctx context.Context, pairId string) (*GetDepthResponseBody, error) {
 	var result GetDepthResponseBody
 
-	if err := c.PublicApiCallWithCustomResult(fmt.Sprintf("/api/depth/%s", pairId), &result); err != nil {
+	if err := c.PublicApiCallWithCustomResultContext(ctx, fmt.Sprintf("/api/depth/%s", pairId), &result); err != nil {
 		return nil, err
 	}
 
@@ -167,9 +216,13 @@ func (c *Client) GetDepth(pairId string) (*GetDepthResponseBody, error) {
 }
 
 func (c *Client) GetOHLCHistory(pairId, timeFrame string, from, to int64) (*[]OHLC, error) {
+	return c.GetOHLCHistoryContext(c.context(), pairId, timeFrame, from, to)
+}
+
+func (c *Client) GetOHLCHistoryContext(ctx context.Context, pairId, timeFrame string, from, to int64) (*[]OHLC, error) {
 	var result []OHLC
 
-	if err := c.PublicApiCallWithCustomResult(fmt.Sprintf("/tradingview/history_v2?symbol=%s&tf=%s&from=%d&to=%d", pairId, timeFrame, from, to), &result); err != nil {
+	if err := c.PublicApiCallWithCustomResultContext(ctx, fmt.Sprintf("/tradingview/history_v2?symbol=%s&tf=%s&from=%d&to=%d", pairId, timeFrame, from, to), &result); err != nil {
 		return nil, err
 	}
 
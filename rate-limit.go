@@ -0,0 +1,74 @@
+package indodax
+
+import (
+	"errors"
+	"github.com/vannleonheart/goutil"
+	"net/http"
+	"time"
+)
+
+/*
+ * Send a request again while the exchange answers with http 429 or too_many_requests,
+ * up to Config.RateLimitRetries times with a backoff doubling from Config.RateLimitBackoff.
+ * Private requests signed by the client are signed again with a new timestamp, every
+ * wait is recorded on the client metrics
+ *
+ * @param ApiHandler send
+ *
+ * @return ApiHandler
+ */
+func (c *Client) retryRateLimited(send ApiHandler) ApiHandler {
+	return func(req *ApiRequest) (*[]byte, error) {
+		resign := req.Api == ApiPrivate && len(req.Header["Sign"]) == 0
+
+		endpoint := req.Endpoint
+		if req.Api == ApiPrivate {
+			endpoint = req.Method
+		}
+
+		backoff := c.Config.RateLimitBackoff
+		if backoff <= 0 {
+			backoff = DefaultRateLimitBackoff
+		}
+
+		for attempt := 0; ; attempt++ {
+			raw, err := send(req)
+
+			if attempt >= c.Config.RateLimitRetries || !isRateLimited(err, raw) {
+				return raw, err
+			}
+
+			wait := backoff << attempt
+
+			c.Metrics.ObserveRateLimitWait(req.Api, endpoint, wait)
+
+			timer := time.NewTimer(wait)
+
+			select {
+			case <-req.Context.Done():
+				timer.Stop()
+				return raw, err
+			case <-timer.C:
+			}
+
+			c.Metrics.ObserveRetry(req.Api, endpoint)
+
+			if resign {
+				delete(req.Header, "Key")
+				delete(req.Header, "Sign")
+
+				req.Data["timestamp"] = time.Now().UnixMilli()
+			}
+		}
+	}
+}
+
+func isRateLimited(err error, raw *[]byte) bool {
+	var httpErr goutil.HttpResponseError
+
+	if errors.As(err, &httpErr) && httpErr.Code == http.StatusTooManyRequests {
+		return true
+	}
+
+	return metricsErrorCode(err, raw) == ErrorCodeTooManyRequests
+}
@@ -0,0 +1,287 @@
+package indodax
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+/*
+ * Signer computes the HMAC-SHA512 signature of private requests, so the trade api
+ * secret does not have to be held by the client
+ */
+type Signer interface {
+	Key() string
+	Sign(ctx context.Context, payload []byte) (string, error)
+}
+
+func (c *Client) WithSigner(signer Signer) *Client {
+	derived := c.withoutCredential()
+	derived.Signer = signer
+
+	return derived
+}
+
+/*
+ * Signer of the client, set by WithSigner, WithCredentialProvider or WithCredential
+ *
+ * @return Signer
+ * @return error
+ */
+func (c *Client) signer() (Signer, error) {
+	if c.Signer != nil {
+		return c.Signer, nil
+	}
+
+	if c.CredentialProvider != nil {
+		credential, err := c.CredentialProvider.Credential()
+		if err != nil {
+			return nil, err
+		}
+
+		return NewHmacSigner(credential.TradeApiKey, credential.TradeApiSecret), nil
+	}
+
+	if c.Credential == nil {
+		return nil, errors.New("credential is required")
+	}
+
+	return NewHmacSigner(c.Credential.TradeApiKey, c.Credential.TradeApiSecret), nil
+}
+
+type HmacSigner struct {
+	key    string
+	secret string
+}
+
+func NewHmacSigner(key, secret string) *HmacSigner {
+	return &HmacSigner{key: key, secret: secret}
+}
+
+func (s *HmacSigner) Key() string {
+	return s.key
+}
+
+func (s *HmacSigner) Sign(_ context.Context, payload []byte) (string, error) {
+	if len(s.secret) == 0 {
+		return "", errors.New("invalid credential")
+	}
+
+	return hmacSign([]byte(s.secret), payload), nil
+}
+
+/*
+ * CommandSigner runs an external command for every request, the payload is written
+ * to its stdin and the hex encoded signature is read from its stdout
+ */
+type CommandSigner struct {
+	key  string
+	name string
+	args []string
+}
+
+func NewCommandSigner(key, name string, args ...string) *CommandSigner {
+	return &CommandSigner{key: key, name: name, args: args}
+}
+
+func (s *CommandSigner) Key() string {
+	return s.key
+}
+
+func (s *CommandSigner) Sign(ctx context.Context, payload []byte) (string, error) {
+	var stdout, stderr bytes.Buffer
+
+	cmd := exec.CommandContext(ctx, s.name, s.args...)
+	cmd.Stdin = bytes.NewReader(payload)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if message := strings.TrimSpace(stderr.String()); len(message) > 0 {
+			return "", fmt.Errorf("signer command failed: %w: %s", err, message)
+		}
+
+		return "", fmt.Errorf("signer command failed: %w", err)
+	}
+
+	return checkSignature(stdout.String())
+}
+
+/*
+ * SocketSigner asks a signing service listening on a unix socket. The payload is sent
+ * as one line and the service answers with one line holding the hex encoded signature,
+ * or a line starting with "error:"
+ */
+type SocketSigner struct {
+	key     string
+	path    string
+	timeout time.Duration
+}
+
+func NewSocketSigner(key, path string, timeout time.Duration) *SocketSigner {
+	if timeout <= 0 {
+		timeout = DefaultSignerTimeout
+	}
+
+	return &SocketSigner{key: key, path: path, timeout: timeout}
+}
+
+func (s *SocketSigner) Key() string {
+	return s.key
+}
+
+func (s *SocketSigner) Sign(ctx context.Context, payload []byte) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	var dialer net.Dialer
+
+	conn, err := dialer.DialContext(ctx, "unix", s.path)
+	if err != nil {
+		return "", fmt.Errorf("failed to connect to signer: %w", err)
+	}
+
+	defer func() {
+		_ = conn.Close()
+	}()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	if _, err = conn.Write(append(append([]byte{}, payload...), '\n')); err != nil {
+		return "", fmt.Errorf("failed to send payload to signer: %w", err)
+	}
+
+	line, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil && len(line) == 0 {
+		return "", fmt.Errorf("failed to read signature from signer: %w", err)
+	}
+
+	if message, found := strings.CutPrefix(strings.TrimSpace(line), "error:"); found {
+		return "", fmt.Errorf("signer refused to sign: %s", strings.TrimSpace(message))
+	}
+
+	return checkSignature(line)
+}
+
+/*
+ * KeystoreSigner keeps the secret sealed with AES-GCM under a random key, it is only
+ * decrypted while a request is signed and is wiped right after. This shortens the time
+ * the plain secret is in memory and keeps it out of logs and dumps of the struct, but
+ * the sealing key lives in the same process, so anyone able to read the process memory
+ * can still recover the secret
+ */
+type KeystoreSigner struct {
+	key    string
+	mu     sync.Mutex
+	aead   cipher.AEAD
+	nonce  []byte
+	sealed []byte
+}
+
+/*
+ * Create a keystore signer. The signer takes ownership of secret and zeroes it once
+ * it is sealed, pass a copy when the caller still needs the secret
+ *
+ * @param string key
+ * @param []byte secret
+ *
+ * @return *KeystoreSigner
+ * @return error
+ */
+func NewKeystoreSigner(key string, secret []byte) (*KeystoreSigner, error) {
+	if len(secret) == 0 {
+		return nil, errors.New("invalid credential")
+	}
+
+	sealKey := make([]byte, 32)
+
+	if _, err := rand.Read(sealKey); err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(sealKey)
+	clear(sealKey)
+
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+
+	if _, err = rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	sealed := aead.Seal(nil, nonce, secret, []byte(key))
+	clear(secret)
+
+	return &KeystoreSigner{key: key, aead: aead, nonce: nonce, sealed: sealed}, nil
+}
+
+func (s *KeystoreSigner) Key() string {
+	return s.key
+}
+
+func (s *KeystoreSigner) Sign(_ context.Context, payload []byte) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	secret, err := s.aead.Open(nil, s.nonce, s.sealed, []byte(s.key))
+	if err != nil {
+		return "", fmt.Errorf("failed to open keystore: %w", err)
+	}
+
+	defer clear(secret)
+
+	return hmacSign(secret, payload), nil
+}
+
+func hmacSign(secret, payload []byte) string {
+	h := hmac.New(sha512.New, secret)
+
+	h.Write(payload)
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+/*
+ * Check the signature returned by an external signer is a hex encoded HMAC-SHA512
+ *
+ * @param string signature
+ *
+ * @return string
+ * @return error
+ */
+func checkSignature(signature string) (string, error) {
+	signature = strings.ToLower(strings.TrimSpace(signature))
+
+	if len(signature) != sha512.Size*2 {
+		return "", fmt.Errorf("invalid signature length %d", len(signature))
+	}
+
+	if _, err := hex.DecodeString(signature); err != nil {
+		return "", fmt.Errorf("invalid signature: %w", err)
+	}
+
+	return signature, nil
+}
@@ -0,0 +1,150 @@
+package indodax
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// RFC 4231 test case 2
+const (
+	signerTestSecret    = "Jefe"
+	signerTestPayload   = "what do ya want for nothing?"
+	signerTestSignature = "164b7a7bfcf819e2e395fbe73b56e0a387bd64222e831fd610270cd7ea2505549758bf75c05a994a6d034f65f8f0e6fdcaeab1a34d4a6b4b636e070a38bce737"
+)
+
+func TestHmacSigner(t *testing.T) {
+	signature, err := NewHmacSigner("key", signerTestSecret).Sign(context.Background(), []byte(signerTestPayload))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if signature != signerTestSignature {
+		t.Fatalf("unexpected signature %s", signature)
+	}
+
+	if _, err = NewHmacSigner("key", "").Sign(context.Background(), []byte(signerTestPayload)); err == nil {
+		t.Fatal("expected an error without secret")
+	}
+}
+
+func TestKeystoreSigner(t *testing.T) {
+	secret := []byte(signerTestSecret)
+
+	signer, err := NewKeystoreSigner("key", secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(secret) == signerTestSecret {
+		t.Fatal("expected the secret to be wiped once sealed")
+	}
+
+	signature, err := signer.Sign(context.Background(), []byte(signerTestPayload))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if signature != signerTestSignature {
+		t.Fatalf("unexpected signature %s", signature)
+	}
+}
+
+func TestCommandSigner(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh is not available")
+	}
+
+	signature, err := NewCommandSigner("key", "sh", "-c", "cat > /dev/null; echo "+strings.ToUpper(signerTestSignature)).Sign(context.Background(), []byte(signerTestPayload))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if signature != signerTestSignature {
+		t.Fatalf("unexpected signature %s", signature)
+	}
+
+	if _, err = NewCommandSigner("key", "sh", "-c", "echo locked >&2; exit 1").Sign(context.Background(), nil); err == nil || !strings.Contains(err.Error(), "locked") {
+		t.Fatalf("expected the command error, got %v", err)
+	}
+
+	if _, err = NewCommandSigner("key", "sh", "-c", "echo abc").Sign(context.Background(), nil); err == nil {
+		t.Fatal("expected an invalid signature to be rejected")
+	}
+}
+
+func TestSocketSigner(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "signer.sock")
+
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		t.Skipf("unix sockets are not available: %v", err)
+	}
+
+	defer listener.Close()
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+
+			line, _ := bufio.NewReader(conn).ReadString('\n')
+
+			if payload := strings.TrimSuffix(line, "\n"); payload == "refuse" {
+				_, _ = io.WriteString(conn, "error: payload refused\n")
+			} else {
+				_, _ = io.WriteString(conn, hmacSign([]byte(signerTestSecret), []byte(payload))+"\n")
+			}
+
+			_ = conn.Close()
+		}
+	}()
+
+	signer := NewSocketSigner("key", path, time.Second)
+
+	signature, err := signer.Sign(context.Background(), []byte(signerTestPayload))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if signature != signerTestSignature {
+		t.Fatalf("unexpected signature %s", signature)
+	}
+
+	if _, err = signer.Sign(context.Background(), []byte("refuse")); err == nil || !strings.Contains(err.Error(), "payload refused") {
+		t.Fatalf("expected the signer to refuse, got %v", err)
+	}
+}
+
+func TestClientSignsWithSigner(t *testing.T) {
+	var key, sign, body string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+
+		key, sign, body = r.Header.Get("Key"), r.Header.Get("Sign"), string(b)
+
+		_, _ = io.WriteString(w, `{"success":1,"return":{}}`)
+	}))
+	defer server.Close()
+
+	client := New(Config{PrivateApiBaseUrl: server.URL}).WithSigner(NewHmacSigner("signer-key", signerTestSecret))
+
+	if _, err := client.GetInfo(); err != nil {
+		t.Fatal(err)
+	}
+
+	if key != "signer-key" || sign != hmacSign([]byte(signerTestSecret), []byte(body)) {
+		t.Fatalf("unexpected key %q or signature %q of %q", key, sign, body)
+	}
+}
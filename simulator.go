@@ -0,0 +1,690 @@
+package indodax
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	simulatedOrderOpen      = "open"
+	simulatedOrderFilled    = "filled"
+	simulatedOrderCancelled = "cancelled"
+
+	simulatedEpsilon = 1e-12
+)
+
+var ErrNotSupportedBySimulator = errors.New("method is not supported by simulator")
+
+type simulatedOrder struct {
+	orderId       string
+	clientOrderId string
+	pair          string
+	tradeType     string
+	orderType     string
+	timeInForce   string
+	price         float64
+	amount        float64
+	remain        float64
+	reserved      float64
+	status        string
+	submitTime    time.Time
+	finishTime    time.Time
+	lastMatch     time.Time
+}
+
+type simulatedFee struct {
+	maker float64
+	taker float64
+}
+
+/*
+ * simulatedExchange keeps a virtual balance sheet, orders and own trades and
+ * implements the read side of TradingAPI and WalletAPI, order placement and
+ * matching is provided by paper trading and backtesting on top of it
+ */
+type simulatedExchange struct {
+	mu       sync.Mutex
+	balances map[string]float64
+	holds    map[string]float64
+	fees     map[string]simulatedFee
+	orders   []*simulatedOrder
+	trades   []OwnTrade
+	sequence int64
+	now      func() time.Time
+}
+
+func newSimulatedExchange(balances map[string]float64) *simulatedExchange {
+	s := &simulatedExchange{
+		balances: map[string]float64{},
+		holds:    map[string]float64{},
+		fees:     map[string]simulatedFee{},
+		now:      time.Now,
+	}
+
+	for currency, amount := range balances {
+		s.balances[strings.ToLower(currency)] = amount
+	}
+
+	return s
+}
+
+/*
+ * Place an order, crossing part is filled as taker against the given price levels
+ * and the remaining part of a limit order rests on the virtual book
+ *
+ * @param string tradeType
+ * @param string pair
+ * @param string orderType
+ * @param float64 price
+ * @param float64 amount
+ * @param float64 spend
+ * @param string timeInForce
+ * @param string clientOrderId
+ * @param [][2]float64 levels
+ *
+ * @return *ResponseBody
+ * @return error
+ */
+func (s *simulatedExchange) place(tradeType, pair, orderType string, price, amount, spend float64, timeInForce, clientOrderId string, levels [][2]float64) (*ResponseBody, error) {
+	pair = strings.ToLower(pair)
+
+	coin, currency, err := splitPair(pair)
+	if err != nil {
+		return nil, err
+	}
+
+	if tradeType != TradeTypeBuy && tradeType != TradeTypeSell {
+		return nil, errors.New("invalid trade type")
+	}
+
+	if len(orderType) == 0 {
+		orderType = OrderTypeLimit
+	}
+
+	if orderType != OrderTypeMarket && orderType != OrderTypeLimit {
+		return nil, fmt.Errorf("order type %s is not supported by simulator", orderType)
+	}
+
+	if orderType == OrderTypeLimit && (price <= 0 || amount <= 0) {
+		return nil, errors.New("invalid price or amount")
+	}
+
+	if orderType == OrderTypeMarket && amount <= 0 && spend <= 0 {
+		return nil, errors.New("invalid amount")
+	}
+
+	if len(clientOrderId) > 0 && s.findOrder("", clientOrderId) != nil {
+		return nil, errors.New("duplicate client order id")
+	}
+
+	fee := s.fees[pair]
+	crosses := len(levels) > 0 && (orderType == OrderTypeMarket || levelCrosses(tradeType, price, levels[0][0]))
+
+	if timeInForce == TimeInForceMakerOrCancel && crosses {
+		return nil, errors.New("maker or cancel order would be matched immediately")
+	}
+
+	now := s.now()
+
+	o := &simulatedOrder{
+		orderId:       s.nextId(),
+		clientOrderId: clientOrderId,
+		pair:          pair,
+		tradeType:     tradeType,
+		orderType:     orderType,
+		timeInForce:   timeInForce,
+		price:         price,
+		amount:        amount,
+		remain:        amount,
+		status:        simulatedOrderOpen,
+		submitTime:    now,
+		lastMatch:     now,
+	}
+
+	if orderType == OrderTypeLimit {
+		holdCurrency, holdAmount := coin, amount
+		if tradeType == TradeTypeBuy {
+			holdCurrency, holdAmount = currency, price*amount*(1+fee.taker)
+		}
+
+		if s.balances[holdCurrency] < holdAmount-simulatedEpsilon {
+			return nil, errors.New("insufficient balance")
+		}
+
+		s.balances[holdCurrency] -= holdAmount
+		s.holds[holdCurrency] += holdAmount
+		o.reserved = holdAmount
+	}
+
+	var filled, value, fees float64
+
+	for _, level := range levels {
+		levelPrice, levelAmount := level[0], level[1]
+
+		if o.remain <= simulatedEpsilon && orderType == OrderTypeLimit {
+			break
+		}
+
+		if orderType == OrderTypeLimit && !levelCrosses(tradeType, price, levelPrice) {
+			break
+		}
+
+		fillAmount := levelAmount
+
+		if orderType == OrderTypeMarket && tradeType == TradeTypeBuy && spend > 0 {
+			budget := (spend - value - fees) / (levelPrice * (1 + fee.taker))
+			if budget < fillAmount {
+				fillAmount = budget
+			}
+		} else if o.remain < fillAmount {
+			fillAmount = o.remain
+		}
+
+		if fillAmount <= simulatedEpsilon {
+			break
+		}
+
+		if orderType == OrderTypeMarket {
+			fillAmount = s.affordable(o, levelPrice, fillAmount, fee.taker)
+			if fillAmount <= simulatedEpsilon {
+				break
+			}
+		}
+
+		s.fill(o, levelPrice, fillAmount, false)
+
+		filled += fillAmount
+		value += levelPrice * fillAmount
+		fees += levelPrice * fillAmount * fee.taker
+	}
+
+	if orderType == OrderTypeMarket {
+		o.amount = filled
+		o.remain = 0
+		o.price = 0
+
+		if filled > 0 {
+			o.price = value / filled
+			o.status = simulatedOrderFilled
+		} else {
+			o.status = simulatedOrderCancelled
+		}
+
+		o.finishTime = now
+	}
+
+	s.orders = append(s.orders, o)
+
+	ret := map[string]interface{}{
+		"order_id":                     o.orderId,
+		"client_order_id":              o.clientOrderId,
+		fmt.Sprintf("remain_%s", coin): simulatedNumber(o.remain),
+		"balance":                      s.balanceNumbers(s.balances),
+	}
+
+	if tradeType == TradeTypeBuy {
+		ret[fmt.Sprintf("receive_%s", coin)] = simulatedNumber(filled)
+		ret[fmt.Sprintf("spend_%s", currency)] = simulatedNumber(value + fees)
+	} else {
+		ret[fmt.Sprintf("sold_%s", coin)] = simulatedNumber(filled)
+		ret[fmt.Sprintf("receive_%s", currency)] = simulatedNumber(value - fees)
+	}
+
+	return &ResponseBody{Success: 1, Return: ret}, nil
+}
+
+/*
+ * Fill part of an order and settle balances, fee is charged in base currency
+ *
+ * @param *simulatedOrder o
+ * @param float64 price
+ * @param float64 amount
+ * @param bool maker
+ *
+ * @return void
+ */
+func (s *simulatedExchange) fill(o *simulatedOrder, price, amount float64, maker bool) {
+	coin, currency, _ := splitPair(o.pair)
+	fees := s.fees[o.pair]
+
+	rate := fees.taker
+	if maker {
+		rate = fees.maker
+	}
+
+	value := price * amount
+	fee := value * rate
+
+	if o.reserved > 0 {
+		release := o.reserved * amount / o.remain
+
+		holdCurrency := coin
+		if o.tradeType == TradeTypeBuy {
+			holdCurrency = currency
+		}
+
+		s.holds[holdCurrency] -= release
+		s.balances[holdCurrency] += release
+		o.reserved -= release
+	}
+
+	if o.tradeType == TradeTypeBuy {
+		s.balances[currency] -= value + fee
+		s.balances[coin] += amount
+	} else {
+		s.balances[coin] -= amount
+		s.balances[currency] += value - fee
+	}
+
+	o.remain -= amount
+
+	if o.orderType == OrderTypeLimit && o.remain <= simulatedEpsilon {
+		o.remain = 0
+		o.status = simulatedOrderFilled
+		o.finishTime = s.now()
+	}
+
+	s.trades = append(s.trades, OwnTrade{
+		TradeId:       s.nextId(),
+		OrderId:       o.orderId,
+		ClientOrderId: o.clientOrderId,
+		Pair:          o.pair,
+		Type:          o.tradeType,
+		Price:         price,
+		Amount:        amount,
+		Fee:           fee,
+		Time:          s.now(),
+	})
+}
+
+/*
+ * Limit market order fill to what the free balance can pay for
+ *
+ * @param *simulatedOrder o
+ * @param float64 price
+ * @param float64 amount
+ * @param float64 feeRate
+ *
+ * @return float64
+ */
+func (s *simulatedExchange) affordable(o *simulatedOrder, price, amount, feeRate float64) float64 {
+	coin, currency, _ := splitPair(o.pair)
+
+	if o.tradeType == TradeTypeSell {
+		if s.balances[coin] < amount {
+			return s.balances[coin]
+		}
+
+		return amount
+	}
+
+	if limit := s.balances[currency] / (price * (1 + feeRate)); limit < amount {
+		return limit
+	}
+
+	return amount
+}
+
+func (s *simulatedExchange) cancel(o *simulatedOrder) error {
+	if o == nil || o.status != simulatedOrderOpen {
+		return errors.New("order not found or already finished")
+	}
+
+	coin, currency, _ := splitPair(o.pair)
+
+	holdCurrency := coin
+	if o.tradeType == TradeTypeBuy {
+		holdCurrency = currency
+	}
+
+	s.holds[holdCurrency] -= o.reserved
+	s.balances[holdCurrency] += o.reserved
+
+	o.reserved = 0
+	o.status = simulatedOrderCancelled
+	o.finishTime = s.now()
+
+	return nil
+}
+
+func (s *simulatedExchange) GetTradeHistory(pair string, fromId, toId, order *string, since, end, count *int64, orderId *string) (*GetTradeHistoryResponseBody, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	coin, _, err := splitPair(pair)
+	if err != nil {
+		return nil, err
+	}
+
+	trades := []map[string]interface{}{}
+
+	for _, t := range s.trades {
+		if t.Pair != strings.ToLower(pair) {
+			continue
+		}
+
+		if (since != nil && t.Time.Unix() < *since) || (end != nil && t.Time.Unix() > *end) || (orderId != nil && *orderId != t.OrderId) {
+			continue
+		}
+
+		if fromId != nil && compareIds(t.TradeId, *fromId) < 0 {
+			continue
+		}
+
+		if toId != nil && compareIds(t.TradeId, *toId) > 0 {
+			continue
+		}
+
+		trades = append(trades, map[string]interface{}{
+			"trade_id":        t.TradeId,
+			"order_id":        t.OrderId,
+			"client_order_id": t.ClientOrderId,
+			"type":            t.Type,
+			coin:              simulatedNumber(t.Amount),
+			"price":           simulatedNumber(t.Price),
+			"fee":             simulatedNumber(t.Fee),
+			"trade_time":      strconv.FormatInt(t.Time.Unix(), 10),
+		})
+	}
+
+	if order == nil || *order != "asc" {
+		for i, j := 0, len(trades)-1; i < j; i, j = i+1, j-1 {
+			trades[i], trades[j] = trades[j], trades[i]
+		}
+	}
+
+	if count != nil && *count > 0 && int64(len(trades)) > *count {
+		trades = trades[:*count]
+	}
+
+	return &GetTradeHistoryResponseBody{Trades: trades}, nil
+}
+
+func (s *simulatedExchange) GetOpenOrders(pair *string) (interface{}, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if pair != nil {
+		orders := []map[string]interface{}{}
+
+		for _, o := range s.orders {
+			if o.status == simulatedOrderOpen && o.pair == strings.ToLower(*pair) {
+				orders = append(orders, o.toMap())
+			}
+		}
+
+		return &GetPairOpenOrdersResponseBody{Orders: orders}, nil
+	}
+
+	orders := map[string][]map[string]interface{}{}
+
+	for _, o := range s.orders {
+		if o.status == simulatedOrderOpen {
+			orders[o.pair] = append(orders[o.pair], o.toMap())
+		}
+	}
+
+	return &GetOpenOrdersResponseBody{Orders: orders}, nil
+}
+
+func (s *simulatedExchange) GetOrderHistory(pair string, count, from *int) (*GetOrderHistoryResponseBody, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	orders := []map[string]interface{}{}
+
+	for i := len(s.orders) - 1; i >= 0; i-- {
+		if s.orders[i].pair == strings.ToLower(pair) {
+			orders = append(orders, s.orders[i].toMap())
+		}
+	}
+
+	if from != nil && *from > 0 {
+		orders = orders[min(*from, len(orders)):]
+	}
+
+	if count != nil && *count > 0 && len(orders) > *count {
+		orders = orders[:*count]
+	}
+
+	return &GetOrderHistoryResponseBody{Orders: orders}, nil
+}
+
+func (s *simulatedExchange) GetOrder(pair, orderId string) (*GetOrderResponseBody, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	o := s.findOrder(orderId, "")
+	if o == nil || o.pair != strings.ToLower(pair) {
+		return nil, ErrOrderNotFound
+	}
+
+	return &GetOrderResponseBody{Order: o.toMap()}, nil
+}
+
+func (s *simulatedExchange) GetOrderByClientOrderId(clientOrderId string) (*GetOrderResponseBody, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	o := s.findOrder("", clientOrderId)
+	if o == nil {
+		return nil, ErrOrderNotFound
+	}
+
+	return &GetOrderResponseBody{Order: o.toMap()}, nil
+}
+
+func (s *simulatedExchange) CancelOrder(pair, orderId, tradeType string, orderType *string) (*map[string]interface{}, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	o := s.findOrder(orderId, "")
+	if o != nil && o.pair != strings.ToLower(pair) {
+		o = nil
+	}
+
+	if err := s.cancel(o); err != nil {
+		return nil, err
+	}
+
+	return s.cancelResult(o), nil
+}
+
+func (s *simulatedExchange) CancelOrderByClientOrderId(clientOrderId string) (*map[string]interface{}, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	o := s.findOrder("", clientOrderId)
+
+	if err := s.cancel(o); err != nil {
+		return nil, err
+	}
+
+	return s.cancelResult(o), nil
+}
+
+func (s *simulatedExchange) GetInfo() (*GetInfoResponseBody, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return &GetInfoResponseBody{
+		UserId:      "0",
+		Name:        "simulator",
+		Balance:     s.balanceNumbers(s.balances),
+		BalanceHold: s.balanceNumbers(s.holds),
+		Address:     map[string]string{},
+		ServerTime:  s.now().Unix(),
+	}, nil
+}
+
+func (s *simulatedExchange) GetTransactionHistory(fromDate, toDate string) (*GetTransactionHistoryResponseBody, error) {
+	return &GetTransactionHistoryResponseBody{
+		Withdraw: map[string][]map[string]interface{}{},
+		Deposit:  map[string][]map[string]interface{}{},
+	}, nil
+}
+
+func (s *simulatedExchange) Withdraw(requestId, currency, address, network, amount, memo string) (*WithdrawCoinResponseBody, error) {
+	return nil, ErrNotSupportedBySimulator
+}
+
+func (s *simulatedExchange) GetWithdrawFee(currency string, coinNetwork *string) (*map[string]interface{}, error) {
+	return nil, ErrNotSupportedBySimulator
+}
+
+func (s *simulatedExchange) Balances() map[string]float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := map[string]float64{}
+
+	for currency, amount := range s.balances {
+		result[currency] = amount + s.holds[currency]
+	}
+
+	return result
+}
+
+func (s *simulatedExchange) OwnTrades() []OwnTrade {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := make([]OwnTrade, len(s.trades))
+	copy(result, s.trades)
+
+	return result
+}
+
+func (s *simulatedExchange) cancelResult(o *simulatedOrder) *map[string]interface{} {
+	result := map[string]interface{}{
+		"order_id":        o.orderId,
+		"client_order_id": o.clientOrderId,
+		"type":            o.tradeType,
+		"pair":            o.pair,
+		"balance":         s.balanceNumbers(s.balances),
+	}
+
+	return &result
+}
+
+func (s *simulatedExchange) findOrder(orderId, clientOrderId string) *simulatedOrder {
+	for _, o := range s.orders {
+		if (len(orderId) > 0 && o.orderId == orderId) || (len(clientOrderId) > 0 && o.clientOrderId == clientOrderId) {
+			return o
+		}
+	}
+
+	return nil
+}
+
+func (s *simulatedExchange) openOrders() []*simulatedOrder {
+	var result []*simulatedOrder
+
+	for _, o := range s.orders {
+		if o.status == simulatedOrderOpen {
+			result = append(result, o)
+		}
+	}
+
+	return result
+}
+
+func (s *simulatedExchange) balanceNumbers(balances map[string]float64) map[string]json.Number {
+	result := map[string]json.Number{}
+
+	for currency, amount := range balances {
+		result[currency] = simulatedNumber(amount)
+	}
+
+	return result
+}
+
+func (s *simulatedExchange) nextId() string {
+	s.sequence++
+
+	return strconv.FormatInt(s.sequence, 10)
+}
+
+func (o *simulatedOrder) toMap() map[string]interface{} {
+	coin, _, _ := splitPair(o.pair)
+
+	result := map[string]interface{}{
+		"order_id":                     o.orderId,
+		"client_order_id":              o.clientOrderId,
+		"pair":                         o.pair,
+		"type":                         o.tradeType,
+		"order_type":                   o.orderType,
+		"price":                        simulatedNumber(o.price),
+		"status":                       o.status,
+		"submit_time":                  strconv.FormatInt(o.submitTime.Unix(), 10),
+		"finish_time":                  "0",
+		fmt.Sprintf("order_%s", coin):  simulatedNumber(o.amount),
+		fmt.Sprintf("remain_%s", coin): simulatedNumber(o.remain),
+	}
+
+	if !o.finishTime.IsZero() {
+		result["finish_time"] = strconv.FormatInt(o.finishTime.Unix(), 10)
+	}
+
+	return result
+}
+
+/*
+ * Get maker and taker fee rates from pair metadata, the api reports them in percent.
+ * A missing rate falls back to the general rate, a reported 0% is kept
+ *
+ * @param Pair pair
+ *
+ * @return simulatedFee
+ */
+func pairFee(pair Pair) simulatedFee {
+	fee := simulatedFee{
+		maker: toFloat(pair.TradeFeePercentMaker) / 100,
+		taker: toFloat(pair.TradeFeePercentTaker) / 100,
+	}
+
+	if len(pair.TradeFeePercentTaker) == 0 {
+		fee.taker = toFloat(pair.TradeFeePercent) / 100
+	}
+
+	if len(pair.TradeFeePercentMaker) == 0 {
+		fee.maker = fee.taker
+	}
+
+	return fee
+}
+
+func levelCrosses(tradeType string, limit, level float64) bool {
+	if tradeType == TradeTypeBuy {
+		return level <= limit
+	}
+
+	return level >= limit
+}
+
+func compareIds(a, b string) int {
+	ai, errA := strconv.ParseInt(a, 10, 64)
+	bi, errB := strconv.ParseInt(b, 10, 64)
+
+	if errA != nil || errB != nil {
+		return strings.Compare(a, b)
+	}
+
+	switch {
+	case ai < bi:
+		return -1
+	case ai > bi:
+		return 1
+	}
+
+	return 0
+}
+
+func simulatedNumber(v float64) json.Number {
+	return json.Number(formatFloat(v))
+}
@@ -0,0 +1,163 @@
+package indodax
+
+import (
+	"encoding/json"
+	"math"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeMarket serves fixed tickers, a fixed order book and no trades
+type fakeMarket struct {
+	PublicAPI
+	mu      sync.Mutex
+	pairs   []Pair
+	depth   GetDepthResponseBody
+	tickers map[string]map[string]interface{}
+}
+
+func (m *fakeMarket) GetTickerAll() (*GetTickerAllResponseBody, error) {
+	return &GetTickerAllResponseBody{Tickers: m.tickers}, nil
+}
+
+func (m *fakeMarket) GetTicker(pairId string) (*GetTickerResponseBody, error) {
+	return &GetTickerResponseBody{Ticker: m.tickers[pairId]}, nil
+}
+
+func (m *fakeMarket) GetPairs() (*[]Pair, error) {
+	return &m.pairs, nil
+}
+
+func (m *fakeMarket) GetDepth(_ string) (*GetDepthResponseBody, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	depth := m.depth
+
+	return &depth, nil
+}
+
+func (m *fakeMarket) GetTrades(_ string) (*[]Trade, error) {
+	return &[]Trade{}, nil
+}
+
+func TestPairFee(t *testing.T) {
+	tests := []struct {
+		pair  Pair
+		maker float64
+		taker float64
+	}{
+		{Pair{TradeFeePercentMaker: "0", TradeFeePercentTaker: "0.3"}, 0, 0.003},
+		{Pair{TradeFeePercentTaker: "0.3"}, 0.003, 0.003},
+		{Pair{TradeFeePercent: "0.2"}, 0.002, 0.002},
+		{Pair{TradeFeePercent: "0.2", TradeFeePercentTaker: "0"}, 0, 0},
+	}
+
+	for _, test := range tests {
+		fee := pairFee(test.pair)
+
+		if math.Abs(fee.maker-test.maker) > 1e-12 || math.Abs(fee.taker-test.taker) > 1e-12 {
+			t.Errorf("%+v: expected maker %v taker %v, got %+v", test.pair, test.maker, test.taker, fee)
+		}
+	}
+}
+
+func TestPaperTraderMatchConsumesDepthOnce(t *testing.T) {
+	market := &fakeMarket{
+		pairs: []Pair{{Id: "btcidr", TickerId: "btc_idr", TradeFeePercentMaker: "0", TradeFeePercentTaker: "0"}},
+		depth: GetDepthResponseBody{Sell: [][2]json.Number{{"110", "1"}}},
+	}
+
+	paper, err := NewPaperTrader(market, map[string]float64{"idr": 10000})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err = paper.Trade(TradeTypeBuy, "btc_idr", OrderTypeLimit, 100, 1, nil, nil, true); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// the ask drops below the resting bids, only its 1 btc can be matched
+	market.mu.Lock()
+	market.depth = GetDepthResponseBody{Sell: [][2]json.Number{{"95", "1"}}}
+	market.mu.Unlock()
+
+	for i := 0; i < 3; i++ {
+		if err = paper.Match(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if btc := paper.Balances()["btc"]; math.Abs(btc-1) > 1e-9 {
+		t.Fatalf("expected 1 btc to be matched, got %v", btc)
+	}
+}
+
+func TestPaperTraderRestingOrderDoesNotRematchTakenDepth(t *testing.T) {
+	market := &fakeMarket{
+		pairs: []Pair{{Id: "btcidr", TickerId: "btc_idr", TradeFeePercentMaker: "0", TradeFeePercentTaker: "0"}},
+		depth: GetDepthResponseBody{Sell: [][2]json.Number{{"100", "1"}}},
+	}
+
+	paper, err := NewPaperTrader(market, map[string]float64{"idr": 10000})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = paper.Trade(TradeTypeBuy, "btc_idr", OrderTypeLimit, 101, 2, nil, nil, true); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = paper.Match(); err != nil {
+		t.Fatal(err)
+	}
+
+	if btc := paper.Balances()["btc"]; math.Abs(btc-1) > 1e-9 {
+		t.Fatalf("expected the taken ask not to be matched again, got %v btc", btc)
+	}
+}
+
+func TestSimulatedOrderHistoryEndsAfterLastPage(t *testing.T) {
+	market := &fakeMarket{
+		pairs: []Pair{{Id: "btcidr", TickerId: "btc_idr", TradeFeePercentMaker: "0", TradeFeePercentTaker: "0"}},
+	}
+
+	paper, err := NewPaperTrader(market, map[string]float64{"idr": 1000000})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < orderHistoryPageSize; i++ {
+		if _, err = paper.Trade(TradeTypeBuy, "btc_idr", OrderTypeLimit, 100, 1, nil, nil, true); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	offset, count := orderHistoryPageSize, orderHistoryPageSize
+
+	if resp, err := paper.GetOrderHistory("btc_idr", &count, &offset); err != nil || len(resp.Orders) != 0 {
+		t.Fatalf("expected no orders past the end, got %v %v", resp, err)
+	}
+
+	done := make(chan struct{})
+
+	var orders []map[string]interface{}
+
+	go func() {
+		defer close(done)
+		orders, err = getOrderHistory(paper, "btc_idr", time.Time{})
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("order history paging did not terminate")
+	}
+
+	if err != nil || len(orders) != orderHistoryPageSize {
+		t.Fatalf("expected %d orders, got %d %v", orderHistoryPageSize, len(orders), err)
+	}
+}
@@ -0,0 +1,235 @@
+package indodax
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+func (c *Client) GenerateStatement(pairs []string, from, to time.Time, opening map[string]float64) (*Statement, error) {
+	var trades []OwnTrade
+
+	orders := map[string][]map[string]interface{}{}
+
+	for _, pair := range pairs {
+		pairTrades, err := c.GetOwnTrades(pair, &from, &to)
+		if err != nil {
+			return nil, err
+		}
+
+		trades = append(trades, pairTrades...)
+
+		pairOrders, err := getOrderHistory(c, pair, from)
+		if err != nil {
+			return nil, err
+		}
+
+		orders[pair] = pairOrders
+	}
+
+	history, err := c.GetTransactionHistoryRange(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewStatement(trades, orders, history, from, to, opening), nil
+}
+
+/*
+ * Merge trades, orders, deposits and withdrawals into a chronologically sorted
+ * ledger with running balances per asset starting from the opening balances.
+ * Trade, deposit and withdrawal fees are booked as separate fee entries, deposits and
+ * withdrawals are booked with the amount sent so that with their fee they add up to the
+ * balance change
+ *
+ * @param []OwnTrade trades
+ * @param map[string][]map[string]interface{} orders
+ * @param *GetTransactionHistoryResponseBody history
+ * @param time.Time from
+ * @param time.Time to
+ * @param map[string]float64 opening
+ *
+ * @return *Statement
+ */
+func NewStatement(trades []OwnTrade, orders map[string][]map[string]interface{}, history *GetTransactionHistoryResponseBody, from, to time.Time, opening map[string]float64) *Statement {
+	statement := Statement{
+		From:    from,
+		To:      to,
+		Opening: map[string]float64{},
+		Closing: map[string]float64{},
+	}
+
+	for currency, amount := range opening {
+		statement.Opening[strings.ToLower(currency)] = amount
+	}
+
+	var entries []LedgerEntry
+
+	for pair, records := range orders {
+		_, quote, err := splitPair(pair)
+		if err != nil {
+			continue
+		}
+
+		for _, record := range records {
+			submittedAt := toTime(record["submit_time"])
+
+			if !inPeriod(submittedAt, from, to) {
+				continue
+			}
+
+			entries = append(entries, LedgerEntry{
+				Time:        submittedAt,
+				Type:        LedgerEntryOrder,
+				Reference:   toString(record["order_id"]),
+				Pair:        pair,
+				Currency:    quote,
+				Description: fmt.Sprintf("%s %s @ %s", toString(record["type"]), toString(record["status"]), toString(record["price"])),
+			})
+		}
+	}
+
+	for _, trade := range trades {
+		if !inPeriod(trade.Time, from, to) {
+			continue
+		}
+
+		coin, quote, err := splitPair(trade.Pair)
+		if err != nil {
+			continue
+		}
+
+		value := trade.Price * trade.Amount
+		description := fmt.Sprintf("%s %s @ %s", trade.Type, formatFloat(trade.Amount), formatFloat(trade.Price))
+
+		coinAmount, quoteAmount := trade.Amount, -value
+		if trade.Type == TradeTypeSell {
+			coinAmount, quoteAmount = -trade.Amount, value
+		}
+
+		entries = append(entries,
+			LedgerEntry{Time: trade.Time, Type: LedgerEntryTrade, Reference: trade.TradeId, Pair: trade.Pair, Currency: coin, Amount: coinAmount, Description: description},
+			LedgerEntry{Time: trade.Time, Type: LedgerEntryTrade, Reference: trade.TradeId, Pair: trade.Pair, Currency: quote, Amount: quoteAmount, Description: description},
+		)
+
+		if trade.Fee != 0 {
+			entries = append(entries, LedgerEntry{Time: trade.Time, Type: LedgerEntryFee, Reference: trade.TradeId, Pair: trade.Pair, Currency: quote, Amount: -trade.Fee, Description: "trade fee"})
+		}
+	}
+
+	if history != nil {
+		for currency, records := range history.Deposit {
+			currency = strings.ToLower(currency)
+
+			for _, record := range records {
+				deposit := parseDeposit(currency, record)
+
+				if deposit.Status != DepositStatusSuccess || !inPeriod(depositTime(deposit), from, to) {
+					continue
+				}
+
+				amount, fee := depositTransfer(deposit)
+
+				entries = append(entries, LedgerEntry{
+					Time:        depositTime(deposit),
+					Type:        LedgerEntryDeposit,
+					Reference:   depositKey(deposit),
+					Currency:    currency,
+					Amount:      amount,
+					Description: deposit.TxId,
+				})
+
+				if fee != 0 {
+					entries = append(entries, LedgerEntry{Time: depositTime(deposit), Type: LedgerEntryFee, Reference: depositKey(deposit), Currency: currency, Amount: -fee, Description: "deposit fee"})
+				}
+			}
+		}
+
+		for currency, records := range history.Withdraw {
+			currency = strings.ToLower(currency)
+
+			for _, record := range records {
+				submittedAt := toTime(record["submit_time"])
+
+				if normalizeWithdrawalStatus(toString(record["status"])) == WithdrawalStatusCancelled || !inPeriod(submittedAt, from, to) {
+					continue
+				}
+
+				amount, fee := withdrawalTransfer(currency, record)
+
+				entries = append(entries, LedgerEntry{
+					Time:        submittedAt,
+					Type:        LedgerEntryWithdrawal,
+					Reference:   toString(record["withdraw_id"]),
+					Currency:    currency,
+					Amount:      -amount,
+					Description: toString(record["tx"]),
+				})
+
+				if fee != 0 {
+					entries = append(entries, LedgerEntry{Time: submittedAt, Type: LedgerEntryFee, Reference: toString(record["withdraw_id"]), Currency: currency, Amount: -fee, Description: "withdrawal fee"})
+				}
+			}
+		}
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Time.Before(entries[j].Time)
+	})
+
+	for currency, amount := range statement.Opening {
+		statement.Closing[currency] = amount
+	}
+
+	for i := range entries {
+		statement.Closing[entries[i].Currency] += entries[i].Amount
+		entries[i].Balance = statement.Closing[entries[i].Currency]
+	}
+
+	statement.Entries = entries
+
+	return &statement
+}
+
+func (s *Statement) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+
+	if err := cw.Write([]string{"time", "type", "reference", "pair", "currency", "amount", "balance", "description"}); err != nil {
+		return err
+	}
+
+	for _, entry := range s.Entries {
+		if err := cw.Write([]string{
+			entry.Time.Format(time.RFC3339),
+			entry.Type,
+			entry.Reference,
+			entry.Pair,
+			entry.Currency,
+			formatFloat(entry.Amount),
+			formatFloat(entry.Balance),
+			entry.Description,
+		}); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+
+	return cw.Error()
+}
+
+func (s *Statement) WriteJSONL(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+
+	for _, entry := range s.Entries {
+		if err := encoder.Encode(entry); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
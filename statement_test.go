@@ -0,0 +1,107 @@
+package indodax
+
+import (
+	"math"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// fakeOrderHistory serves order history newest first, paged by offset
+type fakeOrderHistory struct {
+	unimplementedPrivate
+	orders []map[string]interface{}
+	calls  int
+}
+
+func (f *fakeOrderHistory) GetOrderHistory(_ string, count, from *int) (*GetOrderHistoryResponseBody, error) {
+	f.calls++
+
+	start := *from
+	if start > len(f.orders) {
+		start = len(f.orders)
+	}
+
+	end := start + *count
+	if end > len(f.orders) {
+		end = len(f.orders)
+	}
+
+	return &GetOrderHistoryResponseBody{Orders: f.orders[start:end]}, nil
+}
+
+func TestGetOrderHistoryPagesUntilRangeStart(t *testing.T) {
+	now := time.Now()
+	api := &fakeOrderHistory{}
+
+	for i := 0; i < 5000; i++ {
+		api.orders = append(api.orders, map[string]interface{}{
+			"order_id":    strconv.Itoa(i),
+			"submit_time": now.Add(-time.Duration(i) * time.Hour).Unix(),
+		})
+	}
+
+	orders, err := getOrderHistory(api, "btc_idr", now.Add(-1500*time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(orders) != 1998 || api.calls != 2 {
+		t.Fatalf("expected 2 pages covering the range start, got %d orders in %d calls", len(orders), api.calls)
+	}
+
+	orders, err = getOrderHistory(api, "btc_idr", time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(orders) != 5000 {
+		t.Fatalf("expected the whole history, got %d orders", len(orders))
+	}
+}
+
+func TestNewStatementBooksFees(t *testing.T) {
+	at := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	// the deposit amount is credited net of the fee, the withdrawal debits the currency
+	// amount, which is the amount sent plus the fee
+	history := &GetTransactionHistoryResponseBody{
+		Deposit: map[string][]map[string]interface{}{
+			"btc": {{"deposit_id": "d1", "status": "success", "amount": "1", "fee": "0.001", "submit_time": at.Unix()}},
+		},
+		Withdraw: map[string][]map[string]interface{}{
+			"btc": {
+				{"withdraw_id": "w1", "status": "success", "btc": "0.5", "amount": "0.4995", "fee": "0.0005", "submit_time": at.Add(time.Hour).Unix()},
+				{"withdraw_id": "w2", "status": "success", "btc": "0.2", "fee": "0.0005", "submit_time": at.Add(2 * time.Hour).Unix()},
+			},
+		},
+	}
+
+	statement := NewStatement(nil, nil, history, time.Time{}, time.Time{}, nil)
+
+	want := []struct {
+		entryType string
+		amount    float64
+	}{
+		{LedgerEntryDeposit, 1.001},
+		{LedgerEntryFee, -0.001},
+		{LedgerEntryWithdrawal, -0.4995},
+		{LedgerEntryFee, -0.0005},
+		{LedgerEntryWithdrawal, -0.1995},
+		{LedgerEntryFee, -0.0005},
+	}
+
+	if len(statement.Entries) != len(want) {
+		t.Fatalf("expected %d entries, got %+v", len(want), statement.Entries)
+	}
+
+	for i, entry := range statement.Entries {
+		if entry.Type != want[i].entryType || math.Abs(entry.Amount-want[i].amount) > 1e-9 {
+			t.Errorf("entry %d: expected %s %v, got %s %v", i, want[i].entryType, want[i].amount, entry.Type, entry.Amount)
+		}
+	}
+
+	if closing := statement.Closing["btc"]; math.Abs(closing-0.3) > 1e-9 {
+		t.Fatalf("expected a closing balance of 0.3 btc, got %v", closing)
+	}
+}
@@ -0,0 +1,124 @@
+package indodax
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+var ErrStateNotFound = errors.New("state not found")
+
+type Store interface {
+	Load(key string, v interface{}) error
+	Save(key string, v interface{}) error
+}
+
+type FileStore struct {
+	Path string
+	mu   sync.Mutex
+}
+
+type MemoryStore struct {
+	mu    sync.Mutex
+	items map[string][]byte
+}
+
+func NewFileStore(path string) *FileStore {
+	return &FileStore{Path: path}
+}
+
+/*
+ * Load state from json file
+ *
+ * @param string key
+ * @param interface{} v
+ *
+ * @return error
+ */
+func (s *FileStore) Load(key string, v interface{}) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, err := os.ReadFile(s.filename(key))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return ErrStateNotFound
+		}
+
+		return err
+	}
+
+	return json.Unmarshal(b, v)
+}
+
+/*
+ * Save state to json file, the file is written to a temporary file first
+ * and renamed so a crash never leaves a half written state behind
+ *
+ * @param string key
+ * @param interface{} v
+ *
+ * @return error
+ */
+func (s *FileStore) Save(key string, v interface{}) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err = os.MkdirAll(s.Path, 0700); err != nil {
+		return err
+	}
+
+	filename := s.filename(key)
+	tmp := fmt.Sprintf("%s.tmp", filename)
+
+	if err = os.WriteFile(tmp, b, 0600); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, filename)
+}
+
+func (s *FileStore) filename(key string) string {
+	key = strings.NewReplacer("/", "_", "\\", "_", "..", "_").Replace(key)
+
+	return filepath.Join(s.Path, fmt.Sprintf("%s.json", key))
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{items: map[string][]byte{}}
+}
+
+func (s *MemoryStore) Load(key string, v interface{}) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, exist := s.items[key]
+	if !exist {
+		return ErrStateNotFound
+	}
+
+	return json.Unmarshal(b, v)
+}
+
+func (s *MemoryStore) Save(key string, v interface{}) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.items[key] = b
+
+	return nil
+}
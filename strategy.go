@@ -0,0 +1,542 @@
+package indodax
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+type Strategy interface {
+	OnStart(rt *StrategyRuntime) error
+	OnTicker(rt *StrategyRuntime, ticker StrategyTicker) error
+	OnTrade(rt *StrategyRuntime, pair string, trade Trade) error
+	OnOrderUpdate(rt *StrategyRuntime, update OrderUpdate) error
+	OnStop(rt *StrategyRuntime) error
+}
+
+type StrategyBase struct{}
+
+func (StrategyBase) OnStart(rt *StrategyRuntime) error                           { return nil }
+func (StrategyBase) OnTicker(rt *StrategyRuntime, ticker StrategyTicker) error   { return nil }
+func (StrategyBase) OnTrade(rt *StrategyRuntime, pair string, trade Trade) error { return nil }
+func (StrategyBase) OnOrderUpdate(rt *StrategyRuntime, update OrderUpdate) error { return nil }
+func (StrategyBase) OnStop(rt *StrategyRuntime) error                            { return nil }
+
+/*
+ * StrategyRuntime polls market data, places orders and reconciles order state
+ * for a strategy, the same strategy runs unchanged in live, paper and backtest mode
+ */
+type StrategyRuntime struct {
+	mode      string
+	config    StrategyConfig
+	market    PublicAPI
+	trading   TradingAPI
+	wallet    WalletAPI
+	paper     *PaperTrader
+	backtest  *Backtest
+	result    *BacktestResult
+	mu        sync.Mutex
+	tickers   map[string]StrategyTicker
+	lastTrade map[string]string
+	orders    map[string]*OrderUpdate
+}
+
+func NewLiveRuntime(client *Client, config StrategyConfig) (*StrategyRuntime, error) {
+	if client == nil {
+		return nil, errors.New("client is required")
+	}
+
+	return newStrategyRuntime(StrategyModeLive, client, client, client, config)
+}
+
+func NewPaperRuntime(paper *PaperTrader, config StrategyConfig) (*StrategyRuntime, error) {
+	if paper == nil {
+		return nil, errors.New("paper trader is required")
+	}
+
+	rt, err := newStrategyRuntime(StrategyModePaper, paper.market, paper, paper, config)
+	if err != nil {
+		return nil, err
+	}
+
+	rt.paper = paper
+
+	return rt, nil
+}
+
+func NewBacktestRuntime(backtest *Backtest, config StrategyConfig) (*StrategyRuntime, error) {
+	if backtest == nil {
+		return nil, errors.New("backtest is required")
+	}
+
+	if len(config.Pairs) == 0 {
+		config.Pairs = backtest.config.Pairs
+	}
+
+	rt, err := newStrategyRuntime(StrategyModeBacktest, nil, backtest, backtest, config)
+	if err != nil {
+		return nil, err
+	}
+
+	rt.backtest = backtest
+
+	return rt, nil
+}
+
+func newStrategyRuntime(mode string, market PublicAPI, trading TradingAPI, wallet WalletAPI, config StrategyConfig) (*StrategyRuntime, error) {
+	if len(config.Pairs) == 0 {
+		return nil, errors.New("at least one pair is required")
+	}
+
+	if config.PollInterval <= 0 {
+		config.PollInterval = DefaultStrategyPollInterval
+	}
+
+	pairs := make([]string, 0, len(config.Pairs))
+
+	for _, pair := range config.Pairs {
+		pairs = append(pairs, strings.ToLower(pair))
+	}
+
+	config.Pairs = pairs
+
+	return &StrategyRuntime{
+		mode:      mode,
+		config:    config,
+		market:    market,
+		trading:   trading,
+		wallet:    wallet,
+		tickers:   map[string]StrategyTicker{},
+		lastTrade: map[string]string{},
+		orders:    map[string]*OrderUpdate{},
+	}, nil
+}
+
+func (rt *StrategyRuntime) Mode() string {
+	return rt.mode
+}
+
+func (rt *StrategyRuntime) Pairs() []string {
+	return rt.config.Pairs
+}
+
+func (rt *StrategyRuntime) Now() time.Time {
+	if rt.backtest != nil {
+		return rt.backtest.Now()
+	}
+
+	return time.Now()
+}
+
+func (rt *StrategyRuntime) Trading() TradingAPI {
+	return rt.trading
+}
+
+func (rt *StrategyRuntime) Wallet() WalletAPI {
+	return rt.wallet
+}
+
+func (rt *StrategyRuntime) Ticker(pair string) (StrategyTicker, bool) {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+
+	ticker, exist := rt.tickers[strings.ToLower(pair)]
+
+	return ticker, exist
+}
+
+/*
+ * Get the order book of a pair, in backtest mode a single level at the
+ * last simulated price is returned on both sides
+ *
+ * @param string pair
+ *
+ * @return *GetDepthResponseBody
+ * @return error
+ */
+func (rt *StrategyRuntime) Depth(pair string) (*GetDepthResponseBody, error) {
+	if rt.backtest == nil {
+		return rt.market.GetDepth(publicPairId(pair))
+	}
+
+	last := rt.backtest.LastPrice(pair)
+	if last <= 0 {
+		return nil, fmt.Errorf("no price available for %s", pair)
+	}
+
+	level := [2]json.Number{simulatedNumber(last), simulatedNumber(0)}
+
+	return &GetDepthResponseBody{Buy: [][2]json.Number{level}, Sell: [][2]json.Number{level}}, nil
+}
+
+func (rt *StrategyRuntime) Balances() (map[string]float64, error) {
+	info, err := rt.wallet.GetInfo()
+	if err != nil {
+		return nil, err
+	}
+
+	balances := map[string]float64{}
+
+	for currency, amount := range info.Balance {
+		balances[strings.ToLower(currency)] = toFloat(amount)
+	}
+
+	return balances, nil
+}
+
+/*
+ * Place an order and track it, changes of the order are reported through OnOrderUpdate
+ *
+ * @return *ResponseBody
+ * @return error
+ */
+func (rt *StrategyRuntime) Trade(tradeType, pair, orderType string, price, amount float64, clientOrderId *string) (*ResponseBody, error) {
+	pair = strings.ToLower(pair)
+
+	resp, err := rt.trading.Trade(tradeType, pair, orderType, price, amount, nil, clientOrderId, false)
+	if err != nil {
+		return nil, err
+	}
+
+	ret, _ := resp.Return.(map[string]interface{})
+
+	orderId := toString(ret["order_id"])
+	if len(orderId) == 0 {
+		return resp, nil
+	}
+
+	rt.mu.Lock()
+	rt.orders[orderId] = &OrderUpdate{
+		OrderId:       orderId,
+		ClientOrderId: toString(ret["client_order_id"]),
+		Pair:          pair,
+		Type:          tradeType,
+		Status:        OrderStatusOpen,
+		Price:         price,
+		Amount:        amount,
+		Remain:        amount,
+		Time:          rt.Now(),
+	}
+	rt.mu.Unlock()
+
+	return resp, nil
+}
+
+func (rt *StrategyRuntime) Cancel(pair, orderId, tradeType string) (*map[string]interface{}, error) {
+	return rt.trading.CancelOrder(strings.ToLower(pair), orderId, tradeType, nil)
+}
+
+func (rt *StrategyRuntime) OpenOrders() []OrderUpdate {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+
+	result := make([]OrderUpdate, 0, len(rt.orders))
+
+	for _, order := range rt.orders {
+		result = append(result, *order)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return compareIds(result[i].OrderId, result[j].OrderId) < 0
+	})
+
+	return result
+}
+
+/*
+ * Result of the last backtest run, nil in live and paper mode
+ *
+ * @return *BacktestResult
+ */
+func (rt *StrategyRuntime) Result() *BacktestResult {
+	return rt.result
+}
+
+/*
+ * Run the strategy until the context is done, or until the market data is
+ * exhausted in backtest mode. Errors returned by strategy hooks stop the run,
+ * api errors while polling are logged and retried on the next poll. OnStop is
+ * called on every exit once OnStart succeeded
+ *
+ * @param context.Context ctx
+ * @param Strategy strategy
+ *
+ * @return error
+ */
+func (rt *StrategyRuntime) Run(ctx context.Context, strategy Strategy) (err error) {
+	if rt.backtest != nil {
+		result, err := rt.backtest.Run(&backtestStrategyAdapter{ctx: ctx, rt: rt, strategy: strategy})
+		if err != nil {
+			return err
+		}
+
+		rt.result = result
+
+		return nil
+	}
+
+	if err := strategy.OnStart(rt); err != nil {
+		return err
+	}
+
+	defer func() {
+		if stopErr := strategy.OnStop(rt); stopErr != nil {
+			err = errors.Join(err, stopErr)
+		}
+	}()
+
+	ticker := time.NewTicker(rt.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := rt.Step(strategy); err != nil {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+/*
+ * Poll market data and order state once and dispatch them to the strategy
+ *
+ * @param Strategy strategy
+ *
+ * @return error
+ */
+func (rt *StrategyRuntime) Step(strategy Strategy) error {
+	for _, pair := range rt.config.Pairs {
+		resp, err := rt.market.GetTicker(publicPairId(pair))
+		if err != nil {
+			logApiError(rt.market, "failed to get ticker", err)
+		} else {
+			ticker := parseStrategyTicker(pair, resp.Ticker)
+
+			rt.mu.Lock()
+			rt.tickers[pair] = ticker
+			rt.mu.Unlock()
+
+			if err := strategy.OnTicker(rt, ticker); err != nil {
+				return err
+			}
+		}
+
+		trades, err := rt.newTrades(pair)
+		if err != nil {
+			logApiError(rt.market, "failed to get trades", err)
+			continue
+		}
+
+		for _, trade := range trades {
+			if err := strategy.OnTrade(rt, pair, trade); err != nil {
+				return err
+			}
+		}
+	}
+
+	if rt.paper != nil {
+		if err := rt.paper.Match(); err != nil {
+			logApiError(rt.market, "failed to match paper orders", err)
+		}
+	}
+
+	return rt.reconcile(strategy)
+}
+
+/*
+ * Get trades printed since the previous poll in chronological order,
+ * the first poll only records the latest trade
+ *
+ * @param string pair
+ *
+ * @return []Trade
+ * @return error
+ */
+func (rt *StrategyRuntime) newTrades(pair string) ([]Trade, error) {
+	trades, err := rt.market.GetTrades(publicPairId(pair))
+	if err != nil {
+		return nil, err
+	}
+
+	sorted := make([]Trade, len(*trades))
+	copy(sorted, *trades)
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return compareIds(sorted[i].Tid, sorted[j].Tid) < 0
+	})
+
+	if len(sorted) == 0 {
+		return nil, nil
+	}
+
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+
+	last, seen := rt.lastTrade[pair]
+	rt.lastTrade[pair] = sorted[len(sorted)-1].Tid
+
+	if !seen {
+		return nil, nil
+	}
+
+	var result []Trade
+
+	for _, trade := range sorted {
+		if compareIds(trade.Tid, last) > 0 {
+			result = append(result, trade)
+		}
+	}
+
+	return result, nil
+}
+
+/*
+ * Refresh tracked orders and report the ones whose status or remaining amount changed,
+ * orders in a final state are no longer tracked
+ *
+ * @param Strategy strategy
+ *
+ * @return error
+ */
+func (rt *StrategyRuntime) reconcile(strategy Strategy) error {
+	for _, tracked := range rt.OpenOrders() {
+		resp, err := rt.trading.GetOrder(tracked.Pair, tracked.OrderId)
+		if err != nil {
+			logApiError(rt.trading, "failed to get order", err)
+			continue
+		}
+
+		update := parseOrderUpdate(tracked.Pair, resp.Order)
+		update.Time = rt.Now()
+
+		if len(update.Type) == 0 {
+			update.Type = tracked.Type
+		}
+
+		if update.Status == tracked.Status && update.Remain == tracked.Remain {
+			continue
+		}
+
+		rt.mu.Lock()
+		if update.Status == OrderStatusOpen {
+			rt.orders[update.OrderId] = &update
+		} else {
+			delete(rt.orders, update.OrderId)
+		}
+		rt.mu.Unlock()
+
+		if err := strategy.OnOrderUpdate(rt, update); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+type backtestStrategyAdapter struct {
+	BacktestStrategyBase
+	ctx      context.Context
+	rt       *StrategyRuntime
+	strategy Strategy
+}
+
+func (a *backtestStrategyAdapter) OnStart(bt *Backtest) error {
+	return a.strategy.OnStart(a.rt)
+}
+
+func (a *backtestStrategyAdapter) OnBar(bt *Backtest, pair string, bar OHLC) error {
+	if err := a.ctx.Err(); err != nil {
+		return err
+	}
+
+	if err := a.rt.reconcile(a.strategy); err != nil {
+		return err
+	}
+
+	ticker := StrategyTicker{
+		Pair:   pair,
+		Time:   time.Unix(bar.Time, 0),
+		Last:   toFloat(bar.Close),
+		Buy:    toFloat(bar.Close),
+		Sell:   toFloat(bar.Close),
+		High:   toFloat(bar.High),
+		Low:    toFloat(bar.Low),
+		Volume: toFloat(bar.Volume),
+	}
+
+	a.rt.mu.Lock()
+	a.rt.tickers[pair] = ticker
+	a.rt.mu.Unlock()
+
+	return a.strategy.OnTicker(a.rt, ticker)
+}
+
+func (a *backtestStrategyAdapter) OnTrade(bt *Backtest, pair string, trade Trade) error {
+	if err := a.ctx.Err(); err != nil {
+		return err
+	}
+
+	if err := a.rt.reconcile(a.strategy); err != nil {
+		return err
+	}
+
+	return a.strategy.OnTrade(a.rt, pair, trade)
+}
+
+func (a *backtestStrategyAdapter) OnStop(bt *Backtest) error {
+	err := a.rt.reconcile(a.strategy)
+
+	return errors.Join(err, a.strategy.OnStop(a.rt))
+}
+
+func parseStrategyTicker(pair string, ticker map[string]interface{}) StrategyTicker {
+	coin, _, _ := splitPair(pair)
+
+	result := StrategyTicker{
+		Pair:   pair,
+		Time:   toTime(ticker["server_time"]),
+		Last:   toFloat(ticker["last"]),
+		Buy:    toFloat(ticker["buy"]),
+		Sell:   toFloat(ticker["sell"]),
+		High:   toFloat(ticker["high"]),
+		Low:    toFloat(ticker["low"]),
+		Volume: toFloat(ticker[fmt.Sprintf("vol_%s", coin)]),
+	}
+
+	if result.Time.IsZero() {
+		result.Time = time.Now()
+	}
+
+	return result
+}
+
+func parseOrderUpdate(pair string, order map[string]interface{}) OrderUpdate {
+	coin, _, _ := splitPair(pair)
+
+	update := OrderUpdate{
+		OrderId:       toString(order["order_id"]),
+		ClientOrderId: toString(order["client_order_id"]),
+		Pair:          pair,
+		Type:          toString(order["type"]),
+		Status:        strings.ToLower(toString(order["status"])),
+		Price:         toFloat(order["price"]),
+		Amount:        toFloat(order[fmt.Sprintf("order_%s", coin)]),
+		Remain:        toFloat(order[fmt.Sprintf("remain_%s", coin)]),
+		Order:         order,
+	}
+
+	if update.Amount > 0 {
+		update.Filled = update.Amount - update.Remain
+	}
+
+	return update
+}
@@ -0,0 +1,157 @@
+package indodax
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// recordingStrategy records the hooks it receives and fails or cancels on the given ticker
+type recordingStrategy struct {
+	StrategyBase
+	calls    []string
+	tickers  int
+	failOn   int
+	cancelOn int
+	cancel   context.CancelFunc
+}
+
+var errStrategyFailed = errors.New("strategy failed")
+
+func (s *recordingStrategy) OnStart(rt *StrategyRuntime) error {
+	s.calls = append(s.calls, "start")
+
+	return nil
+}
+
+func (s *recordingStrategy) OnTicker(rt *StrategyRuntime, ticker StrategyTicker) error {
+	s.tickers++
+	s.calls = append(s.calls, "ticker")
+
+	if s.tickers == s.cancelOn {
+		s.cancel()
+	}
+
+	if s.tickers == s.failOn {
+		return errStrategyFailed
+	}
+
+	return nil
+}
+
+func (s *recordingStrategy) OnStop(rt *StrategyRuntime) error {
+	s.calls = append(s.calls, "stop")
+
+	return nil
+}
+
+func TestStrategyRuntimeBacktestStops(t *testing.T) {
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := map[string]struct {
+		strategy *recordingStrategy
+		err      error
+		calls    []string
+	}{
+		"exhausted": {
+			strategy: &recordingStrategy{},
+			calls:    []string{"start", "ticker", "ticker", "ticker", "stop"},
+		},
+		"strategy error": {
+			strategy: &recordingStrategy{failOn: 2},
+			err:      errStrategyFailed,
+			calls:    []string{"start", "ticker", "ticker", "stop"},
+		},
+		"cancelled": {
+			strategy: &recordingStrategy{cancelOn: 1},
+			err:      context.Canceled,
+			calls:    []string{"start", "ticker", "stop"},
+		},
+	}
+
+	for name, test := range tests {
+		bt, err := NewBacktest(BacktestConfig{Pairs: []string{"btc_idr"}, InitialBalances: map[string]float64{CurrencyIdr: 1000}})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		bt.AddBars("btc_idr", dailyBars(base, "100", "110", "120")...)
+
+		rt, err := NewBacktestRuntime(bt, StrategyConfig{})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		test.strategy.cancel = cancel
+
+		err = rt.Run(ctx, test.strategy)
+
+		cancel()
+
+		if !errors.Is(err, test.err) || (test.err == nil && err != nil) {
+			t.Errorf("%s: expected error %v, got %v", name, test.err, err)
+		}
+
+		if !reflect.DeepEqual(test.strategy.calls, test.calls) {
+			t.Errorf("%s: expected calls %v, got %v", name, test.calls, test.strategy.calls)
+		}
+
+		if (rt.Result() != nil) != (test.err == nil) {
+			t.Errorf("%s: expected a result only for a finished run, got %+v", name, rt.Result())
+		}
+	}
+}
+
+func TestStrategyRuntimePaperStops(t *testing.T) {
+	tests := map[string]struct {
+		strategy *recordingStrategy
+		err      error
+		calls    []string
+	}{
+		"strategy error": {
+			strategy: &recordingStrategy{failOn: 1},
+			err:      errStrategyFailed,
+			calls:    []string{"start", "ticker", "stop"},
+		},
+		"cancelled": {
+			strategy: &recordingStrategy{cancelOn: 1},
+			err:      context.Canceled,
+			calls:    []string{"start", "ticker", "stop"},
+		},
+	}
+
+	for name, test := range tests {
+		market := &fakeMarket{
+			pairs:   []Pair{{Id: "btcidr", TickerId: "btc_idr"}},
+			tickers: map[string]map[string]interface{}{"btcidr": {"last": "100", "buy": "99", "sell": "101"}},
+		}
+
+		paper, err := NewPaperTrader(market, map[string]float64{CurrencyIdr: 1000})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		rt, err := NewPaperRuntime(paper, StrategyConfig{Pairs: []string{"btc_idr"}, PollInterval: time.Hour})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		test.strategy.cancel = cancel
+
+		err = rt.Run(ctx, test.strategy)
+
+		cancel()
+
+		if !errors.Is(err, test.err) {
+			t.Errorf("%s: expected error %v, got %v", name, test.err, err)
+		}
+
+		if !reflect.DeepEqual(test.strategy.calls, test.calls) {
+			t.Errorf("%s: expected calls %v, got %v", name, test.calls, test.strategy.calls)
+		}
+	}
+}
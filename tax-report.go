@@ -0,0 +1,331 @@
+package indodax
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func (c *Client) GenerateTaxReport(pairs []string, from, to time.Time, config TaxConfig) (*TaxReport, error) {
+	var trades []OwnTrade
+
+	for _, pair := range pairs {
+		pairTrades, err := c.GetOwnTrades(pair, &from, &to)
+		if err != nil {
+			return nil, err
+		}
+
+		trades = append(trades, pairTrades...)
+	}
+
+	history, err := c.GetTransactionHistoryRange(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewTaxReport(trades, history, from, to, config)
+}
+
+/*
+ * Build tax report from own trades and transaction history. Purchases are subject
+ * to PPN and sales to PPh, deposits and withdrawals are listed but not taxed. Unset
+ * rates use the default rates, and months and years are bucketed in Asia/Jakarta
+ * unless another location is configured.
+ *
+ * @param []OwnTrade trades
+ * @param *GetTransactionHistoryResponseBody history
+ * @param time.Time from
+ * @param time.Time to
+ * @param TaxConfig config
+ *
+ * @return *TaxReport
+ * @return error
+ */
+func NewTaxReport(trades []OwnTrade, history *GetTransactionHistoryResponseBody, from, to time.Time, config TaxConfig) (*TaxReport, error) {
+	if config.PPhRate == nil {
+		rate := DefaultPPhRate
+		config.PPhRate = &rate
+	}
+
+	if config.PPNRate == nil {
+		rate := DefaultPPNRate
+		config.PPNRate = &rate
+	}
+
+	if *config.PPhRate < 0 || *config.PPNRate < 0 {
+		return nil, fmt.Errorf("tax rate can not be negative")
+	}
+
+	if config.Location == nil {
+		config.Location = taxLocation()
+	}
+
+	report := TaxReport{From: from, To: to, Config: config}
+
+	for _, trade := range trades {
+		if !inPeriod(trade.Time, from, to) {
+			continue
+		}
+
+		coin, quote, err := splitPair(trade.Pair)
+		if err != nil {
+			return nil, err
+		}
+
+		tx := TaxTransaction{
+			Time:      trade.Time,
+			Reference: trade.TradeId,
+			Pair:      trade.Pair,
+			Currency:  quote,
+			Amount:    trade.Amount,
+			Value:     trade.Price * trade.Amount,
+			Fee:       trade.Fee,
+		}
+
+		switch trade.Type {
+		case TradeTypeBuy:
+			tx.Category = TaxCategoryPurchase
+			tx.TaxableFor = coin
+			tx.PPN = tx.Value * *config.PPNRate
+		case TradeTypeSell:
+			tx.Category = TaxCategorySale
+			tx.TaxableFor = coin
+			tx.PPh = tx.Value * *config.PPhRate
+		default:
+			continue
+		}
+
+		if config.WithheldByExchange {
+			tx.Withheld = tx.PPh + tx.PPN
+		}
+
+		if config.FeeIncludesTax {
+			tx.Fee -= tx.Withheld
+
+			if tx.Fee < 0 {
+				tx.Fee = 0
+			}
+		}
+
+		tx.Payable = tx.PPh + tx.PPN - tx.Withheld
+
+		report.Transactions = append(report.Transactions, tx)
+	}
+
+	if history != nil {
+		for currency, records := range history.Deposit {
+			currency = strings.ToLower(currency)
+
+			for _, record := range records {
+				deposit := parseDeposit(currency, record)
+
+				if !inPeriod(depositTime(deposit), from, to) {
+					continue
+				}
+
+				amount, fee := depositTransfer(deposit)
+
+				report.Transactions = append(report.Transactions, TaxTransaction{
+					Time:      depositTime(deposit),
+					Category:  TaxCategoryDeposit,
+					Reference: depositKey(deposit),
+					Currency:  currency,
+					Amount:    amount,
+					Fee:       fee,
+				})
+			}
+		}
+
+		for currency, records := range history.Withdraw {
+			currency = strings.ToLower(currency)
+
+			for _, record := range records {
+				submittedAt := toTime(record["submit_time"])
+
+				if !inPeriod(submittedAt, from, to) {
+					continue
+				}
+
+				amount, fee := withdrawalTransfer(currency, record)
+
+				report.Transactions = append(report.Transactions, TaxTransaction{
+					Time:      submittedAt,
+					Category:  TaxCategoryWithdrawal,
+					Reference: toString(record["withdraw_id"]),
+					Currency:  currency,
+					Amount:    amount,
+					Fee:       fee,
+				})
+			}
+		}
+	}
+
+	sort.SliceStable(report.Transactions, func(i, j int) bool {
+		return report.Transactions[i].Time.Before(report.Transactions[j].Time)
+	})
+
+	report.Monthly = summarizeTax(report.Transactions, "2006-01", config.Location)
+	report.Yearly = summarizeTax(report.Transactions, "2006", config.Location)
+
+	return &report, nil
+}
+
+func (r *TaxReport) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(r)
+}
+
+func (r *TaxReport) WriteTransactionsCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+
+	if err := cw.Write([]string{"time", "category", "reference", "pair", "currency", "amount", "value", "fee", "pph", "ppn", "withheld", "payable"}); err != nil {
+		return err
+	}
+
+	for _, tx := range r.Transactions {
+		if err := cw.Write([]string{
+			tx.Time.Format(time.RFC3339),
+			tx.Category,
+			tx.Reference,
+			tx.Pair,
+			tx.Currency,
+			formatFloat(tx.Amount),
+			formatFloat(tx.Value),
+			formatFloat(tx.Fee),
+			formatFloat(tx.PPh),
+			formatFloat(tx.PPN),
+			formatFloat(tx.Withheld),
+			formatFloat(tx.Payable),
+		}); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+
+	return cw.Error()
+}
+
+func (r *TaxReport) WriteSummaryCSV(w io.Writer, period string) error {
+	var summaries []TaxSummary
+
+	switch period {
+	case PeriodMonthly:
+		summaries = r.Monthly
+	case PeriodYearly:
+		summaries = r.Yearly
+	default:
+		return fmt.Errorf("invalid period %s", period)
+	}
+
+	cw := csv.NewWriter(w)
+
+	if err := cw.Write([]string{"period", "currency", "transactions", "purchase_value", "sale_value", "fees", "pph", "ppn", "withheld", "payable"}); err != nil {
+		return err
+	}
+
+	for _, s := range summaries {
+		if err := cw.Write([]string{
+			s.Period,
+			s.Currency,
+			strconv.Itoa(s.Transactions),
+			formatFloat(s.PurchaseValue),
+			formatFloat(s.SaleValue),
+			formatFloat(s.Fees),
+			formatFloat(s.PPh),
+			formatFloat(s.PPN),
+			formatFloat(s.Withheld),
+			formatFloat(s.Payable),
+		}); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+
+	return cw.Error()
+}
+
+/*
+ * Summarize taxable transactions per period and quote currency
+ *
+ * @param []TaxTransaction transactions
+ * @param string layout
+ * @param *time.Location location
+ *
+ * @return []TaxSummary
+ */
+func summarizeTax(transactions []TaxTransaction, layout string, location *time.Location) []TaxSummary {
+	index := map[string]*TaxSummary{}
+
+	var keys []string
+
+	for _, tx := range transactions {
+		if tx.Category != TaxCategoryPurchase && tx.Category != TaxCategorySale {
+			continue
+		}
+
+		period := tx.Time.In(location).Format(layout)
+		key := fmt.Sprintf("%s|%s", period, tx.Currency)
+
+		s, exist := index[key]
+		if !exist {
+			s = &TaxSummary{Period: period, Currency: tx.Currency}
+			index[key] = s
+			keys = append(keys, key)
+		}
+
+		if tx.Category == TaxCategoryPurchase {
+			s.PurchaseValue += tx.Value
+		} else {
+			s.SaleValue += tx.Value
+		}
+
+		s.Transactions++
+		s.Fees += tx.Fee
+		s.PPh += tx.PPh
+		s.PPN += tx.PPN
+		s.Withheld += tx.Withheld
+		s.Payable += tx.Payable
+	}
+
+	sort.Strings(keys)
+
+	result := make([]TaxSummary, 0, len(keys))
+
+	for _, key := range keys {
+		result = append(result, *index[key])
+	}
+
+	return result
+}
+
+/*
+ * Location of the indonesian tax periods, a fixed UTC+7 zone is used when the
+ * timezone database is not available
+ *
+ * @return *time.Location
+ */
+func taxLocation() *time.Location {
+	location, err := time.LoadLocation(DefaultTaxZone)
+	if err != nil {
+		return time.FixedZone("WIB", 7*60*60)
+	}
+
+	return location
+}
+
+func inPeriod(t, from, to time.Time) bool {
+	return (from.IsZero() || !t.Before(from)) && (to.IsZero() || !t.After(to))
+}
+
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
@@ -0,0 +1,71 @@
+package indodax
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestNewTaxReportRates(t *testing.T) {
+	at := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	trades := []OwnTrade{
+		{TradeId: "1", Pair: "btc_idr", Type: TradeTypeBuy, Price: 1000000, Amount: 1, Time: at},
+		{TradeId: "2", Pair: "btc_idr", Type: TradeTypeSell, Price: 2000000, Amount: 1, Time: at.Add(time.Hour)},
+	}
+
+	report, err := NewTaxReport(trades, nil, time.Time{}, time.Time{}, TaxConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if math.Abs(report.Transactions[0].PPN-1100) > 1e-9 || math.Abs(report.Transactions[1].PPh-2000) > 1e-9 {
+		t.Fatalf("expected default rates, got %+v", report.Transactions)
+	}
+
+	exempt := 0.0
+
+	report, err = NewTaxReport(trades, nil, time.Time{}, time.Time{}, TaxConfig{PPNRate: &exempt, WithheldByExchange: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if report.Transactions[0].PPN != 0 || report.Transactions[0].Payable != 0 {
+		t.Fatalf("expected purchases to be exempt from PPN, got %+v", report.Transactions[0])
+	}
+
+	if math.Abs(report.Transactions[1].Withheld-2000) > 1e-9 || report.Transactions[1].Payable != 0 {
+		t.Fatalf("expected PPh to be withheld, got %+v", report.Transactions[1])
+	}
+
+	negative := -0.1
+
+	if _, err = NewTaxReport(trades, nil, time.Time{}, time.Time{}, TaxConfig{PPhRate: &negative}); err == nil {
+		t.Fatal("expected a negative rate to be rejected")
+	}
+}
+
+func TestNewTaxReportBucketsInJakarta(t *testing.T) {
+	// 2023-12-31 18:00 UTC is already 2024-01-01 01:00 in Jakarta
+	at := time.Date(2023, 12, 31, 18, 0, 0, 0, time.UTC)
+
+	trades := []OwnTrade{{TradeId: "1", Pair: "btc_idr", Type: TradeTypeSell, Price: 1000, Amount: 1, Time: at}}
+
+	report, err := NewTaxReport(trades, nil, time.Time{}, time.Time{}, TaxConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(report.Monthly) != 1 || report.Monthly[0].Period != "2024-01" || report.Yearly[0].Period != "2024" {
+		t.Fatalf("expected the sale to be bucketed in 2024-01, got %+v %+v", report.Monthly, report.Yearly)
+	}
+
+	report, err = NewTaxReport(trades, nil, time.Time{}, time.Time{}, TaxConfig{Location: time.UTC})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if report.Monthly[0].Period != "2023-12" {
+		t.Fatalf("expected the configured location to be used, got %+v", report.Monthly)
+	}
+}
@@ -0,0 +1,237 @@
+package indodax
+
+import (
+	"context"
+	"errors"
+	"github.com/vannleonheart/goutil"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+/*
+ * Tracer starts a span for every api call. It is a hook of this package, not an opentelemetry
+ * tracer: the package does not inject traceparent headers, propagation is left to the tracer
+ * adapter or to a transport such as otelhttp that reads the span from the request context
+ */
+type Tracer interface {
+	Start(ctx context.Context, name string, attributes map[string]interface{}) (context.Context, Span)
+}
+
+type Span interface {
+	SetAttributes(attributes map[string]interface{})
+	RecordError(err error)
+	End()
+}
+
+type noopSpan struct{}
+
+func (noopSpan) SetAttributes(map[string]interface{}) {}
+
+func (noopSpan) RecordError(error) {}
+
+func (noopSpan) End() {}
+
+func (c *Client) WithTracer(tracer Tracer) *Client {
+	derived := *c
+	derived.Tracer = tracer
+
+	return &derived
+}
+
+/*
+ * Derive a client whose calls use ctx for cancellation and trace propagation,
+ * the original client is left untouched. Prefer the *Context variants of the
+ * calls, such as GetInfoContext, which take the context of each call explicitly
+ *
+ * @param context.Context ctx
+ *
+ * @return *Client
+ */
+func (c *Client) WithContext(ctx context.Context) *Client {
+	derived := *c
+	derived.ctx = ctx
+
+	return &derived
+}
+
+func (c *Client) context() context.Context {
+	if c.ctx == nil {
+		return context.Background()
+	}
+
+	return c.ctx
+}
+
+/*
+ * Start a span of an api call, a no-op span is returned when no tracer is set
+ *
+ * @param context.Context ctx
+ * @param string name
+ * @param map[string]interface{} attributes
+ *
+ * @return context.Context
+ * @return Span
+ */
+func (c *Client) startSpan(ctx context.Context, name string, attributes map[string]interface{}) (context.Context, Span) {
+	if c.Tracer == nil {
+		return ctx, noopSpan{}
+	}
+
+	return c.Tracer.Start(ctx, name, attributes)
+}
+
+/*
+ * End a span with the http status and error code of the call
+ *
+ * @param Span span
+ * @param error err
+ * @param *[]byte raw
+ *
+ * @return void
+ */
+func endSpan(span Span, err error, raw *[]byte) {
+	attributes := map[string]interface{}{}
+
+	var httpErr goutil.HttpResponseError
+
+	if errors.As(err, &httpErr) {
+		attributes["http.status_code"] = httpErr.Code
+	} else if err == nil {
+		attributes["http.status_code"] = http.StatusOK
+	}
+
+	if code := metricsErrorCode(err, raw); len(code) > 0 {
+		attributes["indodax.error_code"] = code
+	}
+
+	span.SetAttributes(attributes)
+
+	if err != nil {
+		span.RecordError(err)
+	}
+
+	span.End()
+}
+
+/*
+ * Http client sending requests with ctx, so transports such as tracing middleware see
+ * the span of the call and requests are cancelled with ctx
+ *
+ * @param context.Context ctx
+ *
+ * @return *http.Client
+ */
+func (c *Client) httpClient(ctx context.Context) *http.Client {
+	if ctx == context.Background() {
+		return c.HttpClient
+	}
+
+	var client http.Client
+
+	if c.HttpClient != nil {
+		client = *c.HttpClient
+	}
+
+	base := client.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	client.Transport = &contextTransport{ctx: ctx, base: base}
+
+	return &client
+}
+
+type contextTransport struct {
+	ctx  context.Context
+	base http.RoundTripper
+}
+
+/*
+ * Send a request with a context derived from the request context, so the timeout of the
+ * http client still applies, carrying the values of ctx and cancelled when ctx is done.
+ * The derived context is released when the response body is closed
+ *
+ * @param *http.Request req
+ *
+ * @return *http.Response
+ * @return error
+ */
+func (t *contextTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx, cancel := context.WithCancelCause(valuesContext{Context: req.Context(), values: t.ctx})
+	stop := context.AfterFunc(t.ctx, func() {
+		cancel(context.Cause(t.ctx))
+	})
+
+	release := func() {
+		stop()
+		cancel(nil)
+	}
+
+	resp, err := t.base.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		release()
+		return nil, err
+	}
+
+	resp.Body = &contextBody{ReadCloser: resp.Body, release: release}
+
+	return resp, nil
+}
+
+/*
+ * valuesContext looks values up in values before the wrapped context, cancellation
+ * and deadline come from the wrapped context only
+ */
+type valuesContext struct {
+	context.Context
+	values context.Context
+}
+
+func (c valuesContext) Value(key interface{}) interface{} {
+	if v := c.values.Value(key); v != nil {
+		return v
+	}
+
+	return c.Context.Value(key)
+}
+
+type contextBody struct {
+	io.ReadCloser
+	once    sync.Once
+	release func()
+}
+
+func (b *contextBody) Close() error {
+	err := b.ReadCloser.Close()
+
+	b.once.Do(b.release)
+
+	return err
+}
+
+/*
+ * Pair of a public api uri, taken from the path or the symbol query parameter
+ *
+ * @param string uri
+ *
+ * @return string
+ */
+func publicPair(uri string) string {
+	path, query, _ := strings.Cut(uri, "?")
+
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	if len(segments) > 2 {
+		return segments[2]
+	}
+
+	values, err := url.ParseQuery(query)
+	if err != nil {
+		return ""
+	}
+
+	return strings.ToLower(values.Get("symbol"))
+}
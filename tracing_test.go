@@ -0,0 +1,139 @@
+package indodax
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type tracingContextKey struct{}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func newSlowServer(t *testing.T, delay time.Duration) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(delay):
+		}
+
+		_, _ = w.Write([]byte(`{}`))
+	}))
+
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestWithContextKeepsHttpClientTimeout(t *testing.T) {
+	server := newSlowServer(t, 5*time.Second)
+
+	var hasDeadline bool
+
+	transport := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		_, hasDeadline = req.Context().Deadline()
+
+		return http.DefaultTransport.RoundTrip(req)
+	})
+
+	client := New(Config{PublicApiBaseUrl: server.URL}).WithHttpClient(&http.Client{Transport: transport, Timeout: 50 * time.Millisecond})
+	ctx := context.WithValue(context.Background(), tracingContextKey{}, "span")
+
+	start := time.Now()
+
+	if _, err := client.WithContext(ctx).PublicApiCall("/api/server_time"); err == nil {
+		t.Fatal("expected the call to time out")
+	}
+
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Fatalf("expected the http client timeout to apply, took %s", elapsed)
+	}
+
+	if !hasDeadline {
+		t.Fatal("expected the transport to see the deadline of the http client timeout")
+	}
+}
+
+func TestWithContextCancelsRequests(t *testing.T) {
+	server := newSlowServer(t, 5*time.Second)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+
+	if _, err := New(Config{PublicApiBaseUrl: server.URL}).WithContext(ctx).PublicApiCall("/api/server_time"); err == nil {
+		t.Fatal("expected the call to be cancelled")
+	}
+
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Fatalf("expected the context to cancel the call, took %s", elapsed)
+	}
+}
+
+func TestWithContextPassesValuesToTransport(t *testing.T) {
+	server := newSlowServer(t, 0)
+
+	var value interface{}
+
+	transport := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		value = req.Context().Value(tracingContextKey{})
+
+		return http.DefaultTransport.RoundTrip(req)
+	})
+
+	client := New(Config{PublicApiBaseUrl: server.URL}).WithHttpClient(&http.Client{Transport: transport})
+	ctx := context.WithValue(context.Background(), tracingContextKey{}, "span")
+
+	if _, err := client.WithContext(ctx).PublicApiCall("/api/server_time"); err != nil {
+		t.Fatal(err)
+	}
+
+	if value != "span" {
+		t.Fatalf("expected the transport to see the context value, got %v", value)
+	}
+}
+
+func TestContextVariantsUseCallContext(t *testing.T) {
+	server := newTradeServer(t, false, "")
+
+	var values []interface{}
+
+	transport := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		values = append(values, req.Context().Value(tracingContextKey{}))
+
+		return http.DefaultTransport.RoundTrip(req)
+	})
+
+	client := server.client().WithHttpClient(&http.Client{Transport: transport})
+	ctx := context.WithValue(context.Background(), tracingContextKey{}, "span")
+
+	if _, err := client.TradeContext(ctx, TradeTypeBuy, "btc_idr", OrderTypeLimit, 100, 1, nil, nil, true); err != nil {
+		t.Fatal(err)
+	}
+
+	// the dropped trade, the client order id lookup and the second trade all carry the context
+	if len(values) != 3 {
+		t.Fatalf("expected 3 requests, got %d", len(values))
+	}
+
+	for i, value := range values {
+		if value != "span" {
+			t.Errorf("request %d: expected the transport to see the context value, got %v", i, value)
+		}
+	}
+
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := client.GetInfoContext(cancelled); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected a cancelled call, got %v", err)
+	}
+}
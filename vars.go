@@ -1,25 +1,46 @@
 package indodax
 
-import "encoding/json"
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"time"
+)
 
 type Client struct {
-	Config     Config
-	Credential *Credential
+	Config             Config
+	Credential         *Credential
+	Signer             Signer
+	CredentialProvider CredentialProvider
+	HttpClient         *http.Client
+	Logger             *slog.Logger
+	Metrics            *Metrics
+	Tracer             Tracer
+	Middleware         []Middleware
+	ctx                context.Context
 }
 
 type Config struct {
-	PublicApiBaseUrl  string     `json:"public_api_base_url"`
-	PrivateApiBaseUrl string     `json:"private_api_base_url"`
-	Log               *LogConfig `json:"log"`
+	PublicApiBaseUrl     string        `json:"public_api_base_url"`
+	PrivateApiBaseUrl    string        `json:"private_api_base_url"`
+	Log                  *LogConfig    `json:"log"`
+	ClientOrderIdPrefix  string        `json:"client_order_id_prefix"`
+	DisableClientOrderId bool          `json:"disable_client_order_id"`
+	TradeResolveDelay    time.Duration `json:"trade_resolve_delay"`
+	RateLimitRetries     int           `json:"rate_limit_retries"`
+	RateLimitBackoff     time.Duration `json:"rate_limit_backoff"`
 }
 
 type LogConfig struct {
-	Enable    bool   `json:"enable"`
-	Level     string `json:"level"`
-	Path      string `json:"path"`
-	Filename  string `json:"filename"`
-	Extension string `json:"extension"`
-	Rotation  string `json:"rotation"`
+	Enable           bool     `json:"enable"`
+	Level            string   `json:"level"`
+	Path             string   `json:"path"`
+	Filename         string   `json:"filename"`
+	Extension        string   `json:"extension"`
+	Rotation         string   `json:"rotation"`
+	RedactKeys       []string `json:"redact_keys"`
+	DisableRedaction bool     `json:"disable_redaction"`
 }
 
 type Credential struct {
@@ -173,3 +194,362 @@ type OHLC struct {
 	Close  json.Number `json:"Close"`
 	Volume string      `json:"Volume"`
 }
+
+type WithdrawalTrackerConfig struct {
+	PollInterval time.Duration `json:"poll_interval"`
+	StuckAfter   time.Duration `json:"stuck_after"`
+	Retention    time.Duration `json:"retention"`
+	ExpireAfter  time.Duration `json:"expire_after"`
+}
+
+type TrackedWithdrawal struct {
+	RequestId   string    `json:"request_id"`
+	WithdrawId  string    `json:"withdraw_id"`
+	TxId        string    `json:"tx_id"`
+	Currency    string    `json:"currency"`
+	Address     string    `json:"address"`
+	Amount      string    `json:"amount"`
+	Fee         string    `json:"fee"`
+	Status      string    `json:"status"`
+	SubmittedAt time.Time `json:"submitted_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	CompletedAt time.Time `json:"completed_at,omitempty"`
+	Stuck       bool      `json:"stuck"`
+}
+
+type WithdrawalEvent struct {
+	Type           string            `json:"type"`
+	PreviousStatus string            `json:"previous_status,omitempty"`
+	Withdrawal     TrackedWithdrawal `json:"withdrawal"`
+}
+
+type DepositWatcherConfig struct {
+	PollInterval time.Duration `json:"poll_interval"`
+	Overlap      time.Duration `json:"overlap"`
+	Currencies   []string      `json:"currencies"`
+	Addresses    []string      `json:"addresses"`
+}
+
+type Deposit struct {
+	DepositId   string    `json:"deposit_id"`
+	TxId        string    `json:"tx_id"`
+	Currency    string    `json:"currency"`
+	Address     string    `json:"address,omitempty"`
+	Amount      string    `json:"amount"`
+	Fee         string    `json:"fee,omitempty"`
+	Status      string    `json:"status"`
+	Confirmed   bool      `json:"confirmed"`
+	SubmittedAt time.Time `json:"submitted_at,omitempty"`
+	SuccessAt   time.Time `json:"success_at,omitempty"`
+}
+
+type DepositEvent struct {
+	Type           string  `json:"type"`
+	PreviousStatus string  `json:"previous_status,omitempty"`
+	Deposit        Deposit `json:"deposit"`
+}
+
+type DepositCursor struct {
+	Since time.Time              `json:"since"`
+	Seen  map[string]SeenDeposit `json:"seen"`
+}
+
+type SeenDeposit struct {
+	Status string    `json:"status"`
+	Time   time.Time `json:"time"`
+}
+
+type OwnTrade struct {
+	TradeId       string    `json:"trade_id"`
+	OrderId       string    `json:"order_id"`
+	ClientOrderId string    `json:"client_order_id,omitempty"`
+	Pair          string    `json:"pair"`
+	Type          string    `json:"type"`
+	Price         float64   `json:"price"`
+	Amount        float64   `json:"amount"`
+	Fee           float64   `json:"fee"`
+	Time          time.Time `json:"time"`
+}
+
+type BalanceMonitorConfig struct {
+	PollInterval time.Duration      `json:"poll_interval"`
+	Pairs        []string           `json:"pairs"`
+	Tolerance    float64            `json:"tolerance"`
+	Tolerances   map[string]float64 `json:"tolerances"`
+}
+
+type BalanceSnapshot struct {
+	Time time.Time          `json:"time"`
+	Free map[string]float64 `json:"free"`
+	Held map[string]float64 `json:"held"`
+}
+
+type BalanceAttribution struct {
+	Source    string  `json:"source"`
+	Reference string  `json:"reference"`
+	Amount    float64 `json:"amount"`
+}
+
+type BalanceDelta struct {
+	Currency     string               `json:"currency"`
+	Free         float64              `json:"free"`
+	Held         float64              `json:"held"`
+	Total        float64              `json:"total"`
+	Explained    float64              `json:"explained"`
+	Unexplained  float64              `json:"unexplained"`
+	Attributions []BalanceAttribution `json:"attributions,omitempty"`
+}
+
+type BalanceAlert struct {
+	From  time.Time    `json:"from"`
+	To    time.Time    `json:"to"`
+	Delta BalanceDelta `json:"delta"`
+}
+
+type PortfolioConfig struct {
+	PriceSource   string   `json:"price_source"`
+	IncludeUsdt   bool     `json:"include_usdt"`
+	Intermediates []string `json:"intermediates"`
+}
+
+type AssetValuation struct {
+	Currency  string   `json:"currency"`
+	Free      float64  `json:"free"`
+	Held      float64  `json:"held"`
+	Total     float64  `json:"total"`
+	Price     float64  `json:"price"`
+	Value     float64  `json:"value"`
+	ValueUsdt float64  `json:"value_usdt,omitempty"`
+	Weight    float64  `json:"weight"`
+	Route     []string `json:"route,omitempty"`
+}
+
+type PortfolioValuation struct {
+	Time         time.Time        `json:"time"`
+	PriceSource  string           `json:"price_source"`
+	Assets       []AssetValuation `json:"assets"`
+	Total        float64          `json:"total"`
+	TotalUsdt    float64          `json:"total_usdt,omitempty"`
+	UsdtIdrPrice float64          `json:"usdt_idr_price,omitempty"`
+	Unpriced     []string         `json:"unpriced,omitempty"`
+}
+
+type RealizedPnL struct {
+	Pair            string    `json:"pair"`
+	TradeId         string    `json:"trade_id"`
+	Time            time.Time `json:"time"`
+	Amount          float64   `json:"amount"`
+	UnmatchedAmount float64   `json:"unmatched_amount,omitempty"`
+	Proceeds        float64   `json:"proceeds"`
+	CostBasis       float64   `json:"cost_basis"`
+	Fee             float64   `json:"fee"`
+	PnL             float64   `json:"pnl"`
+}
+
+type PnLPeriod struct {
+	Pair     string  `json:"pair"`
+	Period   string  `json:"period"`
+	Realized float64 `json:"realized"`
+	Fees     float64 `json:"fees"`
+	Volume   float64 `json:"volume"`
+	Trades   int     `json:"trades"`
+}
+
+type UnrealizedPnL struct {
+	Pair        string  `json:"pair"`
+	Amount      float64 `json:"amount"`
+	AverageCost float64 `json:"average_cost"`
+	CostBasis   float64 `json:"cost_basis"`
+	Price       float64 `json:"price"`
+	MarketValue float64 `json:"market_value"`
+	PnL         float64 `json:"pnl"`
+}
+
+type TaxConfig struct {
+	PPhRate            *float64       `json:"pph_rate"`
+	PPNRate            *float64       `json:"ppn_rate"`
+	WithheldByExchange bool           `json:"withheld_by_exchange"`
+	FeeIncludesTax     bool           `json:"fee_includes_tax"`
+	Location           *time.Location `json:"-"`
+}
+
+type TaxTransaction struct {
+	Time       time.Time `json:"time"`
+	Category   string    `json:"category"`
+	Reference  string    `json:"reference"`
+	Pair       string    `json:"pair,omitempty"`
+	Currency   string    `json:"currency"`
+	Amount     float64   `json:"amount"`
+	Value      float64   `json:"value"`
+	Fee        float64   `json:"fee"`
+	TaxableFor string    `json:"taxable_for,omitempty"`
+	PPh        float64   `json:"pph"`
+	PPN        float64   `json:"ppn"`
+	Withheld   float64   `json:"withheld"`
+	Payable    float64   `json:"payable"`
+}
+
+type TaxSummary struct {
+	Period        string  `json:"period"`
+	Currency      string  `json:"currency"`
+	Transactions  int     `json:"transactions"`
+	PurchaseValue float64 `json:"purchase_value"`
+	SaleValue     float64 `json:"sale_value"`
+	Fees          float64 `json:"fees"`
+	PPh           float64 `json:"pph"`
+	PPN           float64 `json:"ppn"`
+	Withheld      float64 `json:"withheld"`
+	Payable       float64 `json:"payable"`
+}
+
+type TaxReport struct {
+	From         time.Time        `json:"from"`
+	To           time.Time        `json:"to"`
+	Config       TaxConfig        `json:"config"`
+	Transactions []TaxTransaction `json:"transactions"`
+	Monthly      []TaxSummary     `json:"monthly"`
+	Yearly       []TaxSummary     `json:"yearly"`
+}
+
+type LedgerEntry struct {
+	Time        time.Time `json:"time"`
+	Type        string    `json:"type"`
+	Reference   string    `json:"reference"`
+	Pair        string    `json:"pair,omitempty"`
+	Currency    string    `json:"currency"`
+	Amount      float64   `json:"amount"`
+	Balance     float64   `json:"balance"`
+	Description string    `json:"description,omitempty"`
+}
+
+type Statement struct {
+	From    time.Time          `json:"from"`
+	To      time.Time          `json:"to"`
+	Opening map[string]float64 `json:"opening"`
+	Closing map[string]float64 `json:"closing"`
+	Entries []LedgerEntry      `json:"entries"`
+}
+
+type CassetteInteraction struct {
+	Request  CassetteRequest  `json:"request"`
+	Response CassetteResponse `json:"response"`
+}
+
+type CassetteRequest struct {
+	Method string            `json:"method"`
+	Url    string            `json:"url"`
+	Header map[string]string `json:"header,omitempty"`
+	Body   string            `json:"body,omitempty"`
+}
+
+type CassetteResponse struct {
+	StatusCode int               `json:"status_code"`
+	Header     map[string]string `json:"header,omitempty"`
+	Body       string            `json:"body"`
+}
+
+type BacktestConfig struct {
+	Pairs                  []string           `json:"pairs"`
+	PairMetadata           []Pair             `json:"pair_metadata"`
+	InitialBalances        map[string]float64 `json:"initial_balances"`
+	QuoteCurrency          string             `json:"quote_currency"`
+	SlippageBps            float64            `json:"slippage_bps"`
+	MaxVolumeParticipation float64            `json:"max_volume_participation"`
+	RiskFreeRate           float64            `json:"risk_free_rate"`
+}
+
+type EquityPoint struct {
+	Time   time.Time `json:"time"`
+	Equity float64   `json:"equity"`
+}
+
+type BacktestResult struct {
+	Start         time.Time     `json:"start"`
+	End           time.Time     `json:"end"`
+	InitialEquity float64       `json:"initial_equity"`
+	FinalEquity   float64       `json:"final_equity"`
+	TotalReturn   float64       `json:"total_return"`
+	MaxDrawdown   float64       `json:"max_drawdown"`
+	Sharpe        float64       `json:"sharpe"`
+	WinRate       float64       `json:"win_rate"`
+	Trades        int           `json:"trades"`
+	Fees          float64       `json:"fees"`
+	Turnover      float64       `json:"turnover"`
+	EquityCurve   []EquityPoint `json:"equity_curve"`
+	Fills         []OwnTrade    `json:"fills"`
+}
+
+type StrategyConfig struct {
+	Pairs        []string      `json:"pairs"`
+	PollInterval time.Duration `json:"poll_interval"`
+}
+
+type StrategyTicker struct {
+	Pair   string    `json:"pair"`
+	Time   time.Time `json:"time"`
+	Last   float64   `json:"last"`
+	Buy    float64   `json:"buy"`
+	Sell   float64   `json:"sell"`
+	High   float64   `json:"high"`
+	Low    float64   `json:"low"`
+	Volume float64   `json:"volume"`
+}
+
+type OrderUpdate struct {
+	OrderId       string                 `json:"order_id"`
+	ClientOrderId string                 `json:"client_order_id,omitempty"`
+	Pair          string                 `json:"pair"`
+	Type          string                 `json:"type"`
+	Status        string                 `json:"status"`
+	Price         float64                `json:"price"`
+	Amount        float64                `json:"amount"`
+	Remain        float64                `json:"remain"`
+	Filled        float64                `json:"filled"`
+	Time          time.Time              `json:"time"`
+	Order         map[string]interface{} `json:"order"`
+}
+
+type OrderManagerConfig struct {
+	Pairs             []string      `json:"pairs"`
+	ReconcileInterval time.Duration `json:"reconcile_interval"`
+	PendingTimeout    time.Duration `json:"pending_timeout"`
+	HistoryCount      int           `json:"history_count"`
+}
+
+type ManagedOrder struct {
+	Id            string    `json:"id"`
+	OrderId       string    `json:"order_id,omitempty"`
+	ClientOrderId string    `json:"client_order_id,omitempty"`
+	Pair          string    `json:"pair"`
+	Type          string    `json:"type"`
+	OrderType     string    `json:"order_type"`
+	Price         float64   `json:"price"`
+	Amount        float64   `json:"amount"`
+	Filled        float64   `json:"filled"`
+	Remain        float64   `json:"remain"`
+	State         string    `json:"state"`
+	Error         string    `json:"error,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+type OrderStateChange struct {
+	Order ManagedOrder `json:"order"`
+	From  string       `json:"from"`
+	To    string       `json:"to"`
+}
+
+type CircuitBreakerConfig struct {
+	FailureThreshold   int                          `json:"failure_threshold"`
+	OpenTimeout        time.Duration                `json:"open_timeout"`
+	HalfOpenProbes     int                          `json:"half_open_probes"`
+	PairStatusInterval time.Duration                `json:"pair_status_interval"`
+	Group              func(req *ApiRequest) string `json:"-"`
+}
+
+type CircuitStateChange struct {
+	Group  string `json:"group"`
+	From   string `json:"from"`
+	To     string `json:"to"`
+	Reason string `json:"reason"`
+}
@@ -0,0 +1,338 @@
+package indodax
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+)
+
+const withdrawalStoreKey = "withdrawals"
+
+type WithdrawalTracker struct {
+	client  WalletAPI
+	store   Store
+	config  WithdrawalTrackerConfig
+	handler func(WithdrawalEvent)
+	mu      sync.Mutex
+	items   map[string]*TrackedWithdrawal
+}
+
+func NewWithdrawalTracker(client WalletAPI, store Store, config WithdrawalTrackerConfig) (*WithdrawalTracker, error) {
+	if client == nil {
+		return nil, errors.New("client is required")
+	}
+
+	if store == nil {
+		store = NewMemoryStore()
+	}
+
+	if config.PollInterval <= 0 {
+		config.PollInterval = DefaultWithdrawalPollInterval
+	}
+
+	if config.StuckAfter <= 0 {
+		config.StuckAfter = DefaultWithdrawalStuckAfter
+	}
+
+	if config.Retention <= 0 {
+		config.Retention = DefaultWithdrawalRetention
+	}
+
+	if config.ExpireAfter <= 0 {
+		config.ExpireAfter = config.StuckAfter + DefaultWithdrawalExpiryMargin
+	}
+
+	t := &WithdrawalTracker{
+		client: client,
+		store:  store,
+		config: config,
+		items:  map[string]*TrackedWithdrawal{},
+	}
+
+	var saved []TrackedWithdrawal
+
+	if err := store.Load(withdrawalStoreKey, &saved); err != nil && !errors.Is(err, ErrStateNotFound) {
+		return nil, err
+	}
+
+	for i := range saved {
+		w := saved[i]
+		t.items[w.WithdrawId] = &w
+	}
+
+	return t, nil
+}
+
+func (t *WithdrawalTracker) WithHandler(handler func(WithdrawalEvent)) *WithdrawalTracker {
+	t.handler = handler
+
+	return t
+}
+
+func (t *WithdrawalTracker) Track(requestId, address string, resp *WithdrawCoinResponseBody) (*TrackedWithdrawal, error) {
+	if resp == nil || len(resp.WithdrawId) == 0 {
+		return nil, errors.New("withdraw response has no withdraw id")
+	}
+
+	now := time.Now()
+
+	submittedAt := toTime(resp.SubmitTime)
+	if submittedAt.IsZero() {
+		submittedAt = now
+	}
+
+	if len(address) == 0 {
+		address = resp.WithdrawAddress
+	}
+
+	w := TrackedWithdrawal{
+		RequestId:   requestId,
+		WithdrawId:  resp.WithdrawId,
+		TxId:        resp.TxId,
+		Currency:    strings.ToLower(resp.WithdrawCurrency),
+		Address:     address,
+		Amount:      resp.WithdrawAmount,
+		Fee:         resp.Fee,
+		Status:      normalizeWithdrawalStatus(resp.Status),
+		SubmittedAt: submittedAt,
+		UpdatedAt:   now,
+	}
+
+	if len(w.Status) == 0 {
+		w.Status = WithdrawalStatusRequested
+	}
+
+	t.mu.Lock()
+	t.items[w.WithdrawId] = &w
+	err := t.save()
+	t.mu.Unlock()
+
+	if err != nil {
+		return nil, err
+	}
+
+	t.emit(WithdrawalEvent{Type: WithdrawalEventTracked, Withdrawal: w})
+
+	return &w, nil
+}
+
+func (t *WithdrawalTracker) Withdrawals() []TrackedWithdrawal {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	result := make([]TrackedWithdrawal, 0, len(t.items))
+
+	for _, w := range t.items {
+		result = append(result, *w)
+	}
+
+	return result
+}
+
+func (t *WithdrawalTracker) Stuck() []TrackedWithdrawal {
+	var result []TrackedWithdrawal
+
+	for _, w := range t.Withdrawals() {
+		if w.Stuck && !isFinalWithdrawalStatus(w.Status) {
+			result = append(result, w)
+		}
+	}
+
+	return result
+}
+
+/*
+ * Poll transaction history for the status of tracked withdrawals. History is read back to
+ * ExpireAfter at most, withdrawals that are still not final after ExpireAfter are expired
+ *
+ * @return error
+ */
+func (t *WithdrawalTracker) Poll() error {
+	now := time.Now()
+	horizon := now.Add(-t.config.ExpireAfter)
+
+	t.mu.Lock()
+
+	var from time.Time
+
+	for _, w := range t.items {
+		if isFinalWithdrawalStatus(w.Status) {
+			continue
+		}
+
+		if from.IsZero() || w.SubmittedAt.Before(from) {
+			from = w.SubmittedAt
+		}
+	}
+
+	t.mu.Unlock()
+
+	if from.IsZero() {
+		return nil
+	}
+
+	if from.Before(horizon) {
+		from = horizon
+	}
+
+	history, err := getTransactionHistoryRange(t.client, from.AddDate(0, 0, -1), now.AddDate(0, 0, 1))
+	if err != nil {
+		return err
+	}
+
+	var events []WithdrawalEvent
+
+	t.mu.Lock()
+
+	for currency, records := range history.Withdraw {
+		for _, record := range records {
+			w, exist := t.items[toString(record["withdraw_id"])]
+			if !exist {
+				continue
+			}
+
+			if len(w.Currency) == 0 {
+				w.Currency = strings.ToLower(currency)
+			}
+
+			if tx := toString(record["tx"]); len(tx) > 0 {
+				w.TxId = tx
+			}
+
+			status := normalizeWithdrawalStatus(toString(record["status"]))
+			if len(status) == 0 || status == w.Status {
+				continue
+			}
+
+			previous := w.Status
+
+			w.Status = status
+			w.UpdatedAt = now
+
+			if isFinalWithdrawalStatus(status) {
+				w.Stuck = false
+				w.CompletedAt = toTime(record["success_time"])
+
+				if w.CompletedAt.IsZero() {
+					w.CompletedAt = now
+				}
+			}
+
+			events = append(events, WithdrawalEvent{Type: WithdrawalEventStatusChanged, PreviousStatus: previous, Withdrawal: *w})
+		}
+	}
+
+	for _, w := range t.items {
+		if isFinalWithdrawalStatus(w.Status) {
+			continue
+		}
+
+		if w.SubmittedAt.Before(horizon) {
+			previous := w.Status
+
+			w.Status = WithdrawalStatusExpired
+			w.Stuck = false
+			w.UpdatedAt = now
+
+			events = append(events, WithdrawalEvent{Type: WithdrawalEventExpired, PreviousStatus: previous, Withdrawal: *w})
+
+			continue
+		}
+
+		if w.Stuck {
+			continue
+		}
+
+		if now.Sub(w.SubmittedAt) >= t.config.StuckAfter {
+			w.Stuck = true
+
+			events = append(events, WithdrawalEvent{Type: WithdrawalEventStuck, Withdrawal: *w})
+		}
+	}
+
+	t.prune(now)
+
+	err = t.save()
+
+	t.mu.Unlock()
+
+	for _, event := range events {
+		t.emit(event)
+	}
+
+	return err
+}
+
+func (t *WithdrawalTracker) Run(ctx context.Context) error {
+	ticker := time.NewTicker(t.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := t.Poll(); err != nil {
+			logApiError(t.client, "failed to poll withdrawal status", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+/*
+ * Forget withdrawals that reached a final status longer than the retention ago
+ *
+ * @param time.Time now
+ *
+ * @return void
+ */
+func (t *WithdrawalTracker) prune(now time.Time) {
+	for id, w := range t.items {
+		if isFinalWithdrawalStatus(w.Status) && now.Sub(w.UpdatedAt) >= t.config.Retention {
+			delete(t.items, id)
+		}
+	}
+}
+
+func (t *WithdrawalTracker) save() error {
+	items := make([]TrackedWithdrawal, 0, len(t.items))
+
+	for _, w := range t.items {
+		items = append(items, *w)
+	}
+
+	return t.store.Save(withdrawalStoreKey, items)
+}
+
+func (t *WithdrawalTracker) emit(event WithdrawalEvent) {
+	if t.handler != nil {
+		t.handler(event)
+	}
+}
+
+/*
+ * Normalize withdrawal status reported by indodax
+ *
+ * @param string status
+ *
+ * @return string
+ */
+func normalizeWithdrawalStatus(status string) string {
+	status = strings.ToLower(strings.TrimSpace(status))
+
+	switch status {
+	case "canceled", "cancel", "rejected", "failed":
+		return WithdrawalStatusCancelled
+	case "wait", "waiting", "processing", "process", "approved":
+		return WithdrawalStatusPending
+	}
+
+	return status
+}
+
+func isFinalWithdrawalStatus(status string) bool {
+	return status == WithdrawalStatusSuccess || status == WithdrawalStatusCancelled || status == WithdrawalStatusExpired
+}
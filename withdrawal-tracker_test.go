@@ -0,0 +1,158 @@
+package indodax
+
+import (
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestWithdrawalTrackerPollSplitsLongRange(t *testing.T) {
+	wallet := &fakeWallet{}
+	submitted := time.Now().AddDate(0, 0, -20)
+
+	wallet.add("btc", false, submitted, map[string]interface{}{
+		"withdraw_id": "1",
+		"status":      "pending",
+		"submit_time": submitted.Unix(),
+	})
+
+	tracker, err := NewWithdrawalTracker(wallet, nil, WithdrawalTrackerConfig{ExpireAfter: 30 * 24 * time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = tracker.Track("req-1", "addr", &WithdrawCoinResponseBody{
+		WithdrawId:       "1",
+		WithdrawCurrency: "btc",
+		Status:           "wait",
+		SubmitTime:       strconv.FormatInt(submitted.Unix(), 10),
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	wallet.set("btc", "1", "status", "success")
+
+	if err = tracker.Poll(); err != nil {
+		t.Fatalf("poll: %v", err)
+	}
+
+	if len(wallet.ranges) < 3 {
+		t.Fatalf("expected the range to be split in 7 day chunks, got %v", wallet.ranges)
+	}
+
+	withdrawals := tracker.Withdrawals()
+	if len(withdrawals) != 1 || withdrawals[0].Status != WithdrawalStatusSuccess {
+		t.Fatalf("expected the withdrawal to succeed, got %+v", withdrawals)
+	}
+}
+
+func TestWithdrawalTrackerPrunesFinalWithdrawals(t *testing.T) {
+	store := NewMemoryStore()
+	now := time.Now()
+
+	saved := []TrackedWithdrawal{
+		{WithdrawId: "old", Status: WithdrawalStatusSuccess, SubmittedAt: now.AddDate(0, 0, -40), UpdatedAt: now.AddDate(0, 0, -31)},
+		{WithdrawId: "recent", Status: WithdrawalStatusCancelled, SubmittedAt: now.AddDate(0, 0, -2), UpdatedAt: now.AddDate(0, 0, -1)},
+		{WithdrawId: "pending", Status: WithdrawalStatusPending, SubmittedAt: now.AddDate(0, 0, -40), UpdatedAt: now.AddDate(0, 0, -40), Stuck: true},
+	}
+
+	if err := store.Save(withdrawalStoreKey, saved); err != nil {
+		t.Fatal(err)
+	}
+
+	tracker, err := NewWithdrawalTracker(&fakeWallet{}, store, WithdrawalTrackerConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = tracker.Poll(); err != nil {
+		t.Fatalf("poll: %v", err)
+	}
+
+	kept := map[string]bool{}
+	for _, w := range tracker.Withdrawals() {
+		kept[w.WithdrawId] = true
+	}
+
+	if kept["old"] || !kept["recent"] || !kept["pending"] {
+		t.Fatalf("expected only the old final withdrawal to be pruned, kept %v", kept)
+	}
+}
+
+func TestWithdrawalTrackerExpiresMissingWithdrawals(t *testing.T) {
+	wallet := &fakeWallet{}
+	submitted := time.Now().AddDate(0, -6, 0)
+
+	tracker, err := NewWithdrawalTracker(wallet, nil, WithdrawalTrackerConfig{StuckAfter: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var events []WithdrawalEvent
+
+	tracker.WithHandler(func(event WithdrawalEvent) {
+		events = append(events, event)
+	})
+
+	if _, err = tracker.Track("req-1", "addr", &WithdrawCoinResponseBody{
+		WithdrawId:       "missing",
+		WithdrawCurrency: "btc",
+		Status:           "wait",
+		SubmitTime:       strconv.FormatInt(submitted.Unix(), 10),
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = tracker.Poll(); err != nil {
+		t.Fatalf("poll: %v", err)
+	}
+
+	if len(wallet.ranges) > 2 {
+		t.Fatalf("expected history to be read back to the expiry horizon only, got %d ranges", len(wallet.ranges))
+	}
+
+	withdrawals := tracker.Withdrawals()
+	if len(withdrawals) != 1 || withdrawals[0].Status != WithdrawalStatusExpired || withdrawals[0].Stuck {
+		t.Fatalf("expected the withdrawal to expire, got %+v", withdrawals)
+	}
+
+	if last := events[len(events)-1]; last.Type != WithdrawalEventExpired || last.PreviousStatus != WithdrawalStatusPending {
+		t.Fatalf("expected an expired event, got %+v", events)
+	}
+
+	calls := len(wallet.ranges)
+
+	if err = tracker.Poll(); err != nil {
+		t.Fatalf("poll: %v", err)
+	}
+
+	if len(wallet.ranges) != calls {
+		t.Fatalf("expected expired withdrawals not to be polled, got %d more ranges", len(wallet.ranges)-calls)
+	}
+}
+
+func TestWithdrawalTrackerKeepsRecentMissingWithdrawals(t *testing.T) {
+	submitted := time.Now().Add(-3 * time.Hour)
+
+	tracker, err := NewWithdrawalTracker(&fakeWallet{}, nil, WithdrawalTrackerConfig{StuckAfter: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = tracker.Track("req-1", "addr", &WithdrawCoinResponseBody{
+		WithdrawId:       "missing",
+		WithdrawCurrency: "btc",
+		Status:           "wait",
+		SubmitTime:       strconv.FormatInt(submitted.Unix(), 10),
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = tracker.Poll(); err != nil {
+		t.Fatalf("poll: %v", err)
+	}
+
+	if stuck := tracker.Stuck(); len(stuck) != 1 || stuck[0].Status != WithdrawalStatusPending {
+		t.Fatalf("expected the withdrawal to be stuck but pending, got %+v", stuck)
+	}
+}